@@ -866,7 +866,10 @@ func apply_server_state(state: Dictionary) -> void:
 		# Server reconciliation for local player
 		# Only correct if difference is significant
 		var pos_error = global_position.distance_to(target_position)
-		if pos_error > 0.5:
+		if state.has("aiVelocity"):
+			# Character AI is moving us while we aren't steering; follow the server
+			global_position = global_position.lerp(target_position, 0.3)
+		elif pos_error > 0.5:
 			print("[PLAYER] Server correction - Current: ", global_position, " Server: ", target_position, " Error: ", pos_error)
 			global_position = global_position.lerp(target_position, 0.3)
 		else:
@@ -20,21 +20,112 @@ type AbilitiesConfig struct {
 
 type AbilityData struct {
 	Name       string  `json:"name"`
+	Type       string  `json:"type"`
+	Shape      string  `json:"shape"`
 	Cooldown   float64 `json:"cooldown"`
 	Damage     float64 `json:"damage"`
 	DamageType string  `json:"damageType"`
+	Range      float64 `json:"range"`
+	AreaRadius float64 `json:"areaRadius"`
+	Heal       float64 `json:"heal"`
+	Buff       *struct {
+		Stat     string  `json:"stat"`
+		Duration float64 `json:"duration"`
+	} `json:"buff"`
+	Movement *struct {
+		Distance float64 `json:"distance"`
+		Duration float64 `json:"duration"`
+	} `json:"movement"`
+	Summon *struct {
+		Ability string `json:"ability"`
+	} `json:"summon"`
+}
+
+// defaultShapes maps ability categories to the shape used when none is given
+var defaultShapes = map[string]string{
+	"projectile": "projectile",
+	"instant":    "line",
+	"melee":      "cone",
+	"buff":       "self_buff",
+	"movement":   "dash",
+	"summon":     "summon",
 }
 
 // EnemiesConfig represents the enemies.json structure
 type EnemiesConfig struct {
 	Version     string              `json:"version"`
 	EnemyTypes  map[string]EnemyData `json:"enemyTypes"`
+	Behaviors   map[string]BehaviorNode `json:"behaviors"`
+	Levels      struct {
+		Health    LevelCurve `json:"health"`
+		Damage    LevelCurve `json:"damage"`
+		XP        LevelCurve `json:"xp"`
+		ItemLevel LevelCurve `json:"itemLevel"`
+	} `json:"levels"`
+	Summons struct {
+		OnSummonerDeath string `json:"onSummonerDeath"`
+	} `json:"summons"`
+}
+
+// LevelCurve is a monster level scaling curve from enemies.json
+type LevelCurve struct {
+	Curve    string  `json:"curve"`
+	PerLevel float64 `json:"perLevel"`
 }
 
 type EnemyData struct {
 	Name      string  `json:"name"`
 	Health    float64 `json:"health"`
 	MaxHealth float64 `json:"maxHealth"`
+	AI        struct {
+		Type     string `json:"type"`
+		Behavior string `json:"behavior"`
+	} `json:"ai"`
+}
+
+// BehaviorNode is a behavior tree node from enemies.json
+type BehaviorNode struct {
+	Type      string         `json:"type"`
+	Condition string         `json:"condition"`
+	Action    string         `json:"action"`
+	Range     string         `json:"range"`
+	EnemyType string         `json:"enemyType"`
+	Children  []BehaviorNode `json:"children"`
+}
+
+// Behavior tree vocabulary, mirroring the tables in the game package's
+// behavior_tree.go. A server test fails if the two drift apart.
+var behaviorConditions = map[string]bool{
+	"hasTarget":     true,
+	"healthBelow":   true,
+	"shouldFlee":    true,
+	"targetWithin":  true,
+	"targetVisible": true,
+	"attackReady":   true,
+	"summonReady":   true,
+	"charging":      true,
+	"enraged":       true,
+	"stunned":       true,
+}
+
+var behaviorActions = map[string]bool{
+	"idle":         true,
+	"wait":         true,
+	"chase":        true,
+	"strafe":       true,
+	"attack":       true,
+	"cast":         true,
+	"explode":      true,
+	"flee":         true,
+	"keepDistance": true,
+	"buffAllies":   true,
+	"summon":       true,
+}
+
+var behaviorRanges = map[string]bool{
+	"attack":  true,
+	"aggro":   true,
+	"support": true,
 }
 
 func main() {
@@ -152,20 +243,58 @@ func validateAbilities(filePath string) bool {
 		return false
 	}
 
+	valid := true
 	for abilityName, abilityData := range config.Abilities {
 		missing := []string{}
 
+		shape := abilityData.Shape
+		if shape == "" {
+			shape = defaultShapes[abilityData.Type]
+		}
+
 		if abilityData.Name == "" {
 			missing = append(missing, "name")
 		}
 		if abilityData.Cooldown == 0 {
 			missing = append(missing, "cooldown")
 		}
-		if abilityData.Damage == 0 {
-			missing = append(missing, "damage")
-		}
-		if abilityData.DamageType == "" {
-			missing = append(missing, "damageType")
+
+		switch shape {
+		case "projectile", "line", "cone", "ground_aoe":
+			if abilityData.Damage == 0 {
+				missing = append(missing, "damage")
+			}
+			if abilityData.DamageType == "" {
+				missing = append(missing, "damageType")
+			}
+			if shape == "ground_aoe" && abilityData.AreaRadius == 0 {
+				missing = append(missing, "areaRadius")
+			}
+		case "self_buff":
+			if abilityData.Heal == 0 && (abilityData.Buff == nil || abilityData.Buff.Stat == "" || abilityData.Buff.Duration == 0) {
+				missing = append(missing, "buff or heal")
+			}
+		case "dash":
+			if abilityData.Movement == nil || abilityData.Movement.Distance == 0 {
+				missing = append(missing, "movement.distance")
+			}
+		case "blink", "leap":
+			if abilityData.Range == 0 {
+				missing = append(missing, "range")
+			}
+			if shape == "leap" && (abilityData.Movement == nil || abilityData.Movement.Duration == 0) {
+				missing = append(missing, "movement.duration")
+			}
+		case "summon":
+			if abilityData.Summon == nil {
+				missing = append(missing, "summon")
+			} else if _, ok := config.Abilities[abilityData.Summon.Ability]; !ok {
+				fmt.Printf("[ERROR] Ability '%s' summons unknown ability '%s'\n", abilityName, abilityData.Summon.Ability)
+				valid = false
+			}
+		default:
+			fmt.Printf("[ERROR] Ability '%s' has unknown type/shape: %q/%q\n", abilityName, abilityData.Type, abilityData.Shape)
+			valid = false
 		}
 
 		if len(missing) > 0 {
@@ -173,6 +302,9 @@ func validateAbilities(filePath string) bool {
 		}
 	}
 
+	if !valid {
+		return false
+	}
 	fmt.Printf("[OK] abilities.json: %d abilities validated\n", len(config.Abilities))
 	return true
 }
@@ -190,9 +322,44 @@ func validateEnemies(filePath string) bool {
 		return false
 	}
 
+	// The server falls back to its built-in AI for a broken tree, logging
+	// only a line, so catch the mistakes here
+	valid := true
+	for name, tree := range config.Behaviors {
+		for _, problem := range checkBehaviorNode(tree, name, config.EnemyTypes) {
+			fmt.Printf("[ERROR] Behavior %s\n", problem)
+			valid = false
+		}
+	}
+
+	curves := map[string]LevelCurve{
+		"health":    config.Levels.Health,
+		"damage":    config.Levels.Damage,
+		"xp":        config.Levels.XP,
+		"itemLevel": config.Levels.ItemLevel,
+	}
+	for name, curve := range curves {
+		if curve.Curve != "" && curve.Curve != "linear" && curve.Curve != "exponential" {
+			fmt.Printf("[ERROR] Level curve '%s' has unknown curve '%s'\n", name, curve.Curve)
+			valid = false
+		}
+	}
+
+	if mode := config.Summons.OnSummonerDeath; mode != "" && mode != "despawn" && mode != "berserk" {
+		fmt.Printf("[ERROR] summons.onSummonerDeath must be despawn or berserk, got '%s'\n", mode)
+		valid = false
+	}
+
 	for enemyName, enemyData := range config.EnemyTypes {
 		missing := []string{}
 
+		if enemyData.AI.Behavior != "" {
+			if _, ok := config.Behaviors[enemyData.AI.Behavior]; !ok {
+				fmt.Printf("[ERROR] Enemy '%s' uses unknown behavior '%s'\n", enemyName, enemyData.AI.Behavior)
+				valid = false
+			}
+		}
+
 		if enemyData.Name == "" {
 			missing = append(missing, "name")
 		}
@@ -208,6 +375,53 @@ func validateEnemies(filePath string) bool {
 		}
 	}
 
-	fmt.Printf("[OK] enemies.json: %d enemy types validated\n", len(config.EnemyTypes))
+	if !valid {
+		return false
+	}
+	fmt.Printf("[OK] enemies.json: %d enemy types, %d behaviors validated\n", len(config.EnemyTypes), len(config.Behaviors))
 	return true
 }
+
+// checkBehaviorNode walks a behavior tree the way the server validates it and
+// returns a description of each problem: unknown node types, conditions,
+// actions or ranges, composites with the wrong number of children, and
+// summons of enemy types that don't exist
+func checkBehaviorNode(node BehaviorNode, path string, enemyTypes map[string]EnemyData) []string {
+	var problems []string
+	switch node.Type {
+	case "selector", "sequence":
+		if len(node.Children) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: %s has no children", path, node.Type))
+		}
+	case "inverter":
+		if len(node.Children) != 1 {
+			problems = append(problems, fmt.Sprintf("%s: inverter needs exactly one child, has %d", path, len(node.Children)))
+		}
+	case "condition":
+		if !behaviorConditions[node.Condition] {
+			problems = append(problems, fmt.Sprintf("%s: unknown condition %q", path, node.Condition))
+		}
+		if node.Range != "" && !behaviorRanges[node.Range] {
+			problems = append(problems, fmt.Sprintf("%s: unknown range %q", path, node.Range))
+		}
+	case "action":
+		if !behaviorActions[node.Action] {
+			problems = append(problems, fmt.Sprintf("%s: unknown action %q", path, node.Action))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("%s: unknown node type %q", path, node.Type))
+	}
+
+	if (node.Type == "condition" || node.Type == "action") && len(node.Children) > 0 {
+		problems = append(problems, fmt.Sprintf("%s: %s can't have children", path, node.Type))
+	}
+	if node.EnemyType != "" {
+		if _, ok := enemyTypes[node.EnemyType]; !ok {
+			problems = append(problems, fmt.Sprintf("%s: summons unknown enemy type %q", path, node.EnemyType))
+		}
+	}
+	for i, child := range node.Children {
+		problems = append(problems, checkBehaviorNode(child, fmt.Sprintf("%s.children[%d]", path, i), enemyTypes)...)
+	}
+	return problems
+}
@@ -15,16 +15,21 @@ import (
 )
 
 var (
-	addr       = flag.String("addr", ":7000", "HTTP service address")
-	dbType     = flag.String("db-type", "sqlite", "Database type: 'sqlite' or 'postgres'")
-	dbFile     = flag.String("db-file", "./data/players.db", "SQLite database file path")
-	dbHost     = flag.String("db-host", "localhost", "Database host (PostgreSQL only)")
-	dbPort     = flag.String("db-port", "7001", "Database port (PostgreSQL only)")
-	dbUser     = flag.String("db-user", "crawler", "Database user (PostgreSQL only)")
-	dbPassword = flag.String("db-password", "crawler", "Database password (PostgreSQL only)")
-	dbName     = flag.String("db-name", "crawler", "Database name (PostgreSQL only)")
-	tickRate   = flag.Int("tick-rate", 60, "Game loop ticks per second")
-	llmURL     = flag.String("llm-url", "", "URL of llama-server for AI combat (e.g., http://localhost:8080)")
+	addr            = flag.String("addr", ":7000", "HTTP service address")
+	dbType          = flag.String("db-type", "sqlite", "Database type: 'sqlite' or 'postgres'")
+	dbFile          = flag.String("db-file", "./data/players.db", "SQLite database file path")
+	dbHost          = flag.String("db-host", "localhost", "Database host (PostgreSQL only)")
+	dbPort          = flag.String("db-port", "7001", "Database port (PostgreSQL only)")
+	dbUser          = flag.String("db-user", "crawler", "Database user (PostgreSQL only)")
+	dbPassword      = flag.String("db-password", "crawler", "Database password (PostgreSQL only)")
+	dbName          = flag.String("db-name", "crawler", "Database name (PostgreSQL only)")
+	tickRate        = flag.Int("tick-rate", 60, "Game loop ticks per second")
+	llmURL          = flag.String("llm-url", "", "URL of the LLM server for AI combat (e.g., http://localhost:8080)")
+	llmProviderName = flag.String("llm-provider", "llama", "LLM API at -llm-url: 'llama' (llama-server /completion with GBNF) or 'openai' (/v1/chat/completions with a JSON schema)")
+	llmModel        = flag.String("llm-model", "", "Model name (openai provider only)")
+	llmTemperature  = flag.Float64("llm-temperature", 0.7, "Sampling temperature (openai provider only)")
+	llmAPIKey       = flag.String("llm-api-key", "", "API key sent as a bearer token (openai provider only)")
+	llmSlots        = flag.Int("llm-slots", 4, "Concurrent LLM requests; match the server's parallel slots (llama-server --parallel)")
 )
 
 func envOrFlag(envKey string, flagVal *string) string {
@@ -44,6 +49,37 @@ func envOrFlagInt(envKey string, flagVal *int) int {
 	return *flagVal
 }
 
+func envOrFlagFloat(envKey string, flagVal *float64) float64 {
+	if v := os.Getenv(envKey); v != "" {
+		var f float64
+		if _, err := fmt.Sscanf(v, "%g", &f); err == nil {
+			return f
+		}
+	}
+	return *flagVal
+}
+
+// newLLMProvider connects to the LLM server at url using the named API
+func newLLMProvider(name, url string) (game.LLMProvider, error) {
+	switch name {
+	case "llama", "":
+		return game.NewHTTPLLMProvider(game.HTTPLLMConfig{
+			BaseURL: url,
+			Timeout: 30,
+		})
+	case "openai":
+		return game.NewOpenAILLMProvider(game.OpenAILLMConfig{
+			BaseURL:     url,
+			Model:       envOrFlag("LLM_MODEL", llmModel),
+			Temperature: envOrFlagFloat("LLM_TEMPERATURE", llmTemperature),
+			APIKey:      envOrFlag("LLM_API_KEY", llmAPIKey),
+			Timeout:     30,
+		})
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q (want llama or openai)", name)
+	}
+}
+
 func main() {
 	flag.Parse()
 
@@ -68,6 +104,7 @@ func main() {
 	if err := config.LoadAll(configDir); err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	game.LoadAbilityRegistry()
 
 	// Build database config based on type
 	var dbConfig database.Config
@@ -104,13 +141,11 @@ func main() {
 	// Initialize LLM provider if URL is provided
 	var llmProvider game.LLMProvider
 	resolvedLLMURL := envOrFlag("LLM_URL", llmURL)
+	resolvedLLMProvider := envOrFlag("LLM_PROVIDER", llmProviderName)
 
 	if resolvedLLMURL != "" {
-		log.Printf("Initializing HTTP LLM provider at: %s", resolvedLLMURL)
-		provider, err := game.NewHTTPLLMProvider(game.HTTPLLMConfig{
-			BaseURL: resolvedLLMURL,
-			Timeout: 30,
-		})
+		log.Printf("Initializing %s LLM provider at: %s", resolvedLLMProvider, resolvedLLMURL)
+		provider, err := newLLMProvider(resolvedLLMProvider, resolvedLLMURL)
 		if err != nil {
 			log.Printf("WARNING: Failed to initialize LLM provider: %v", err)
 			log.Printf("AI combat will use fallback behavior trees")
@@ -123,8 +158,17 @@ func main() {
 		log.Printf("To enable AI combat, start llama-server and use -llm-url flag")
 	}
 
+	// One pool of inference workers serves every world
+	var llm *game.LLMManager
+	if llmProvider != nil {
+		llm = game.NewLLMManager(game.LLMManagerConfig{
+			Provider: llmProvider,
+			Workers:  envOrFlagInt("LLM_SLOTS", llmSlots),
+		})
+	}
+
 	// Initialize game server
-	gameServer := game.NewServer(resolvedTickRate, db, llmProvider)
+	gameServer := game.NewServer(resolvedTickRate, db, llm)
 
 	// Initialize network server
 	netServer := network.NewServer(resolvedAddr, gameServer, db)
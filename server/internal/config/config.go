@@ -14,6 +14,7 @@ var (
 	Enemies   EnemiesData
 	Player    PlayerData
 	Combat    CombatData
+	Items     ItemsData
 	Spawning  SpawningData
 	Server    ServerData
 )
@@ -22,6 +23,7 @@ var (
 type AbilityConfig struct {
 	Name            string                 `json:"name"`
 	Type            string                 `json:"type"`
+	Shape           string                 `json:"shape,omitempty"`
 	Cooldown        float64                `json:"cooldown"`
 	Damage          float64                `json:"damage"`
 	DamageType      string                 `json:"damageType"`
@@ -29,10 +31,38 @@ type AbilityConfig struct {
 	Speed           float64                `json:"speed"`
 	Lifetime        float64                `json:"lifetime"`
 	CollisionRadius float64                `json:"collisionRadius"`
+	AreaRadius      float64                `json:"areaRadius,omitempty"`
 	ManaCost        float64                `json:"manaCost"`
 	Description     string                 `json:"description"`
 	StatusEffect    map[string]interface{} `json:"statusEffect,omitempty"`
 	Angle           float64                `json:"angle,omitempty"`
+	Scaling         map[string]float64     `json:"scaling,omitempty"`
+	Buff            *AbilityBuffConfig     `json:"buff,omitempty"`
+	Movement        *AbilityMovementConfig `json:"movement,omitempty"`
+	Summon          *AbilitySummonConfig   `json:"summon,omitempty"`
+	Heal            float64                `json:"heal,omitempty"`  // Health restored to the caster and allies in areaRadius
+	Taunt           bool                   `json:"taunt,omitempty"` // Enemies hit switch to the caster
+}
+
+// AbilityBuffConfig describes the stat bonus granted by a self-buff ability
+type AbilityBuffConfig struct {
+	Stat     string  `json:"stat"`
+	Amount   float64 `json:"amount"`
+	Duration float64 `json:"duration"`
+}
+
+// AbilityMovementConfig describes the displacement of a movement ability
+type AbilityMovementConfig struct {
+	Distance float64 `json:"distance"`           // Dash distance
+	Duration float64 `json:"duration,omitempty"` // Seconds the movement takes (dash, leap)
+}
+
+// AbilitySummonConfig describes the minion created by a summon ability
+type AbilitySummonConfig struct {
+	MinionType   string  `json:"minionType"`
+	Ability      string  `json:"ability"`
+	Duration     float64 `json:"duration"`
+	CastInterval float64 `json:"castInterval"`
 }
 
 // AbilitiesData represents the abilities.json structure
@@ -65,6 +95,24 @@ type EnemyAI struct {
 	SupportRange    float64 `json:"supportRange,omitempty"`
 	SummonCooldown  float64 `json:"summonCooldown,omitempty"`
 	MaxSummons      int     `json:"maxSummons,omitempty"`
+	ThreatSwitch    float64 `json:"threatSwitch,omitempty"` // Overrides combat.json threat.switchRatio
+	LeashRadius     float64 `json:"leashRadius,omitempty"`  // Overrides enemies.json leash.radius
+	Behavior        string  `json:"behavior,omitempty"`     // Behavior tree from enemies.json behaviors; replaces the built-in AI for type
+}
+
+// BehaviorNodeConfig is one node of an enemy behavior tree. Selectors run
+// children until one succeeds, sequences until one fails, and inverters
+// flip their single child. Conditions test the enemy's situation and
+// actions make it do something.
+type BehaviorNodeConfig struct {
+	Type       string               `json:"type"`                 // selector, sequence, inverter, condition or action
+	Condition  string               `json:"condition,omitempty"`  // For conditions, e.g. healthBelow or targetWithin
+	Action     string               `json:"action,omitempty"`     // For actions, e.g. chase, cast or summon
+	Value      float64              `json:"value,omitempty"`      // Threshold, distance or duration, depending on the node
+	Range      string               `json:"range,omitempty"`      // targetWithin: attack, aggro or support instead of a value
+	EnemyType  string               `json:"enemyType,omitempty"`  // summon: what to summon
+	DamageType string               `json:"damageType,omitempty"` // cast: damage type of the projectile
+	Children   []BehaviorNodeConfig `json:"children,omitempty"`
 }
 
 // EnemyConfig represents a single enemy type's configuration
@@ -81,8 +129,149 @@ type EnemyConfig struct {
 
 // EnemiesData represents the enemies.json structure
 type EnemiesData struct {
-	Version     string                  `json:"version"`
-	EnemyTypes  map[string]EnemyConfig  `json:"enemyTypes"`
+	Version    string                        `json:"version"`
+	EnemyTypes map[string]EnemyConfig        `json:"enemyTypes"`
+	Elites     ElitesConfig                  `json:"elites"`
+	Packs      PacksConfig                   `json:"packs"`
+	Leash      LeashConfig                   `json:"leash"`
+	Levels     MonsterLevelConfig            `json:"levels"`
+	Summons    SummonsConfig                 `json:"summons"`
+	Behaviors  map[string]BehaviorNodeConfig `json:"behaviors"` // Named behavior trees enemies refer to with ai.behavior
+	Bosses     map[string]BossConfig         `json:"bosses"`    // Keyed by the boss's enemy type
+}
+
+// EliteRankConfig scales an elite or champion above a normal enemy of its type
+type EliteRankConfig struct {
+	HealthMultiplier float64 `json:"healthMultiplier"`
+	DamageMultiplier float64 `json:"damageMultiplier"`
+	XPMultiplier     float64 `json:"xpMultiplier"`
+	RarityBonus      float64 `json:"rarityBonus"` // Shifts loot rarity rolls upward (0-1)
+	ExtraDrops       int     `json:"extraDrops"`  // Guaranteed drops beyond the first
+}
+
+// EliteAffixConfig describes one champion affix. Fields an affix doesn't use are left zero.
+type EliteAffixConfig struct {
+	Name                  string  `json:"name"`
+	SpeedMultiplier       float64 `json:"speedMultiplier"`
+	AttackSpeedMultiplier float64 `json:"attackSpeedMultiplier"`
+	LifeSteal             float64 `json:"lifeSteal"`          // Fraction of damage dealt healed
+	ShieldFraction        float64 `json:"shieldFraction"`     // Shield strength as a fraction of max health
+	ShieldRegenSeconds    float64 `json:"shieldRegenSeconds"` // Time without damage before the shield returns
+	DamageType            string  `json:"damageType"`         // Converts attacks to this damage type
+	DeathExplosionDamage  float64 `json:"deathExplosionDamage"`
+	DeathExplosionRadius  float64 `json:"deathExplosionRadius"`
+	TeleportRange         float64 `json:"teleportRange"`
+	TeleportCooldown      float64 `json:"teleportCooldown"`
+}
+
+// ElitesConfig controls how elite packs are rolled at spawn time
+type ElitesConfig struct {
+	MinDifficulty       int                         `json:"minDifficulty"`       // Easier tiles never spawn elites
+	BaseChance          float64                     `json:"baseChance"`          // Chance per spawn group at minDifficulty
+	ChancePerDifficulty float64                     `json:"chancePerDifficulty"` // Added per difficulty above the minimum
+	MaxChance           float64                     `json:"maxChance"`
+	MinAffixes          int                         `json:"minAffixes"`
+	MaxAffixes          int                         `json:"maxAffixes"`
+	Elite               EliteRankConfig             `json:"elite"`    // Pack members
+	Champion            EliteRankConfig             `json:"champion"` // Pack leader, who carries the affixes
+	Affixes             map[string]EliteAffixConfig `json:"affixes"`
+}
+
+// PacksConfig tunes how enemies spawned together fight as a pack
+type PacksConfig struct {
+	AlertRadius            float64 `json:"alertRadius"`            // Members this close to one that finds a target join in
+	AlertThreat            float64 `json:"alertThreat"`            // Threat alerted members start with on the target
+	FlankRangeFraction     float64 `json:"flankRangeFraction"`     // Members spread around the target at this fraction of their attack range
+	LeaderBuffRadius       float64 `json:"leaderBuffRadius"`       // Members this close to a living leader are buffed
+	LeaderDamageMultiplier float64 `json:"leaderDamageMultiplier"`
+	LeaderSpeedMultiplier  float64 `json:"leaderSpeedMultiplier"`
+	LeaderDeathFleeChance  float64 `json:"leaderDeathFleeChance"`  // Chance each member flees when the leader dies; the rest scatter
+	LeaderDeathFleeSeconds float64 `json:"leaderDeathFleeSeconds"`
+}
+
+// LeashConfig controls how far enemies follow a target from where they spawned
+type LeashConfig struct {
+	Radius                float64 `json:"radius"`                // Enemies pulled further than this from home give up and return
+	ReturnSpeedMultiplier float64 `json:"returnSpeedMultiplier"` // Of chase speed, while walking home
+	RegenPerSecond        float64 `json:"regenPerSecond"`        // Fraction of max health regained per second while returning
+}
+
+// MonsterLevelConfig derives an enemy's level from where it spawned and scales
+// its stats and rewards by that level
+type MonsterLevelConfig struct {
+	LevelsPerDifficulty float64          `json:"levelsPerDifficulty"` // Levels gained per point of tile difficulty
+	LevelsPerTier       int              `json:"levelsPerTier"`       // Levels added per world difficulty tier
+	MaxTier             int              `json:"maxTier"`             // Highest difficulty tier a game can pick
+	MaxLevel            int              `json:"maxLevel"`            // 0 for no cap
+	Health              LevelCurveConfig `json:"health"`              // Health multiplier
+	Damage              LevelCurveConfig `json:"damage"`              // Damage multiplier
+	XP                  LevelCurveConfig `json:"xp"`                  // Experience reward multiplier
+	ItemLevel           LevelCurveConfig `json:"itemLevel"`           // Level of dropped items; unset drops items at the monster's level
+}
+
+// LevelCurveConfig is a value that grows with monster level, starting at 1 at level 1
+type LevelCurveConfig struct {
+	Curve    string  `json:"curve"`    // linear (1 + perLevel*(level-1)) or exponential ((1+perLevel)^(level-1))
+	PerLevel float64 `json:"perLevel"`
+}
+
+// SummonsConfig tunes enemies raised by summoners
+type SummonsConfig struct {
+	OnSummonerDeath         string  `json:"onSummonerDeath"`         // despawn or berserk
+	BerserkDamageMultiplier float64 `json:"berserkDamageMultiplier"`
+	BerserkSpeedMultiplier  float64 `json:"berserkSpeedMultiplier"`
+	BerserkSeconds          float64 `json:"berserkSeconds"`          // Berserk summons crumble after this long
+	LootChanceMultiplier    float64 `json:"lootChanceMultiplier"`    // Scales a summon's chance to drop loot
+	XPMultiplier            float64 `json:"xpMultiplier"`            // Scales a summon's experience reward
+}
+
+// BossAbilityConfig is a telegraphed ground AoE a boss can cast
+type BossAbilityConfig struct {
+	Name           string  `json:"name"`
+	Damage         float64 `json:"damage"`
+	DamageType     string  `json:"damageType"`
+	Radius         float64 `json:"radius"`
+	WarningSeconds float64 `json:"warningSeconds"` // Delay between the warning and the hit
+	Cooldown       float64 `json:"cooldown"`
+	Target         string  `json:"target"` // "self", "target" or "players" (one on each player in range)
+	Range          float64 `json:"range"`  // How far "players" reaches; defaults to the boss's aggro range
+}
+
+// BossAddWaveConfig spawns reinforcements around the boss when a phase begins
+type BossAddWaveConfig struct {
+	EnemyTypes []string `json:"enemyTypes"`
+	Count      int      `json:"count"`
+	Radius     float64  `json:"radius"`
+}
+
+// BossPhaseConfig is one stage of a boss fight. A phase begins once the
+// boss's health fraction drops to its healthThreshold.
+type BossPhaseConfig struct {
+	Name             string             `json:"name"`
+	HealthThreshold  float64            `json:"healthThreshold"`
+	Abilities        []string           `json:"abilities"`
+	AddWave          *BossAddWaveConfig `json:"addWave"`
+	DamageMultiplier float64            `json:"damageMultiplier"`
+	SpeedMultiplier  float64            `json:"speedMultiplier"`
+}
+
+// BossLootConfig is the chest left behind when a boss dies
+type BossLootConfig struct {
+	Items       int     `json:"items"`
+	RarityBonus float64 `json:"rarityBonus"`
+}
+
+// BossConfig scripts a boss encounter. Base stats come from the enemy type
+// with the same key.
+type BossConfig struct {
+	Name                   string                       `json:"name"`
+	MinDifficulty          int                          `json:"minDifficulty"` // Easier dungeons don't roll this boss
+	EnrageSeconds          float64                      `json:"enrageSeconds"`
+	EnrageDamageMultiplier float64                      `json:"enrageDamageMultiplier"`
+	EnrageSpeedMultiplier  float64                      `json:"enrageSpeedMultiplier"`
+	Abilities              map[string]BossAbilityConfig `json:"abilities"`
+	Phases                 []BossPhaseConfig            `json:"phases"`
+	Loot                   BossLootConfig               `json:"loot"`
 }
 
 // PlayerStats represents player base stats
@@ -109,6 +298,18 @@ type PlayerData struct {
 	BaseStats         PlayerStats `json:"baseStats"`
 	Visual            PlayerVisual `json:"visual"`
 	StartingAbilities []string `json:"startingAbilities"`
+	Death             DeathConfig `json:"death"`
+}
+
+// DeathConfig controls respawn timing, the death penalty and the death recap
+type DeathConfig struct {
+	RespawnSeconds              float64 `json:"respawnSeconds"`              // Base wait before a player may respawn
+	RespawnSecondsPerDifficulty float64 `json:"respawnSecondsPerDifficulty"` // Added per difficulty level of the tile died on
+	MaxRespawnSeconds           float64 `json:"maxRespawnSeconds"`
+	Penalty                     string  `json:"penalty"`         // "none" or "xp"
+	XPLossPercent               float64 `json:"xpLossPercent"`   // Percent of experience lost with the "xp" penalty
+	RecapSeconds                float64 `json:"recapSeconds"`    // How far back the death recap looks
+	RecapMaxEntries             int     `json:"recapMaxEntries"` // Most damage entries kept for the recap
 }
 
 // CombatData represents the combat.json structure
@@ -117,6 +318,42 @@ type CombatData struct {
 	CollisionRadii     map[string]float64                `json:"collisionRadii"`
 	DamageMultipliers  map[string]map[string]float64     `json:"damageMultipliers"`
 	StatusEffects      map[string]map[string]interface{} `json:"statusEffects"`
+	Threat             ThreatConfig                      `json:"threat"`
+	Stats              CombatStatsConfig                 `json:"stats"`
+}
+
+// CombatStatsConfig tunes per-player combat statistics
+type CombatStatsConfig struct {
+	EncounterTimeoutSeconds float64 `json:"encounterTimeoutSeconds"` // Seconds out of combat that end an encounter
+}
+
+// ThreatConfig tunes how enemies build and act on threat
+type ThreatConfig struct {
+	DamageMultiplier  float64 `json:"damageMultiplier"`  // Threat per point of damage dealt
+	HealingMultiplier float64 `json:"healingMultiplier"` // Threat per point of healing done
+	MinionMultiplier  float64 `json:"minionMultiplier"`  // Scales threat generated by minions
+	ProximityThreat   float64 `json:"proximityThreat"`   // Baseline threat for being in aggro range
+	TauntBonus        float64 `json:"tauntBonus"`        // Threat a taunt puts the taunter above the top entry
+	DecayPerSecond    float64 `json:"decayPerSecond"`    // Fraction of threat lost each second
+	SwitchRatio       float64 `json:"switchRatio"`       // New target must exceed current threat by this factor
+}
+
+// ConsumableConfig represents a single consumable item type
+type ConsumableConfig struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Effect      string  `json:"effect"`     // restore_health, restore_mana, town_portal or identify
+	Amount      float64 `json:"amount"`     // Health or mana restored
+	MaxStack    int     `json:"maxStack"`   // Most that fit in one bag slot
+	DropWeight  float64 `json:"dropWeight"` // Relative chance among consumable drops
+}
+
+// ItemsData represents the parts of items.json the server uses
+type ItemsData struct {
+	Version               string                      `json:"version"`
+	PotionCooldownSeconds float64                     `json:"potionCooldownSeconds"` // Shared by all potions
+	ConsumableDropChance  float64                     `json:"consumableDropChance"`  // Chance a loot drop is a consumable
+	Consumables           map[string]ConsumableConfig `json:"consumables"`
 }
 
 // SpawnPattern represents a spawn pattern configuration
@@ -147,6 +384,8 @@ type DebugConfig struct {
 	LogItemDrops      bool `json:"logItemDrops"`
 	LogPlayerSaves    bool `json:"logPlayerSaves"`
 	LogPlayerLoads    bool `json:"logPlayerLoads"`
+	SerializeThreat   bool `json:"serializeThreat"` // Include enemy threat tables in world state
+	AllowFreeHeal     bool `json:"allowFreeHeal"`   // Enable the use_heal cheat
 }
 
 // ServerData represents the server.json structure
@@ -156,6 +395,7 @@ type ServerData struct {
 	TickRate              int         `json:"tickRate"`
 	BroadcastRate         int         `json:"broadcastRate"`
 	MaxPlayers            int         `json:"maxPlayers"`
+	CombatLogPath         string      `json:"combatLogPath"` // JSONL combat log file; empty disables it
 	Debug                 DebugConfig `json:"debug"`
 }
 
@@ -184,6 +424,11 @@ func LoadAll(configDir string) error {
 	}
 	log.Printf("[CONFIG] Loaded combat config (version %s)", Combat.Version)
 
+	if err := loadJSON(filepath.Join(configDir, "shared", "items.json"), &Items); err != nil {
+		return fmt.Errorf("failed to load items: %w", err)
+	}
+	log.Printf("[CONFIG] Loaded %d consumable types (version %s)", len(Items.Consumables), Items.Version)
+
 	// Load server-specific configs
 	if err := loadJSON(filepath.Join(configDir, "server", "spawning.json"), &Spawning); err != nil {
 		return fmt.Errorf("failed to load spawning: %w", err)
@@ -220,6 +465,24 @@ func GetEnemyConfig(enemyType string) (*EnemyConfig, bool) {
 	return &config, ok
 }
 
+// GetBossConfig returns the encounter script for a boss enemy type
+func GetBossConfig(enemyType string) (*BossConfig, bool) {
+	config, ok := Enemies.Bosses[enemyType]
+	return &config, ok
+}
+
+// GetBehaviorConfig returns a named enemy behavior tree
+func GetBehaviorConfig(name string) (*BehaviorNodeConfig, bool) {
+	config, ok := Enemies.Behaviors[name]
+	return &config, ok
+}
+
+// GetConsumableConfig returns a consumable configuration by type
+func GetConsumableConfig(consumableType string) (*ConsumableConfig, bool) {
+	config, ok := Items.Consumables[consumableType]
+	return &config, ok
+}
+
 // GetSpawnPattern returns a spawn pattern by name
 func GetSpawnPattern(patternName string) (*SpawnPattern, bool) {
 	pattern, ok := Spawning.SpawnPatterns[patternName]
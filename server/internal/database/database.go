@@ -46,8 +46,11 @@ type PlayerData struct {
 	PositionZ     float64
 	Rotation      float64
 	Health        float64
+	Mana          float64
+	Experience    float64
 	EquippedItems json.RawMessage // JSONB
 	BagItems      json.RawMessage // JSONB
+	CharacterAI   json.RawMessage // JSONB: personality, trust and mood
 }
 
 // Connect establishes a connection to the database (PostgreSQL or SQLite)
@@ -125,8 +128,11 @@ func (db *DB) EnsureSchema() error {
 				position_z REAL DEFAULT 0,
 				rotation REAL DEFAULT 0,
 				health REAL DEFAULT 100,
+				mana REAL DEFAULT 100,
+				experience REAL DEFAULT 0,
 				equipped_items TEXT DEFAULT '{}',
 				bag_items TEXT DEFAULT '[]',
+				character_ai TEXT DEFAULT '{}',
 				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 				last_saved DATETIME DEFAULT CURRENT_TIMESTAMP
 			);
@@ -140,8 +146,11 @@ func (db *DB) EnsureSchema() error {
 				position_z DOUBLE PRECISION DEFAULT 0,
 				rotation DOUBLE PRECISION DEFAULT 0,
 				health DOUBLE PRECISION DEFAULT 100,
+				mana DOUBLE PRECISION DEFAULT 100,
+				experience DOUBLE PRECISION DEFAULT 0,
 				equipped_items JSONB DEFAULT '{}',
 				bag_items JSONB DEFAULT '[]',
+				character_ai JSONB DEFAULT '{}',
 				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 				last_saved TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 			);
@@ -152,23 +161,72 @@ func (db *DB) EnsureSchema() error {
 	if err != nil {
 		return fmt.Errorf("failed to create players table: %w", err)
 	}
+
+	// Add columns introduced after the initial schema to existing tables
+	if err := db.ensureColumn("mana", "DOUBLE PRECISION DEFAULT 100"); err != nil {
+		return err
+	}
+	if err := db.ensureColumn("experience", "DOUBLE PRECISION DEFAULT 0"); err != nil {
+		return err
+	}
+	jsonType := "JSONB"
+	if db.dbType == SQLite {
+		jsonType = "TEXT"
+	}
+	if err := db.ensureColumn("character_ai", jsonType+" DEFAULT '{}'"); err != nil {
+		return err
+	}
 	log.Printf("[DB] Schema ensured (players table ready)")
 	return nil
 }
 
+// ensureColumn adds a column to the players table if it is missing
+func (db *DB) ensureColumn(name, definition string) error {
+	var exists bool
+	switch db.dbType {
+	case SQLite:
+		err := db.conn.QueryRow(
+			`SELECT COUNT(*) > 0 FROM pragma_table_info('players') WHERE name = $1`, name,
+		).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to inspect players table: %w", err)
+		}
+	case PostgreSQL:
+		err := db.conn.QueryRow(
+			`SELECT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'players' AND column_name = $1)`, name,
+		).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to inspect players table: %w", err)
+		}
+	}
+
+	if exists {
+		return nil
+	}
+
+	if _, err := db.conn.Exec(fmt.Sprintf("ALTER TABLE players ADD COLUMN %s %s", name, definition)); err != nil {
+		return fmt.Errorf("failed to add column %s: %w", name, err)
+	}
+	log.Printf("[DB] Added column players.%s", name)
+	return nil
+}
+
 // SavePlayer upserts player data into the database
 func (db *DB) SavePlayer(data *PlayerData) error {
 	query := `
-		INSERT INTO players (username, position_x, position_y, position_z, rotation, health, equipped_items, bag_items, last_saved)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO players (username, position_x, position_y, position_z, rotation, health, mana, experience, equipped_items, bag_items, character_ai, last_saved)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		ON CONFLICT (username) DO UPDATE SET
 			position_x = EXCLUDED.position_x,
 			position_y = EXCLUDED.position_y,
 			position_z = EXCLUDED.position_z,
 			rotation = EXCLUDED.rotation,
 			health = EXCLUDED.health,
+			mana = EXCLUDED.mana,
+			experience = EXCLUDED.experience,
 			equipped_items = EXCLUDED.equipped_items,
 			bag_items = EXCLUDED.bag_items,
+			character_ai = EXCLUDED.character_ai,
 			last_saved = EXCLUDED.last_saved
 	`
 
@@ -177,8 +235,11 @@ func (db *DB) SavePlayer(data *PlayerData) error {
 		data.PositionX, data.PositionY, data.PositionZ,
 		data.Rotation,
 		data.Health,
+		data.Mana,
+		data.Experience,
 		data.EquippedItems,
 		data.BagItems,
+		data.CharacterAI,
 		time.Now(),
 	)
 	if err != nil {
@@ -190,19 +251,25 @@ func (db *DB) SavePlayer(data *PlayerData) error {
 // LoadPlayer loads player data from the database. Returns nil if not found.
 func (db *DB) LoadPlayer(username string) (*PlayerData, error) {
 	query := `
-		SELECT username, position_x, position_y, position_z, rotation, health, equipped_items, bag_items
+		SELECT username, position_x, position_y, position_z, rotation, health, mana, experience, equipped_items, bag_items, character_ai
 		FROM players
 		WHERE username = $1
 	`
 
+	// JSON columns are scanned as []byte; SQLite hands back column defaults as
+	// strings, which database/sql won't store in a json.RawMessage
 	data := &PlayerData{}
+	var equippedItems, bagItems, characterAI []byte
 	err := db.conn.QueryRow(query, username).Scan(
 		&data.Username,
 		&data.PositionX, &data.PositionY, &data.PositionZ,
 		&data.Rotation,
 		&data.Health,
-		&data.EquippedItems,
-		&data.BagItems,
+		&data.Mana,
+		&data.Experience,
+		&equippedItems,
+		&bagItems,
+		&characterAI,
 	)
 
 	if err == sql.ErrNoRows {
@@ -211,6 +278,9 @@ func (db *DB) LoadPlayer(username string) (*PlayerData, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to load player %s: %w", username, err)
 	}
+	data.EquippedItems = equippedItems
+	data.BagItems = bagItems
+	data.CharacterAI = characterAI
 
 	return data, nil
 }
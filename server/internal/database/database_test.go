@@ -1,9 +1,12 @@
 package database
 
 import (
+	"encoding/json"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestConnect_InvalidConfig(t *testing.T) {
@@ -22,3 +25,50 @@ func TestConnect_InvalidConfig(t *testing.T) {
 // Integration tests require database running
 // Run with: docker-compose up -d postgres
 // Then: go test ./internal/database -tags=integration
+
+func TestSQLite_PlayerRoundTrip(t *testing.T) {
+	db, err := Connect(Config{Type: SQLite, FilePath: filepath.Join(t.TempDir(), "players.db")})
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.EnsureSchema())
+
+	saved := &PlayerData{
+		Username:      "one",
+		Health:        80,
+		Mana:          40,
+		Experience:    1200,
+		EquippedItems: json.RawMessage(`{}`),
+		BagItems:      json.RawMessage(`[]`),
+		CharacterAI:   json.RawMessage(`{"trust":42,"mood":"anxious"}`),
+	}
+	require.NoError(t, db.SavePlayer(saved))
+
+	loaded, err := db.LoadPlayer("one")
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, 1200.0, loaded.Experience)
+	assert.JSONEq(t, `{"trust":42,"mood":"anxious"}`, string(loaded.CharacterAI))
+
+	missing, err := db.LoadPlayer("nobody")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestSQLite_EnsureSchemaAddsCharacterAIColumn(t *testing.T) {
+	db, err := Connect(Config{Type: SQLite, FilePath: filepath.Join(t.TempDir(), "players.db")})
+	require.NoError(t, err)
+	defer db.Close()
+
+	// A table from before character AI was saved
+	_, err = db.conn.Exec(`CREATE TABLE players (username TEXT PRIMARY KEY, position_x REAL DEFAULT 0, position_y REAL DEFAULT 0,
+		position_z REAL DEFAULT 0, rotation REAL DEFAULT 0, health REAL DEFAULT 100, equipped_items TEXT DEFAULT '{}',
+		bag_items TEXT DEFAULT '[]', created_at DATETIME DEFAULT CURRENT_TIMESTAMP, last_saved DATETIME DEFAULT CURRENT_TIMESTAMP)`)
+	require.NoError(t, err)
+	_, err = db.conn.Exec(`INSERT INTO players (username) VALUES ('veteran')`)
+	require.NoError(t, err)
+
+	require.NoError(t, db.EnsureSchema())
+	loaded, err := db.LoadPlayer("veteran")
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(loaded.CharacterAI), "existing characters have nothing saved yet")
+}
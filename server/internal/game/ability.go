@@ -3,12 +3,15 @@ package game
 import (
 	"fmt"
 	"log"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/PersonThing/cs-crawler/server/internal/config"
 )
 
-// AbilityType represents different ability types
+// AbilityType identifies an ability. Abilities are defined in abilities.json;
+// these constants name the built-in abilities referenced directly by code.
 type AbilityType string
 
 const (
@@ -18,33 +21,133 @@ const (
 	AbilityBasicAttack AbilityType = "basic_attack"
 )
 
-// AbilityCategory represents the category of ability (projectile, instant, melee)
+// AbilityCategory represents the category of ability (projectile, instant, melee, ...)
 type AbilityCategory string
 
 const (
 	AbilityCategoryProjectile AbilityCategory = "projectile"
 	AbilityCategoryInstant    AbilityCategory = "instant"
 	AbilityCategoryMelee      AbilityCategory = "melee"
+	AbilityCategoryBuff       AbilityCategory = "buff"
+	AbilityCategoryMovement   AbilityCategory = "movement"
+	AbilityCategorySummon     AbilityCategory = "summon"
+)
+
+// AbilityShape determines how an ability is resolved against the world when cast
+type AbilityShape string
+
+const (
+	AbilityShapeProjectile AbilityShape = "projectile" // Spawns a moving projectile
+	AbilityShapeLine       AbilityShape = "line"       // Instant hit along a line
+	AbilityShapeCone       AbilityShape = "cone"       // Instant hit in a cone
+	AbilityShapeGroundAoE  AbilityShape = "ground_aoe" // Instant hit in a circle at a target point
+	AbilityShapeSelfBuff   AbilityShape = "self_buff"  // Temporary stat bonus on the caster
+	AbilityShapeDash       AbilityShape = "dash"       // Moves the caster quickly along a line
+	AbilityShapeBlink      AbilityShape = "blink"      // Teleports the caster to a target point
+	AbilityShapeLeap       AbilityShape = "leap"       // Arcs the caster to a target point, hitting an area on landing
+	AbilityShapeSummon     AbilityShape = "summon"     // Creates a minion
 )
 
 // Ability defines the structure of an ability
 type Ability struct {
 	Type         AbilityType
 	Name         string
-	Category     AbilityCategory // projectile, instant, or melee
-	Cooldown     float64         // Cooldown in seconds
+	Description  string
+	Category     AbilityCategory // projectile, instant, melee, buff, movement, or summon
+	Shape        AbilityShape
+	Cooldown     float64 // Cooldown in seconds
+	ManaCost     float64
 	Damage       float64
 	DamageType   DamageType
 	Range        float64
-	Speed        float64           // For projectiles
-	Lifetime     float64           // For projectiles
-	Radius       float64           // Collision radius
-	Angle        float64           // For cone/melee attacks (in degrees)
-	StatusEffect *StatusEffectInfo // Optional status effect to apply
+	Speed        float64              // For projectiles
+	Lifetime     float64              // For projectiles
+	Radius       float64              // Collision radius (line width for line shapes)
+	AreaRadius   float64              // For ground-targeted AoE
+	Angle        float64              // For cone/melee attacks (in degrees)
+	StatusEffect *StatusEffectInfo    // Optional status effect to apply
+	Scaling      map[StatType]float64 // Bonus damage per point of caster stat
+	Buff         *AbilityBuff         // For self-buff shapes
+	MoveDistance float64              // For dash shapes
+	MoveDuration float64              // Seconds a dash or leap takes
+	Summon       *AbilitySummon       // For summon shapes
+	Heal         float64              // Health restored by self-buff shapes
+	Taunt        bool                 // Enemies hit switch to the caster
+}
+
+// AbilityBuff is a temporary stat bonus applied to the caster
+type AbilityBuff struct {
+	Stat     StatType
+	Amount   float64
+	Duration float64
+}
+
+// AbilitySummon describes the minion a summon ability creates
+type AbilitySummon struct {
+	MinionType   MinionType
+	Ability      AbilityType // Ability the minion casts
+	Duration     float64
+	CastInterval float64
+}
+
+// IsOffensive returns true if the ability deals damage to targets
+func (a *Ability) IsOffensive() bool {
+	switch a.Shape {
+	case AbilityShapeProjectile, AbilityShapeLine, AbilityShapeCone, AbilityShapeGroundAoE:
+		return true
+	}
+	return false
+}
+
+// IsMovement returns true if the ability repositions the caster
+func (a *Ability) IsMovement() bool {
+	switch a.Shape {
+	case AbilityShapeDash, AbilityShapeBlink, AbilityShapeLeap:
+		return true
+	}
+	return false
+}
+
+// ScaledDamage returns the ability's damage including scaling from the caster's stats
+func (a *Ability) ScaledDamage(caster *Player) float64 {
+	damage := a.Damage
+	if caster == nil {
+		return damage
+	}
+	for stat, coefficient := range a.Scaling {
+		damage += caster.GetStat(stat) * coefficient
+	}
+	return damage
+}
+
+// clone returns a copy of the ability that shares no maps or pointers with it
+func (a *Ability) clone() *Ability {
+	c := *a
+	if a.StatusEffect != nil {
+		effect := *a.StatusEffect
+		c.StatusEffect = &effect
+	}
+	if a.Scaling != nil {
+		c.Scaling = make(map[StatType]float64, len(a.Scaling))
+		for stat, coefficient := range a.Scaling {
+			c.Scaling[stat] = coefficient
+		}
+	}
+	if a.Buff != nil {
+		buff := *a.Buff
+		c.Buff = &buff
+	}
+	if a.Summon != nil {
+		summon := *a.Summon
+		c.Summon = &summon
+	}
+	return &c
 }
 
 // AbilityManager manages ability cooldowns for a player
 type AbilityManager struct {
+	simTime // Cooldowns run on the owner's world clock
+
 	abilities map[AbilityType]*Ability
 	lastUsed  map[AbilityType]time.Time
 }
@@ -56,11 +159,12 @@ func NewAbilityManager() *AbilityManager {
 		lastUsed:  make(map[AbilityType]time.Time),
 	}
 
-	// Initialize all abilities
-	am.RegisterAbility(GetFireballAbility())
-	am.RegisterAbility(GetFrostboltAbility())
-	am.RegisterAbility(GetLightningAbility())
-	am.RegisterAbility(GetBasicAttackAbility())
+	// Initialize all abilities from the registry. Each manager gets its own copy
+	// so per-player adjustments don't leak into the shared definitions.
+	for _, abilityType := range RegisteredAbilities() {
+		def, _ := GetAbility(abilityType)
+		am.RegisterAbility(def.clone())
+	}
 
 	return am
 }
@@ -82,20 +186,43 @@ func (am *AbilityManager) CanUseAbility(abilityType AbilityType) bool {
 		return true
 	}
 
-	return time.Since(lastUse).Seconds() >= ability.Cooldown
+	return am.now().Sub(lastUse).Seconds() >= ability.Cooldown
 }
 
-// UseAbility marks an ability as used and returns the ability data
-func (am *AbilityManager) UseAbility(abilityType AbilityType) (*Ability, error) {
+// CanAfford checks if the given amount of mana covers an ability's cost
+func (am *AbilityManager) CanAfford(abilityType AbilityType, mana float64) bool {
+	ability, exists := am.abilities[abilityType]
+	if !exists {
+		return false
+	}
+	return mana >= ability.ManaCost
+}
+
+// UseAbility marks an ability as used and returns the ability data.
+// If mana is non-nil the ability's mana cost is checked against and deducted from it.
+func (am *AbilityManager) UseAbility(abilityType AbilityType, mana *float64) (*Ability, error) {
 	if !am.CanUseAbility(abilityType) {
 		return nil, fmt.Errorf("ability on cooldown")
 	}
 
 	ability := am.abilities[abilityType]
-	am.lastUsed[abilityType] = time.Now()
+	if mana != nil {
+		if *mana < ability.ManaCost {
+			return nil, fmt.Errorf("insufficient mana")
+		}
+		*mana -= ability.ManaCost
+	}
+
+	am.lastUsed[abilityType] = am.now()
 	return ability, nil
 }
 
+// GetAbility returns the ability registered with this manager
+func (am *AbilityManager) GetAbility(abilityType AbilityType) (*Ability, bool) {
+	ability, ok := am.abilities[abilityType]
+	return ability, ok
+}
+
 // GetRemainingCooldown returns the remaining cooldown for an ability
 func (am *AbilityManager) GetRemainingCooldown(abilityType AbilityType) float64 {
 	ability, exists := am.abilities[abilityType]
@@ -108,7 +235,7 @@ func (am *AbilityManager) GetRemainingCooldown(abilityType AbilityType) float64
 		return 0
 	}
 
-	elapsed := time.Since(lastUse).Seconds()
+	elapsed := am.now().Sub(lastUse).Seconds()
 	remaining := ability.Cooldown - elapsed
 	if remaining < 0 {
 		return 0
@@ -116,190 +243,228 @@ func (am *AbilityManager) GetRemainingCooldown(abilityType AbilityType) float64
 	return remaining
 }
 
-// GetFireballAbility returns the Fireball ability definition
-func GetFireballAbility() *Ability {
-	// Try to load from config
-	cfg, ok := config.GetAbilityConfig("fireball")
-	if !ok {
-		log.Println("[ABILITY] Fireball config not found, using defaults")
-		return getDefaultFireball()
+// Ability registry, built from abilities.json at startup
+var (
+	abilityRegistryMu sync.RWMutex
+	abilityRegistry   map[AbilityType]*Ability
+	abilityOrder      []AbilityType
+)
+
+// LoadAbilityRegistry builds the ability registry from the loaded config.
+// If no abilities are configured, the built-in defaults are used.
+func LoadAbilityRegistry() {
+	registry := make(map[AbilityType]*Ability)
+	for id, cfg := range config.Abilities.Abilities {
+		cfg := cfg
+		registry[AbilityType(id)] = NewAbilityFromConfig(AbilityType(id), &cfg)
 	}
 
-	return &Ability{
-		Type:         AbilityFireball,
-		Name:         cfg.Name,
-		Category:     AbilityCategoryProjectile,
-		Cooldown:     cfg.Cooldown,
-		Damage:       cfg.Damage,
-		DamageType:   parseDamageType(cfg.DamageType),
-		Range:        cfg.Range,
-		Speed:        cfg.Speed,
-		Lifetime:     cfg.Lifetime,
-		Radius:       cfg.CollisionRadius,
-		Angle:        0,
-		StatusEffect: nil,
+	if len(registry) == 0 {
+		log.Println("[ABILITY] No ability configs found, using defaults")
+		for _, ability := range getDefaultAbilities() {
+			registry[ability.Type] = ability
+		}
+	}
+
+	// Order by range (longest first) then name, so ranged attacks are preferred
+	order := make([]AbilityType, 0, len(registry))
+	for abilityType := range registry {
+		order = append(order, abilityType)
 	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := registry[order[i]], registry[order[j]]
+		if a.Range != b.Range {
+			return a.Range > b.Range
+		}
+		return order[i] < order[j]
+	})
+
+	abilityRegistryMu.Lock()
+	abilityRegistry = registry
+	abilityOrder = order
+	abilityRegistryMu.Unlock()
+
+	log.Printf("[ABILITY] Registered %d abilities", len(registry))
 }
 
-// GetFrostboltAbility returns the Frostbolt ability definition
-func GetFrostboltAbility() *Ability {
-	// Try to load from config
-	cfg, ok := config.GetAbilityConfig("frostbolt")
-	if !ok {
-		log.Println("[ABILITY] Frostbolt config not found, using defaults")
-		return getDefaultFrostbolt()
+// ensureAbilityRegistry lazily loads the registry if it hasn't been built yet
+func ensureAbilityRegistry() {
+	abilityRegistryMu.RLock()
+	loaded := abilityRegistry != nil
+	abilityRegistryMu.RUnlock()
+	if !loaded {
+		LoadAbilityRegistry()
 	}
+}
+
+// GetAbility returns the registered definition of an ability
+func GetAbility(abilityType AbilityType) (*Ability, bool) {
+	ensureAbilityRegistry()
+	abilityRegistryMu.RLock()
+	defer abilityRegistryMu.RUnlock()
+	ability, ok := abilityRegistry[abilityType]
+	return ability, ok
+}
+
+// RegisteredAbilities returns all registered ability types, longest range first
+func RegisteredAbilities() []AbilityType {
+	ensureAbilityRegistry()
+	abilityRegistryMu.RLock()
+	defer abilityRegistryMu.RUnlock()
+	order := make([]AbilityType, len(abilityOrder))
+	copy(order, abilityOrder)
+	return order
+}
 
-	// Parse status effect if present
-	var statusEffect *StatusEffectInfo
-	if cfg.StatusEffect != nil {
-		statusEffect = ParseStatusEffectInfo(cfg.StatusEffect)
+// NewAbilityFromConfig builds an ability definition from its config entry
+func NewAbilityFromConfig(abilityType AbilityType, cfg *config.AbilityConfig) *Ability {
+	category := AbilityCategory(cfg.Type)
+	shape := AbilityShape(cfg.Shape)
+	if shape == "" {
+		shape = defaultShapeForCategory(category)
 	}
 
-	return &Ability{
-		Type:         AbilityFrostbolt,
+	ability := &Ability{
+		Type:         abilityType,
 		Name:         cfg.Name,
-		Category:     AbilityCategoryProjectile,
+		Description:  cfg.Description,
+		Category:     category,
+		Shape:        shape,
 		Cooldown:     cfg.Cooldown,
+		ManaCost:     cfg.ManaCost,
 		Damage:       cfg.Damage,
 		DamageType:   parseDamageType(cfg.DamageType),
 		Range:        cfg.Range,
 		Speed:        cfg.Speed,
 		Lifetime:     cfg.Lifetime,
 		Radius:       cfg.CollisionRadius,
-		Angle:        0,
-		StatusEffect: statusEffect,
+		AreaRadius:   cfg.AreaRadius,
+		Angle:        cfg.Angle,
+		StatusEffect: ParseStatusEffectInfo(cfg.StatusEffect),
+		Heal:         cfg.Heal,
+		Taunt:        cfg.Taunt,
 	}
-}
 
-// GetLightningAbility returns the Lightning ability definition
-func GetLightningAbility() *Ability {
-	// Try to load from config
-	cfg, ok := config.GetAbilityConfig("lightning")
-	if !ok {
-		log.Println("[ABILITY] Lightning config not found, using defaults")
-		return getDefaultLightning()
+	switch shape {
+	case AbilityShapeLine:
+		if ability.Radius <= 0 {
+			ability.Radius = 0.5 // Width of the line
+		}
+	case AbilityShapeCone:
+		if ability.Angle <= 0 {
+			ability.Angle = 90.0 // Default cone angle
+		}
 	}
 
-	return &Ability{
-		Type:         AbilityLightning,
-		Name:         cfg.Name,
-		Category:     AbilityCategoryInstant,
-		Cooldown:     cfg.Cooldown,
-		Damage:       cfg.Damage,
-		DamageType:   parseDamageType(cfg.DamageType),
-		Range:        cfg.Range,
-		Speed:        0,
-		Lifetime:     0,
-		Radius:       0.5, // Width of the line
-		Angle:        0,
-		StatusEffect: nil,
+	if len(cfg.Scaling) > 0 {
+		ability.Scaling = make(map[StatType]float64, len(cfg.Scaling))
+		for stat, coefficient := range cfg.Scaling {
+			ability.Scaling[StatType(stat)] = coefficient
+		}
 	}
-}
 
-// GetBasicAttackAbility returns the Basic Attack ability definition
-func GetBasicAttackAbility() *Ability {
-	// Try to load from config
-	cfg, ok := config.GetAbilityConfig("basic_attack")
-	if !ok {
-		log.Println("[ABILITY] Basic Attack config not found, using defaults")
-		return getDefaultBasicAttack()
+	if cfg.Buff != nil {
+		ability.Buff = &AbilityBuff{
+			Stat:     StatType(cfg.Buff.Stat),
+			Amount:   cfg.Buff.Amount,
+			Duration: cfg.Buff.Duration,
+		}
 	}
 
-	angle := 90.0 // Default cone angle
-	if cfg.Angle > 0 {
-		angle = cfg.Angle
+	if cfg.Movement != nil {
+		ability.MoveDistance = cfg.Movement.Distance
+		ability.MoveDuration = cfg.Movement.Duration
 	}
 
-	return &Ability{
-		Type:         AbilityBasicAttack,
-		Name:         cfg.Name,
-		Category:     AbilityCategoryMelee,
-		Cooldown:     cfg.Cooldown,
-		Damage:       cfg.Damage,
-		DamageType:   parseDamageType(cfg.DamageType),
-		Range:        cfg.Range,
-		Speed:        0,
-		Lifetime:     0,
-		Radius:       0,
-		Angle:        angle,
-		StatusEffect: nil,
+	if cfg.Summon != nil {
+		ability.Summon = &AbilitySummon{
+			MinionType:   MinionType(cfg.Summon.MinionType),
+			Ability:      AbilityType(cfg.Summon.Ability),
+			Duration:     cfg.Summon.Duration,
+			CastInterval: cfg.Summon.CastInterval,
+		}
 	}
-}
 
-// getDefaultFireball returns hardcoded defaults as fallback
-func getDefaultFireball() *Ability {
-	return &Ability{
-		Type:         AbilityFireball,
-		Name:         "Fireball",
-		Category:     AbilityCategoryProjectile,
-		Cooldown:     0.5,
-		Damage:       25.0,
-		DamageType:   DamageTypeFire,
-		Range:        30.0,
-		Speed:        15.0,
-		Lifetime:     5.0,
-		Radius:       0.5,
-		Angle:        0,
-		StatusEffect: nil,
-	}
+	return ability
 }
 
-// getDefaultFrostbolt returns hardcoded defaults as fallback
-func getDefaultFrostbolt() *Ability {
-	return &Ability{
-		Type:       AbilityFrostbolt,
-		Name:       "Frostbolt",
-		Category:   AbilityCategoryProjectile,
-		Cooldown:   0.8,
-		Damage:     20.0,
-		DamageType: DamageTypeCold,
-		Range:      30.0,
-		Speed:      12.0,
-		Lifetime:   5.0,
-		Radius:     0.5,
-		Angle:      0,
-		StatusEffect: &StatusEffectInfo{
-			Type:      StatusEffectSlow,
-			Duration:  2.0,
-			Magnitude: 0.5,
-		},
-	}
-}
-
-// getDefaultLightning returns hardcoded defaults as fallback
-func getDefaultLightning() *Ability {
-	return &Ability{
-		Type:         AbilityLightning,
-		Name:         "Lightning",
-		Category:     AbilityCategoryInstant,
-		Cooldown:     1.0,
-		Damage:       30.0,
-		DamageType:   DamageTypeLightning,
-		Range:        20.0,
-		Speed:        0,
-		Lifetime:     0,
-		Radius:       0.5,
-		Angle:        0,
-		StatusEffect: nil,
+// defaultShapeForCategory maps legacy categories to their cast shape
+func defaultShapeForCategory(category AbilityCategory) AbilityShape {
+	switch category {
+	case AbilityCategoryInstant:
+		return AbilityShapeLine
+	case AbilityCategoryMelee:
+		return AbilityShapeCone
+	case AbilityCategoryBuff:
+		return AbilityShapeSelfBuff
+	case AbilityCategoryMovement:
+		return AbilityShapeDash
+	case AbilityCategorySummon:
+		return AbilityShapeSummon
+	default:
+		return AbilityShapeProjectile
 	}
 }
 
-// getDefaultBasicAttack returns hardcoded defaults as fallback
-func getDefaultBasicAttack() *Ability {
-	return &Ability{
-		Type:         AbilityBasicAttack,
-		Name:         "Basic Attack",
-		Category:     AbilityCategoryMelee,
-		Cooldown:     0.3,
-		Damage:       15.0,
-		DamageType:   DamageTypePhysical,
-		Range:        2.0,
-		Speed:        0,
-		Lifetime:     0,
-		Radius:       0,
-		Angle:        90.0,
-		StatusEffect: nil,
+// getDefaultAbilities returns hardcoded defaults as fallback
+func getDefaultAbilities() []*Ability {
+	return []*Ability{
+		{
+			Type:       AbilityFireball,
+			Name:       "Fireball",
+			Category:   AbilityCategoryProjectile,
+			Shape:      AbilityShapeProjectile,
+			Cooldown:   0.5,
+			ManaCost:   10.0,
+			Damage:     25.0,
+			DamageType: DamageTypeFire,
+			Range:      30.0,
+			Speed:      15.0,
+			Lifetime:   5.0,
+			Radius:     0.5,
+		},
+		{
+			Type:       AbilityFrostbolt,
+			Name:       "Frostbolt",
+			Category:   AbilityCategoryProjectile,
+			Shape:      AbilityShapeProjectile,
+			Cooldown:   0.8,
+			ManaCost:   12.0,
+			Damage:     20.0,
+			DamageType: DamageTypeCold,
+			Range:      30.0,
+			Speed:      12.0,
+			Lifetime:   5.0,
+			Radius:     0.5,
+			StatusEffect: &StatusEffectInfo{
+				Type:      StatusEffectSlow,
+				Duration:  2.0,
+				Magnitude: 0.5,
+			},
+		},
+		{
+			Type:       AbilityLightning,
+			Name:       "Lightning",
+			Category:   AbilityCategoryInstant,
+			Shape:      AbilityShapeLine,
+			Cooldown:   1.0,
+			ManaCost:   15.0,
+			Damage:     30.0,
+			DamageType: DamageTypeLightning,
+			Range:      20.0,
+			Radius:     0.5,
+		},
+		{
+			Type:       AbilityBasicAttack,
+			Name:       "Basic Attack",
+			Category:   AbilityCategoryMelee,
+			Shape:      AbilityShapeCone,
+			Cooldown:   0.3,
+			Damage:     15.0,
+			DamageType: DamageTypePhysical,
+			Range:      2.0,
+			Angle:      90.0,
+		},
 	}
 }
 
@@ -324,7 +489,10 @@ func (a *Ability) Serialize() map[string]interface{} {
 	return map[string]interface{}{
 		"type":       string(a.Type),
 		"name":       a.Name,
+		"category":   string(a.Category),
+		"shape":      string(a.Shape),
 		"cooldown":   a.Cooldown,
+		"manaCost":   a.ManaCost,
 		"damage":     a.Damage,
 		"damageType": string(a.DamageType),
 		"range":      a.Range,
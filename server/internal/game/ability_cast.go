@@ -0,0 +1,395 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+)
+
+// CastOptions carries per-cast modifiers for an ability
+type CastOptions struct {
+	Homing           bool
+	Piercing         bool
+	ProjectileHeight float64  // World Y that projectiles spawn at
+	TargetPosition   *Vector3 // Ground-targeted abilities; defaults to max range along direction
+}
+
+// CastResult describes the outcome of an ability cast
+type CastResult struct {
+	ProjectileID   string
+	HitTargets     []string
+	TargetPosition *Vector3 // Center of a ground AoE
+	EndPosition    *Vector3 // Caster position after a movement ability
+	Duration       float64  // Seconds a movement ability takes to reach EndPosition
+	MinionID       string
+}
+
+// Serialize adds the cast result fields to an ability_cast message
+func (r *CastResult) Serialize(msg map[string]interface{}) {
+	if r.ProjectileID != "" {
+		msg["projectileID"] = r.ProjectileID
+	}
+	if r.HitTargets != nil {
+		msg["hitTargets"] = r.HitTargets
+	}
+	if r.TargetPosition != nil {
+		msg["targetPosition"] = *r.TargetPosition
+	}
+	if r.EndPosition != nil {
+		msg["endPosition"] = *r.EndPosition
+		msg["duration"] = r.Duration
+	}
+	if r.MinionID != "" {
+		msg["minionID"] = r.MinionID
+	}
+}
+
+// CastPlayerAbility resolves an ability cast by a player against the world
+func (w *World) CastPlayerAbility(player *Player, ability *Ability, direction Vector3, opts CastOptions) *CastResult {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.castAbility(player.ID, player, player.Position, ability, direction, opts)
+}
+
+// castAbility resolves an ability by its shape. The owner is the player credited
+// with damage and whose stats scale it; the caster may be the owner or a minion.
+// Caller must hold w.mu.
+func (w *World) castAbility(casterID string, owner *Player, origin Vector3, ability *Ability, direction Vector3, opts CastOptions) *CastResult {
+	result := &CastResult{}
+	if owner.IsDead() {
+		return result
+	}
+	w.recordCast(casterID, owner.ID, string(ability.Type))
+	damage := ability.ScaledDamage(owner)
+	fromMinion := casterID != owner.ID
+
+	switch ability.Shape {
+	case AbilityShapeProjectile:
+		projectileID := fmt.Sprintf("proj-%s-%d", casterID, time.Now().UnixNano())
+		spawnPosition := origin
+		spawnPosition.Y = opts.ProjectileHeight
+
+		projectile := NewProjectile(
+			projectileID,
+			owner.ID,
+			spawnPosition,
+			Vector3{
+				X: direction.X * ability.Speed,
+				Y: direction.Y * ability.Speed,
+				Z: direction.Z * ability.Speed,
+			},
+			damage,
+			ability.DamageType,
+			string(ability.Type),
+		)
+		projectile.StatusEffectInfo = ability.StatusEffect
+		projectile.CasterID = casterID
+		projectile.Layer = layerFromY(origin.Y)
+		if ability.Lifetime > 0 {
+			projectile.Lifetime = ability.Lifetime
+		}
+		if ability.Radius > 0 {
+			projectile.Radius = ability.Radius
+		}
+
+		if opts.Homing {
+			projectile.IsHoming = true
+			projectile.HomingTurnRate = 360.0 // degrees per second
+		}
+		if opts.Piercing {
+			projectile.IsPiercing = true
+			projectile.MaxPierces = 3 // Can hit up to 3 enemies
+		}
+
+		w.addProjectile(projectile)
+		result.ProjectileID = projectileID
+
+	case AbilityShapeLine:
+		result.HitTargets = make([]string, 0)
+		for _, enemy := range w.enemiesOnLine(origin, direction, ability.Range, ability.Radius) {
+			w.hitEnemy(casterID, owner.ID, ability, damage, enemy)
+			result.HitTargets = append(result.HitTargets, enemy.ID)
+		}
+		result.HitTargets = append(result.HitTargets, w.hitPlayers(owner, ability, damage, fromMinion, func(pos Vector3) bool {
+			return lineContains(origin, direction, ability.Range, ability.Radius, pos)
+		})...)
+
+	case AbilityShapeCone:
+		result.HitTargets = make([]string, 0)
+		for _, enemy := range w.enemiesInCone(origin, direction, ability.Range, ability.Angle) {
+			w.hitEnemy(casterID, owner.ID, ability, damage, enemy)
+			result.HitTargets = append(result.HitTargets, enemy.ID)
+		}
+		result.HitTargets = append(result.HitTargets, w.hitPlayers(owner, ability, damage, fromMinion, func(pos Vector3) bool {
+			return coneContains(origin, direction, ability.Range, ability.Angle, pos)
+		})...)
+
+	case AbilityShapeGroundAoE:
+		center := groundTarget(origin, direction, ability.Range, opts.TargetPosition)
+		result.TargetPosition = &center
+		result.HitTargets = make([]string, 0)
+		for _, enemy := range w.enemiesInRadius(center, layerFromY(origin.Y), ability.AreaRadius) {
+			w.hitEnemy(casterID, owner.ID, ability, damage, enemy)
+			result.HitTargets = append(result.HitTargets, enemy.ID)
+		}
+		result.HitTargets = append(result.HitTargets, w.hitPlayers(owner, ability, damage, true, func(pos Vector3) bool {
+			return Distance2D(center, pos) <= ability.AreaRadius
+		})...)
+
+	case AbilityShapeSelfBuff:
+		if ability.Buff != nil {
+			owner.ApplyBuff(ability.Buff, ability.Type)
+		}
+		if ability.Heal > 0 {
+			w.healPlayer(owner, owner, ability.Heal)
+			for _, ally := range w.players {
+				if ally.ID != owner.ID && Distance2D(origin, ally.Position) <= ability.AreaRadius {
+					w.healPlayer(owner, ally, ability.Heal)
+				}
+			}
+		}
+
+	case AbilityShapeDash, AbilityShapeBlink, AbilityShapeLeap:
+		end := w.movementDestination(origin, ability, direction, opts.TargetPosition)
+		result.EndPosition = &end
+		result.Duration = ability.MoveDuration
+
+		// Dashes can damage everything along their path
+		if ability.Shape == AbilityShapeDash && ability.Damage > 0 {
+			pathLength := Distance2D(origin, end)
+			result.HitTargets = make([]string, 0)
+			for _, enemy := range w.enemiesOnLine(origin, direction, pathLength, ability.Radius) {
+				w.hitEnemy(casterID, owner.ID, ability, damage, enemy)
+				result.HitTargets = append(result.HitTargets, enemy.ID)
+			}
+			result.HitTargets = append(result.HitTargets, w.hitPlayers(owner, ability, damage, fromMinion, func(pos Vector3) bool {
+				return lineContains(origin, direction, pathLength, ability.Radius, pos)
+			})...)
+		}
+
+		movement := &ForcedMovement{
+			Ability:   ability,
+			From:      origin,
+			To:        end,
+			StartedAt: w.Now(),
+			Duration:  ability.MoveDuration,
+		}
+		if ability.Shape == AbilityShapeBlink || movement.Duration <= 0 {
+			owner.Movement = nil
+			owner.Position = end
+			if hits := w.resolveLanding(owner, movement); hits != nil {
+				result.HitTargets = hits
+			}
+		} else {
+			owner.Movement = movement
+		}
+
+	case AbilityShapeSummon:
+		if ability.Summon == nil {
+			break
+		}
+		summoned, ok := GetAbility(ability.Summon.Ability)
+		if !ok {
+			break
+		}
+		minionAbility := *summoned
+
+		modifier := &Modifier{
+			MinionDuration: ability.Summon.Duration,
+			CastInterval:   ability.Summon.CastInterval,
+		}
+		minionID := fmt.Sprintf("%s-%s-%d", ability.Summon.MinionType, owner.ID, time.Now().UnixNano())
+
+		var minion *Minion
+		switch ability.Summon.MinionType {
+		case MinionTypeTurret:
+			modifier.Type = ModifierTurret
+			position := Vector3{
+				X: origin.X + direction.X*2.0,
+				Y: origin.Y,
+				Z: origin.Z + direction.Z*2.0,
+			}
+			minion = NewTurret(minionID, owner.ID, position, &minionAbility, minionAbility.Type, modifier)
+		default:
+			modifier.Type = ModifierPet
+			minion = NewPet(minionID, owner.ID, origin, &minionAbility, minionAbility.Type, modifier)
+		}
+
+		w.addMinion(minion)
+		result.MinionID = minionID
+	}
+
+	return result
+}
+
+// movementDestination computes where a movement ability ends, clamped against
+// tile edges and terrain. Dashes travel a fixed distance along the direction and
+// are stopped by terrain; blinks and leaps go to a target point within range.
+// Caller must hold w.mu.
+func (w *World) movementDestination(origin Vector3, ability *Ability, direction Vector3, target *Vector3) Vector3 {
+	var end Vector3
+	checkTerrain := false
+
+	switch ability.Shape {
+	case AbilityShapeDash:
+		dir := Normalize2D(direction)
+		end = Vector3{
+			X: origin.X + dir.X*ability.MoveDistance,
+			Y: origin.Y,
+			Z: origin.Z + dir.Z*ability.MoveDistance,
+		}
+		checkTerrain = true
+	default:
+		end = groundTarget(origin, direction, ability.Range, target)
+		end.Y = origin.Y
+	}
+
+	if w.Board == nil {
+		return end
+	}
+	return w.Board.ClampMovement(origin, end, layerFromY(origin.Y), checkTerrain)
+}
+
+// resolveLanding applies the landing effect of a finished movement ability and
+// returns the IDs of enemies hit, or nil if the ability has no landing effect.
+// Caller must hold w.mu.
+func (w *World) resolveLanding(player *Player, movement *ForcedMovement) []string {
+	ability := movement.Ability
+	if ability.Shape != AbilityShapeLeap || ability.AreaRadius <= 0 || ability.Damage <= 0 {
+		return nil
+	}
+
+	damage := ability.ScaledDamage(player)
+	hitTargets := make([]string, 0)
+	for _, enemy := range w.enemiesInRadius(player.Position, layerFromY(player.Position.Y), ability.AreaRadius) {
+		w.hitEnemy(player.ID, player.ID, ability, damage, enemy)
+		hitTargets = append(hitTargets, enemy.ID)
+	}
+	hitTargets = append(hitTargets, w.hitPlayers(player, ability, damage, true, func(pos Vector3) bool {
+		return Distance2D(player.Position, pos) <= ability.AreaRadius
+	})...)
+	return hitTargets
+}
+
+// hitEnemy applies an ability's damage and status effect to an enemy and
+// records the resulting events. Threat goes to the caster, which may be the
+// owner or one of their minions. Caller must hold w.mu.
+func (w *World) hitEnemy(casterID, ownerID string, ability *Ability, damage float64, enemy *Enemy) {
+	w.addDamageThreat(enemy, casterID, ownerID, damage)
+	if ability.Taunt && enemy.AI != nil && !enemy.Dead {
+		enemy.AI.Taunt(casterID, casterID != ownerID)
+	}
+
+	damageInfo := DamageInfo{
+		Amount:      damage,
+		Type:        ability.DamageType,
+		SourceID:    ownerID,
+		TargetID:    enemy.ID,
+		AbilityType: string(ability.Type),
+	}
+	healthBefore := enemy.Health
+	died := ApplyDamage(enemy, damageInfo)
+
+	if ability.StatusEffect != nil {
+		enemy.ApplyStatusEffect(NewStatusEffect(
+			ability.StatusEffect.Type,
+			ability.StatusEffect.Duration,
+			ability.StatusEffect.Magnitude,
+			ownerID,
+		))
+	}
+
+	w.enemyDamaged(enemy, damageInfo, healthBefore, died)
+}
+
+// enemyDamaged records a player's hit on an enemy and, if it was fatal, the
+// kill, loot and experience. Caller must hold w.mu.
+func (w *World) enemyDamaged(enemy *Enemy, damageInfo DamageInfo, healthBefore float64, died bool) {
+	event := DamageEvent{
+		TargetID:    enemy.ID,
+		TargetType:  "enemy",
+		Damage:      damageInfo.Amount,
+		Type:        damageInfo.Type,
+		SourceID:    damageInfo.SourceID,
+		SourceType:  "player",
+		AbilityType: damageInfo.AbilityType,
+	}
+	if died {
+		event.Overkill = math.Max(0, damageInfo.Amount-healthBefore)
+	}
+	w.emitDamage(event)
+
+	if died {
+		w.emitDeath(DeathEvent{EntityID: enemy.ID, EntityType: "enemy", KillerID: damageInfo.SourceID, KillerType: "player"})
+		w.triggerDeathAffixes(enemy)
+		w.packMemberDied(enemy)
+		if enemy.AI != nil && enemy.AI.Boss != nil {
+			w.bossDefeated(enemy) // Bosses leave a chest instead of regular loot
+		} else {
+			w.dropLoot(enemy)
+		}
+		w.awardExperience(damageInfo.SourceID, enemy)
+	}
+}
+
+// healPlayer restores a living player's health and draws threat onto the
+// healer from enemies fighting the target. Caller must hold w.mu.
+func (w *World) healPlayer(healer, target *Player, amount float64) {
+	if target.IsDead() {
+		return
+	}
+	healed := math.Min(amount, target.MaxHealth-target.Health)
+	if healed <= 0 {
+		return
+	}
+	target.Health += healed
+	w.addHealingThreat(healer.ID, target.ID, healed)
+	w.recordHealing(healer.ID, target.ID, healed)
+}
+
+// hitPlayers damages every other player the ruleset lets the owner harm whose
+// position passes the hit test, and returns their IDs. Caller must hold w.mu.
+func (w *World) hitPlayers(owner *Player, ability *Ability, damage float64, indirect bool, hit func(pos Vector3) bool) []string {
+	var hitTargets []string
+	for _, victim := range w.players {
+		if !w.canHarmPlayer(owner, victim, indirect) || !hit(victim.Position) {
+			continue
+		}
+		w.hitPlayer(owner, victim, damage, ability.DamageType, string(ability.Type))
+		hitTargets = append(hitTargets, victim.ID)
+	}
+	return hitTargets
+}
+
+// hitPlayer applies damage from one player to another and records the
+// resulting events, crediting the attacker with the kill. Caller must hold w.mu.
+func (w *World) hitPlayer(attacker, victim *Player, damage float64, damageType DamageType, abilityType string) {
+	damageInfo := DamageInfo{
+		Amount:      damage,
+		Type:        damageType,
+		SourceID:    attacker.ID,
+		TargetID:    victim.ID,
+		AbilityType: abilityType,
+	}
+	if w.damagePlayer(victim, damageInfo, "player", attacker.Username) {
+		log.Printf("[PVP] %s killed %s", attacker.Username, victim.Username)
+	}
+}
+
+// groundTarget returns the center of a ground-targeted ability, clamped to its range
+func groundTarget(origin, direction Vector3, maxRange float64, target *Vector3) Vector3 {
+	if target == nil {
+		dir := Normalize2D(direction)
+		return Vector3{X: origin.X + dir.X*maxRange, Y: origin.Y, Z: origin.Z + dir.Z*maxRange}
+	}
+
+	center := *target
+	if dist := Distance2D(origin, center); dist > maxRange && dist > 0 {
+		scale := maxRange / dist
+		center.X = origin.X + (center.X-origin.X)*scale
+		center.Z = origin.Z + (center.Z-origin.Z)*scale
+	}
+	return center
+}
@@ -0,0 +1,125 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCastPlayerAbility_Projectile(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	player := NewPlayer("player-1", "TestUser")
+	world.players[player.ID] = player
+
+	fireball, _ := GetAbility(AbilityFireball)
+	result := world.CastPlayerAbility(player, fireball, Vector3{X: 1}, CastOptions{ProjectileHeight: 0.9, Piercing: true})
+
+	require.NotEmpty(t, result.ProjectileID)
+	projectile := world.projectiles[result.ProjectileID]
+	require.NotNil(t, projectile)
+	assert.Equal(t, player.ID, projectile.OwnerID)
+	assert.Equal(t, 0.9, projectile.Position.Y)
+	assert.Equal(t, fireball.Speed, projectile.Velocity.X)
+	assert.True(t, projectile.IsPiercing)
+}
+
+func TestCastPlayerAbility_GroundAoE(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	player := NewPlayer("player-1", "TestUser")
+	world.players[player.ID] = player
+
+	inside := NewEnemy("inside", "basic", Vector3{X: 10, Z: 1})
+	outside := NewEnemy("outside", "basic", Vector3{X: 2, Z: 0})
+	world.enemies[inside.ID] = inside
+	world.enemies[outside.ID] = outside
+
+	aoe := &Ability{
+		Type:       "meteor",
+		Shape:      AbilityShapeGroundAoE,
+		Damage:     30,
+		DamageType: DamageTypeFire,
+		Range:      15,
+		AreaRadius: 3,
+	}
+	target := Vector3{X: 10, Z: 0}
+	result := world.CastPlayerAbility(player, aoe, Vector3{X: 1}, CastOptions{TargetPosition: &target})
+
+	assert.Equal(t, []string{"inside"}, result.HitTargets)
+	assert.Less(t, inside.Health, inside.MaxHealth)
+	assert.Equal(t, outside.MaxHealth, outside.Health)
+	assert.Len(t, world.damageEvents, 1)
+}
+
+func TestCastPlayerAbility_GroundAoEClampedToRange(t *testing.T) {
+	origin := Vector3{X: 0, Z: 0}
+	far := Vector3{X: 100, Z: 0}
+
+	center := groundTarget(origin, Vector3{X: 1}, 10, &far)
+	assert.InDelta(t, 10.0, center.X, 0.001)
+
+	center = groundTarget(origin, Vector3{Z: 2}, 10, nil)
+	assert.InDelta(t, 10.0, center.Z, 0.001)
+}
+
+func TestCastPlayerAbility_SelfBuff(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	player := NewPlayer("player-1", "TestUser")
+	world.players[player.ID] = player
+	baseDamage := player.Damage
+
+	buff := &Ability{
+		Type:  "battle_cry",
+		Shape: AbilityShapeSelfBuff,
+		Buff:  &AbilityBuff{Stat: StatDamage, Amount: 10, Duration: 5},
+	}
+	world.CastPlayerAbility(player, buff, Vector3{}, CastOptions{})
+
+	assert.Equal(t, baseDamage+10, player.Damage)
+	require.Len(t, player.Buffs, 1)
+
+	// Expire the buff and make sure stats revert on the next update
+	player.Buffs[0].ExpiresAt = player.Buffs[0].ExpiresAt.Add(-10 * time.Second)
+	player.Update(0.1)
+	assert.Equal(t, baseDamage, player.Damage)
+	assert.Empty(t, player.Buffs)
+}
+
+func TestCastPlayerAbility_Dash(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	player := NewPlayer("player-1", "TestUser")
+	world.players[player.ID] = player
+
+	dash := &Ability{Type: "dash", Shape: AbilityShapeDash, MoveDistance: 5}
+	result := world.CastPlayerAbility(player, dash, Vector3{Z: 2}, CastOptions{})
+
+	require.NotNil(t, result.EndPosition)
+	assert.InDelta(t, 5.0, player.Position.Z, 0.001)
+	assert.Equal(t, player.Position, *result.EndPosition)
+}
+
+func TestCastPlayerAbility_Summon(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	player := NewPlayer("player-1", "TestUser")
+	world.players[player.ID] = player
+
+	summon := &Ability{
+		Type:  "summon_turret",
+		Shape: AbilityShapeSummon,
+		Summon: &AbilitySummon{
+			MinionType:   MinionTypeTurret,
+			Ability:      AbilityFireball,
+			Duration:     10,
+			CastInterval: 1,
+		},
+	}
+	result := world.CastPlayerAbility(player, summon, Vector3{X: 1}, CastOptions{})
+
+	require.NotEmpty(t, result.MinionID)
+	minion := world.minions[result.MinionID]
+	require.NotNil(t, minion)
+	assert.Equal(t, MinionTypeTurret, minion.Type)
+	assert.Equal(t, AbilityFireball, minion.AbilityType)
+	assert.Equal(t, player.ID, minion.OwnerID)
+}
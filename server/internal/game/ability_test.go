@@ -1,8 +1,11 @@
 package game
 
 import (
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
 )
 
 func TestNewAbilityManager(t *testing.T) {
@@ -35,7 +38,7 @@ func TestCanUseAbility(t *testing.T) {
 	}
 
 	// Use the ability
-	_, err := am.UseAbility(AbilityFireball)
+	_, err := am.UseAbility(AbilityFireball, nil)
 	if err != nil {
 		t.Fatalf("Failed to use ability: %v", err)
 	}
@@ -48,12 +51,14 @@ func TestCanUseAbility(t *testing.T) {
 
 func TestCanUseAbilityAfterCooldown(t *testing.T) {
 	am := NewAbilityManager()
+	clock := NewSimClock(time.Now())
+	am.clock = clock
 
 	// Override with short cooldown for testing
 	am.abilities[AbilityFireball].Cooldown = 0.1 // 100ms
 
 	// Use the ability
-	_, err := am.UseAbility(AbilityFireball)
+	_, err := am.UseAbility(AbilityFireball, nil)
 	if err != nil {
 		t.Fatalf("Failed to use ability: %v", err)
 	}
@@ -64,7 +69,7 @@ func TestCanUseAbilityAfterCooldown(t *testing.T) {
 	}
 
 	// Wait for cooldown
-	time.Sleep(150 * time.Millisecond)
+	clock.Advance(150 * time.Millisecond)
 
 	// Should be able to use after cooldown
 	if !am.CanUseAbility(AbilityFireball) {
@@ -75,7 +80,7 @@ func TestCanUseAbilityAfterCooldown(t *testing.T) {
 func TestUseAbility(t *testing.T) {
 	am := NewAbilityManager()
 
-	ability, err := am.UseAbility(AbilityFireball)
+	ability, err := am.UseAbility(AbilityFireball, nil)
 	if err != nil {
 		t.Fatalf("Failed to use ability: %v", err)
 	}
@@ -93,13 +98,13 @@ func TestUseAbilityOnCooldown(t *testing.T) {
 	am := NewAbilityManager()
 
 	// Use ability first time
-	_, err := am.UseAbility(AbilityFireball)
+	_, err := am.UseAbility(AbilityFireball, nil)
 	if err != nil {
 		t.Fatalf("Failed to use ability first time: %v", err)
 	}
 
 	// Try to use again immediately
-	_, err = am.UseAbility(AbilityFireball)
+	_, err = am.UseAbility(AbilityFireball, nil)
 	if err == nil {
 		t.Error("Expected error when using ability on cooldown")
 	}
@@ -108,12 +113,47 @@ func TestUseAbilityOnCooldown(t *testing.T) {
 func TestUseNonexistentAbility(t *testing.T) {
 	am := NewAbilityManager()
 
-	_, err := am.UseAbility("nonexistent")
+	_, err := am.UseAbility("nonexistent", nil)
 	if err == nil {
 		t.Error("Expected error when using nonexistent ability")
 	}
 }
 
+func TestUseAbilityDeductsMana(t *testing.T) {
+	am := NewAbilityManager()
+	am.abilities[AbilityFireball].ManaCost = 10
+
+	mana := 25.0
+	_, err := am.UseAbility(AbilityFireball, &mana)
+	if err != nil {
+		t.Fatalf("Failed to use ability: %v", err)
+	}
+
+	if mana != 15.0 {
+		t.Errorf("Expected 15 mana remaining, got %f", mana)
+	}
+}
+
+func TestUseAbilityInsufficientMana(t *testing.T) {
+	am := NewAbilityManager()
+	am.abilities[AbilityFireball].ManaCost = 10
+
+	mana := 5.0
+	_, err := am.UseAbility(AbilityFireball, &mana)
+	if err == nil || err.Error() != "insufficient mana" {
+		t.Fatalf("Expected insufficient mana error, got %v", err)
+	}
+
+	if mana != 5.0 {
+		t.Errorf("Mana should not be deducted on failure, got %f", mana)
+	}
+
+	// A failed cast must not start the cooldown
+	if !am.CanUseAbility(AbilityFireball) {
+		t.Error("Ability should not be on cooldown after failed cast")
+	}
+}
+
 func TestGetRemainingCooldown(t *testing.T) {
 	am := NewAbilityManager()
 
@@ -124,7 +164,7 @@ func TestGetRemainingCooldown(t *testing.T) {
 	}
 
 	// Use ability
-	am.UseAbility(AbilityFireball)
+	am.UseAbility(AbilityFireball, nil)
 
 	// Should have cooldown now
 	remaining = am.GetRemainingCooldown(AbilityFireball)
@@ -139,10 +179,10 @@ func TestGetRemainingCooldown(t *testing.T) {
 }
 
 func TestGetFireballAbility(t *testing.T) {
-	fireball := GetFireballAbility()
+	fireball, ok := GetAbility(AbilityFireball)
 
-	if fireball == nil {
-		t.Fatal("GetFireballAbility returned nil")
+	if !ok || fireball == nil {
+		t.Fatal("GetAbility returned no fireball")
 	}
 
 	if fireball.Type != AbilityFireball {
@@ -190,7 +230,7 @@ func TestRegisterAbility(t *testing.T) {
 }
 
 func TestAbilitySerialize(t *testing.T) {
-	fireball := GetFireballAbility()
+	fireball, _ := GetAbility(AbilityFireball)
 	serialized := fireball.Serialize()
 
 	if serialized["type"] != string(AbilityFireball) {
@@ -205,3 +245,99 @@ func TestAbilitySerialize(t *testing.T) {
 		t.Error("Cooldown not serialized correctly")
 	}
 }
+
+func TestNewAbilityFromConfigDefaultsShape(t *testing.T) {
+	cfg := &config.AbilityConfig{
+		Name:       "Shock",
+		Type:       "instant",
+		Cooldown:   1.0,
+		Damage:     10.0,
+		DamageType: "lightning",
+		Range:      15.0,
+		Scaling:    map[string]float64{"lightning_damage": 2.0},
+	}
+
+	ability := NewAbilityFromConfig("shock", cfg)
+
+	if ability.Shape != AbilityShapeLine {
+		t.Errorf("Expected instant ability to default to line shape, got %s", ability.Shape)
+	}
+	if ability.Radius != 0.5 {
+		t.Errorf("Expected default line width 0.5, got %f", ability.Radius)
+	}
+	if ability.Scaling[StatLightningDamage] != 2.0 {
+		t.Error("Scaling not parsed from config")
+	}
+}
+
+func TestNewAbilityFromConfigShapes(t *testing.T) {
+	buff := NewAbilityFromConfig("rage", &config.AbilityConfig{
+		Name:     "Rage",
+		Type:     "buff",
+		Cooldown: 10.0,
+		Buff:     &config.AbilityBuffConfig{Stat: "damage", Amount: 5, Duration: 3},
+	})
+	if buff.Shape != AbilityShapeSelfBuff || buff.Buff == nil || buff.Buff.Stat != StatDamage {
+		t.Errorf("Self-buff not parsed correctly: %+v", buff)
+	}
+	if buff.IsOffensive() {
+		t.Error("Self-buff should not be offensive")
+	}
+
+	summon := NewAbilityFromConfig("imp", &config.AbilityConfig{
+		Name:   "Summon Imp",
+		Type:   "summon",
+		Summon: &config.AbilitySummonConfig{MinionType: "pet", Ability: "fireball", Duration: 10, CastInterval: 2},
+	})
+	if summon.Shape != AbilityShapeSummon || summon.Summon == nil || summon.Summon.Ability != AbilityFireball {
+		t.Errorf("Summon not parsed correctly: %+v", summon)
+	}
+}
+
+func TestScaledDamage(t *testing.T) {
+	player := NewPlayer("player-1", "TestUser")
+	player.FireDamage = 10
+
+	ability := &Ability{Damage: 20, Scaling: map[StatType]float64{StatFireDamage: 1.5}}
+
+	if got := ability.ScaledDamage(player); got != 35 {
+		t.Errorf("Expected scaled damage 35, got %f", got)
+	}
+	if got := ability.ScaledDamage(nil); got != 20 {
+		t.Errorf("Expected unscaled damage 20 without caster, got %f", got)
+	}
+}
+
+func TestAbilityManagerCopiesRegistry(t *testing.T) {
+	am := NewAbilityManager()
+	am.abilities[AbilityFireball].Cooldown = 99
+
+	registered, _ := GetAbility(AbilityFireball)
+	if registered.Cooldown == 99 {
+		t.Error("Modifying a manager's ability should not change the registry")
+	}
+
+	frostbolt, _ := GetAbility(AbilityFrostbolt)
+	am.abilities[AbilityFrostbolt].StatusEffect.Duration = 99
+	if frostbolt.StatusEffect.Duration == 99 {
+		t.Error("Modifying a manager's status effect should not change the registry")
+	}
+}
+
+func TestAbilityCloneCopiesScaling(t *testing.T) {
+	ability := &Ability{Scaling: map[StatType]float64{StatFireDamage: 1.0}}
+	clone := ability.clone()
+	clone.Scaling[StatFireDamage] = 99
+
+	if ability.Scaling[StatFireDamage] != 1.0 {
+		t.Error("Modifying a clone's scaling should not change the original")
+	}
+}
+
+func TestBuildActionGBNF(t *testing.T) {
+	grammar := BuildActionGBNF([]AbilityType{"fireball", "meteor"}, nil)
+
+	if !strings.Contains(grammar, `ability-val   ::= "\"fireball\"" | "\"meteor\""`) {
+		t.Errorf("Grammar missing registered abilities:\n%s", grammar)
+	}
+}
@@ -0,0 +1,326 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+)
+
+// Behavior tree node types
+const (
+	behaviorSelector  = "selector"  // Runs children in order until one succeeds
+	behaviorSequence  = "sequence"  // Runs children in order until one fails
+	behaviorInverter  = "inverter"  // Flips its one child's result
+	behaviorCondition = "condition" // Tests the enemy's situation
+	behaviorAction    = "action"    // Makes the enemy do something
+)
+
+// behaviorConditionFunc tests the enemy's situation for a condition node
+type behaviorConditionFunc func(ai *EnemyAI, node *config.BehaviorNodeConfig, enemy *Enemy, ctx *EnemyAIContext) bool
+
+// behaviorActionFunc carries out an action node. It returns false if the
+// action couldn't be done (e.g. it's on cooldown), plus anything the world
+// needs to resolve.
+type behaviorActionFunc func(ai *EnemyAI, node *config.BehaviorNodeConfig, enemy *Enemy, ctx *EnemyAIContext) (bool, *EnemyAttackResult)
+
+// behaviorConditions are the conditions trees in enemies.json can use
+var behaviorConditions = map[string]behaviorConditionFunc{
+	"hasTarget": func(ai *EnemyAI, _ *config.BehaviorNodeConfig, _ *Enemy, ctx *EnemyAIContext) bool {
+		_, exists := ai.targetPosition(ctx)
+		return ai.TargetID != "" && exists
+	},
+	// healthBelow is true under value (0-1) of max health
+	"healthBelow": func(_ *EnemyAI, node *config.BehaviorNodeConfig, enemy *Enemy, _ *EnemyAIContext) bool {
+		return enemy.Health/enemy.MaxHealth < node.Value
+	},
+	// shouldFlee is true below the enemy's fleeHealth or when its pack broke
+	"shouldFlee": func(ai *EnemyAI, _ *config.BehaviorNodeConfig, enemy *Enemy, _ *EnemyAIContext) bool {
+		return ai.shouldFlee(enemy)
+	},
+	// targetWithin is true if the target is within value, or the named range
+	"targetWithin": func(ai *EnemyAI, node *config.BehaviorNodeConfig, enemy *Enemy, ctx *EnemyAIContext) bool {
+		targetPos, exists := ai.targetPosition(ctx)
+		return exists && Distance2D(enemy.Position, targetPos) <= ai.behaviorRange(node)
+	},
+	"targetVisible": func(ai *EnemyAI, _ *config.BehaviorNodeConfig, enemy *Enemy, ctx *EnemyAIContext) bool {
+		targetPos, exists := ai.targetPosition(ctx)
+		return exists && ctx.hasLineOfSight(enemy.Position, targetPos)
+	},
+	"attackReady": func(ai *EnemyAI, _ *config.BehaviorNodeConfig, _ *Enemy, _ *EnemyAIContext) bool {
+		return ai.attackReady()
+	},
+	"summonReady": func(ai *EnemyAI, _ *config.BehaviorNodeConfig, _ *Enemy, _ *EnemyAIContext) bool {
+		return ai.summonReady()
+	},
+	"charging": func(ai *EnemyAI, _ *config.BehaviorNodeConfig, _ *Enemy, _ *EnemyAIContext) bool {
+		return ai.IsCharging
+	},
+	"enraged": func(ai *EnemyAI, _ *config.BehaviorNodeConfig, _ *Enemy, _ *EnemyAIContext) bool {
+		return ai.RageMode
+	},
+	"stunned": func(_ *EnemyAI, _ *config.BehaviorNodeConfig, enemy *Enemy, _ *EnemyAIContext) bool {
+		return enemy.HasStatusEffect(StatusEffectStun)
+	},
+}
+
+// behaviorActions are the actions trees in enemies.json can use
+var behaviorActions = map[string]behaviorActionFunc{
+	// idle stops and stands around
+	"idle": func(ai *EnemyAI, _ *config.BehaviorNodeConfig, enemy *Enemy, _ *EnemyAIContext) (bool, *EnemyAttackResult) {
+		ai.State = AIStateIdle
+		enemy.Velocity = Vector3{X: 0, Y: 0, Z: 0}
+		return true, nil
+	},
+	// wait stops moving for this tick without changing state
+	"wait": func(_ *EnemyAI, _ *config.BehaviorNodeConfig, enemy *Enemy, _ *EnemyAIContext) (bool, *EnemyAttackResult) {
+		enemy.Velocity = Vector3{X: 0, Y: 0, Z: 0}
+		return true, nil
+	},
+	"chase": func(ai *EnemyAI, _ *config.BehaviorNodeConfig, enemy *Enemy, ctx *EnemyAIContext) (bool, *EnemyAttackResult) {
+		if _, exists := ai.targetPosition(ctx); !exists {
+			return false, nil
+		}
+		ai.State = AIStateChase
+		return true, ai.executeChase(enemy, ctx)
+	},
+	// strafe circles the target, switching direction every value seconds
+	"strafe": func(ai *EnemyAI, node *config.BehaviorNodeConfig, enemy *Enemy, ctx *EnemyAIContext) (bool, *EnemyAttackResult) {
+		if !ai.strafe(enemy, ctx, node.Value) {
+			return false, nil
+		}
+		ai.State = AIStateChase
+		return true, nil
+	},
+	"attack": func(ai *EnemyAI, _ *config.BehaviorNodeConfig, enemy *Enemy, ctx *EnemyAIContext) (bool, *EnemyAttackResult) {
+		if !ai.canAttack(enemy, ctx) {
+			return false, nil
+		}
+		ai.State = AIStateAttack
+		return true, ai.meleeAttack(enemy, ctx)
+	},
+	// cast fires a projectile of damageType (fire if unset) at the target
+	"cast": func(ai *EnemyAI, node *config.BehaviorNodeConfig, enemy *Enemy, ctx *EnemyAIContext) (bool, *EnemyAttackResult) {
+		if !ai.canAttack(enemy, ctx) {
+			return false, nil
+		}
+		damageType := DamageTypeFire
+		if node.DamageType != "" {
+			damageType = DamageType(node.DamageType)
+		}
+		ai.State = AIStateAttack
+		return true, ai.rangedAttack(enemy, ctx, damageType)
+	},
+	"explode": func(ai *EnemyAI, _ *config.BehaviorNodeConfig, enemy *Enemy, _ *EnemyAIContext) (bool, *EnemyAttackResult) {
+		ai.State = AIStateAttack
+		return true, ai.explode(enemy)
+	},
+	"flee": func(ai *EnemyAI, _ *config.BehaviorNodeConfig, enemy *Enemy, ctx *EnemyAIContext) (bool, *EnemyAttackResult) {
+		if _, exists := ai.targetPosition(ctx); !exists {
+			return false, nil
+		}
+		ai.State = AIStateFlee
+		return true, ai.executeFlee(enemy, ctx)
+	},
+	// keepDistance backs away from a target inside 70% of the attack range
+	"keepDistance": func(ai *EnemyAI, _ *config.BehaviorNodeConfig, enemy *Enemy, ctx *EnemyAIContext) (bool, *EnemyAttackResult) {
+		ai.State = AIStateSupport
+		ai.keepDistance(enemy, ctx)
+		return true, nil
+	},
+	"buffAllies": func(ai *EnemyAI, _ *config.BehaviorNodeConfig, enemy *Enemy, _ *EnemyAIContext) (bool, *EnemyAttackResult) {
+		if !ai.attackReady() || enemy.HasStatusEffect(StatusEffectStun) {
+			return false, nil
+		}
+		ai.State = AIStateSupport
+		return true, ai.buffAllies(enemy)
+	},
+	// summon raises an enemyType (skeleton if unset) next to the enemy
+	"summon": func(ai *EnemyAI, node *config.BehaviorNodeConfig, enemy *Enemy, _ *EnemyAIContext) (bool, *EnemyAttackResult) {
+		if !ai.summonReady() || enemy.HasStatusEffect(StatusEffectStun) {
+			return false, nil
+		}
+		enemyType := node.EnemyType
+		if enemyType == "" {
+			enemyType = defaultSummonType
+		}
+		ai.State = AIStateSupport
+		return true, ai.summon(enemy, enemyType)
+	},
+}
+
+// behaviorRanges are the named ranges targetWithin accepts instead of a value
+var behaviorRanges = map[string]func(ai *EnemyAI) float64{
+	"attack":  func(ai *EnemyAI) float64 { return ai.AttackRange },
+	"aggro":   func(ai *EnemyAI) float64 { return ai.AggroRange },
+	"support": func(ai *EnemyAI) float64 { return ai.SupportRange },
+}
+
+// behaviorRange returns the distance a targetWithin node checks against.
+// An explicit value wins, then the named range, then the attack range.
+func (ai *EnemyAI) behaviorRange(node *config.BehaviorNodeConfig) float64 {
+	if node.Value > 0 {
+		return node.Value
+	}
+	if rangeFunc, ok := behaviorRanges[node.Range]; ok {
+		return rangeFunc(ai)
+	}
+	return ai.AttackRange
+}
+
+// validateBehaviorTree checks that every node in a tree is well formed and
+// only uses known conditions and actions
+func validateBehaviorTree(node *config.BehaviorNodeConfig) error {
+	return validateBehaviorNode(node, "root")
+}
+
+func validateBehaviorNode(node *config.BehaviorNodeConfig, path string) error {
+	switch node.Type {
+	case behaviorSelector, behaviorSequence:
+		if len(node.Children) == 0 {
+			return fmt.Errorf("%s: %s has no children", path, node.Type)
+		}
+	case behaviorInverter:
+		if len(node.Children) != 1 {
+			return fmt.Errorf("%s: inverter needs exactly one child, has %d", path, len(node.Children))
+		}
+	case behaviorCondition:
+		if _, ok := behaviorConditions[node.Condition]; !ok {
+			return fmt.Errorf("%s: unknown condition %q", path, node.Condition)
+		}
+		if node.Range != "" {
+			if _, ok := behaviorRanges[node.Range]; !ok {
+				return fmt.Errorf("%s: unknown range %q", path, node.Range)
+			}
+		}
+	case behaviorAction:
+		if _, ok := behaviorActions[node.Action]; !ok {
+			return fmt.Errorf("%s: unknown action %q", path, node.Action)
+		}
+	default:
+		return fmt.Errorf("%s: unknown node type %q", path, node.Type)
+	}
+
+	if (node.Type == behaviorCondition || node.Type == behaviorAction) && len(node.Children) > 0 {
+		return fmt.Errorf("%s: %s can't have children", path, node.Type)
+	}
+	for i := range node.Children {
+		if err := validateBehaviorNode(&node.Children[i], fmt.Sprintf("%s.children[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadBehaviorTree returns the named tree from enemies.json, or nil if it's
+// missing or invalid, in which case the enemy keeps its built-in behavior
+func loadBehaviorTree(name string) *config.BehaviorNodeConfig {
+	tree, ok := config.GetBehaviorConfig(name)
+	if !ok {
+		log.Printf("[ENEMY] Unknown behavior tree '%s', using the built-in AI", name)
+		return nil
+	}
+	if err := validateBehaviorTree(tree); err != nil {
+		log.Printf("[ENEMY] Behavior tree '%s' is invalid, using the built-in AI: %v", name, err)
+		return nil
+	}
+	return tree
+}
+
+// runBehaviorTree ticks the enemy's tree once. If several actions produce
+// results in one tick, the first wins.
+func (ai *EnemyAI) runBehaviorTree(enemy *Enemy, ctx *EnemyAIContext) *EnemyAttackResult {
+	var result *EnemyAttackResult
+	ai.tickBehavior(ai.Tree, enemy, ctx, &result)
+	return result
+}
+
+// tickBehavior runs one node and returns whether it succeeded
+func (ai *EnemyAI) tickBehavior(node *config.BehaviorNodeConfig, enemy *Enemy, ctx *EnemyAIContext, result **EnemyAttackResult) bool {
+	switch node.Type {
+	case behaviorSelector:
+		for i := range node.Children {
+			if ai.tickBehavior(&node.Children[i], enemy, ctx, result) {
+				return true
+			}
+		}
+		return false
+
+	case behaviorSequence:
+		for i := range node.Children {
+			if !ai.tickBehavior(&node.Children[i], enemy, ctx, result) {
+				return false
+			}
+		}
+		return true
+
+	case behaviorInverter:
+		return !ai.tickBehavior(&node.Children[0], enemy, ctx, result)
+
+	case behaviorCondition:
+		return behaviorConditions[node.Condition](ai, node, enemy, ctx)
+
+	case behaviorAction:
+		ok, actionResult := behaviorActions[node.Action](ai, node, enemy, ctx)
+		if actionResult != nil && *result == nil {
+			*result = actionResult
+		}
+		return ok
+	}
+	return false
+}
+
+// canAttack returns true if the enemy has a target and is free to hit it
+func (ai *EnemyAI) canAttack(enemy *Enemy, ctx *EnemyAIContext) bool {
+	if !ai.attackReady() || enemy.HasStatusEffect(StatusEffectStun) {
+		return false
+	}
+	_, exists := ai.targetPosition(ctx)
+	return exists
+}
+
+// strafe circles the target at move speed, switching direction every
+// switchSeconds (2 if unset). Returns false without a target.
+func (ai *EnemyAI) strafe(enemy *Enemy, ctx *EnemyAIContext, switchSeconds float64) bool {
+	targetPos, exists := ai.targetPosition(ctx)
+	if !exists {
+		return false
+	}
+	if enemy.HasStatusEffect(StatusEffectStun) {
+		enemy.Velocity = Vector3{X: 0, Y: 0, Z: 0}
+		return true
+	}
+
+	now := ai.now()
+	if ai.strafeSign == 0 || now.After(ai.strafeSwitchAt) {
+		if switchSeconds <= 0 {
+			switchSeconds = 2.0
+		}
+		if ai.strafeSign == 0 {
+			ai.strafeSign = 1
+		} else {
+			ai.strafeSign = -ai.strafeSign
+		}
+		ai.strafeSwitchAt = now.Add(time.Duration(switchSeconds * float64(time.Second)))
+	}
+
+	dx := enemy.Position.X - targetPos.X
+	dz := enemy.Position.Z - targetPos.Z
+	distance := math.Sqrt(dx*dx + dz*dz)
+	if distance < 0.1 {
+		return false
+	}
+
+	speed := ai.MoveSpeed * ai.packSpeedMultiplier()
+	if slow := enemy.GetStatusEffect(StatusEffectSlow); slow != nil {
+		speed *= 1.0 - slow.Magnitude
+	}
+
+	// Perpendicular to the line to the target
+	enemy.Velocity = Vector3{X: -dz / distance * ai.strafeSign * speed, Z: dx / distance * ai.strafeSign * speed}
+	enemy.Position.X += enemy.Velocity.X * ctx.DeltaSeconds
+	enemy.Position.Z += enemy.Velocity.Z * ctx.DeltaSeconds
+	return true
+}
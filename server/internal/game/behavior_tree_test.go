@@ -0,0 +1,228 @@
+package game
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func btCondition(condition string) config.BehaviorNodeConfig {
+	return config.BehaviorNodeConfig{Type: "condition", Condition: condition}
+}
+
+func btAction(action string) config.BehaviorNodeConfig {
+	return config.BehaviorNodeConfig{Type: "action", Action: action}
+}
+
+func btNode(nodeType string, children ...config.BehaviorNodeConfig) config.BehaviorNodeConfig {
+	return config.BehaviorNodeConfig{Type: nodeType, Children: children}
+}
+
+// testMeleeTree attacks in range, chases while on cooldown or further away,
+// and idles without a target
+func testMeleeTree() config.BehaviorNodeConfig {
+	aggro := btCondition("targetWithin")
+	aggro.Range = "aggro"
+	return btNode("selector",
+		btNode("sequence", btNode("inverter", btCondition("hasTarget")), btAction("idle")),
+		btNode("sequence", btCondition("targetWithin"), btNode("selector", btAction("attack"), btAction("chase"))),
+		btNode("sequence", aggro, btAction("chase")),
+		btAction("idle"),
+	)
+}
+
+// withBehaviorTree registers a behavior tree for the duration of a test
+func withBehaviorTree(t *testing.T, name string, tree config.BehaviorNodeConfig) {
+	t.Helper()
+	previous := config.Enemies.Behaviors
+	config.Enemies.Behaviors = map[string]config.BehaviorNodeConfig{name: tree}
+	t.Cleanup(func() { config.Enemies.Behaviors = previous })
+}
+
+// newTreeEnemy returns an enemy driven by the named tree that already hates p1
+func newTreeEnemy(t *testing.T, behavior string, aiType string, pos Vector3) *Enemy {
+	t.Helper()
+	cfg := createTestEnemyConfig(aiType, 15)
+	cfg.AI.Behavior = behavior
+	enemy := createTestEnemy("e1", "zombie", pos, cfg)
+	require.NotNil(t, enemy.AI.Tree)
+	enemy.AI.Threat.Raise("p1", 100, false)
+	return enemy
+}
+
+func newTreeContext() *EnemyAIContext {
+	player := NewPlayer("p1", "One")
+	return &EnemyAIContext{
+		Players:      map[string]*Player{player.ID: player},
+		Enemies:      map[string]*Enemy{},
+		Minions:      map[string]*Minion{},
+		DeltaSeconds: 0.1,
+	}
+}
+
+func TestValidateBehaviorTree(t *testing.T) {
+	tree := testMeleeTree()
+	assert.NoError(t, validateBehaviorTree(&tree))
+
+	bad := btNode("selector", btAction("dance"))
+	assert.EqualError(t, validateBehaviorTree(&bad), `root.children[0]: unknown action "dance"`)
+
+	bad = btNode("inverter", btCondition("hasTarget"), btCondition("charging"))
+	assert.EqualError(t, validateBehaviorTree(&bad), "root: inverter needs exactly one child, has 2")
+
+	bad = btNode("sequence", config.BehaviorNodeConfig{Type: "condition", Condition: "targetWithin", Range: "far"})
+	assert.EqualError(t, validateBehaviorTree(&bad), `root.children[0]: unknown range "far"`)
+
+	bad = btAction("chase")
+	bad.Children = []config.BehaviorNodeConfig{btAction("idle")}
+	assert.EqualError(t, validateBehaviorTree(&bad), "root: action can't have children")
+
+	bad = btNode("sequence")
+	assert.EqualError(t, validateBehaviorTree(&bad), "root: sequence has no children")
+}
+
+func TestNewEnemyAI_LoadsBehaviorTree(t *testing.T) {
+	withBehaviorTree(t, "brawler", testMeleeTree())
+
+	cfg := createTestEnemyConfig("melee", 10)
+	cfg.AI.Behavior = "brawler"
+	assert.NotNil(t, NewEnemyAI(cfg).Tree)
+
+	cfg.AI.Behavior = "missing"
+	assert.Nil(t, NewEnemyAI(cfg).Tree, "unknown trees fall back to the built-in AI")
+
+	withBehaviorTree(t, "broken", btNode("selector", btAction("dance")))
+	cfg.AI.Behavior = "broken"
+	assert.Nil(t, NewEnemyAI(cfg).Tree, "invalid trees fall back to the built-in AI")
+}
+
+func TestNewEnemyAI_ExplicitAttackRangeIsKept(t *testing.T) {
+	cfg := createTestEnemyConfig("ranged", 20)
+	assert.Equal(t, 12.0, NewEnemyAI(cfg).AttackRange, "ranged default")
+
+	cfg.AI.AttackRange = 2.0
+	assert.Equal(t, 2.0, NewEnemyAI(cfg).AttackRange, "an explicit 2.0 isn't mistaken for the melee default")
+}
+
+func TestBehaviorTree_AttacksThenChasesThenIdles(t *testing.T) {
+	withBehaviorTree(t, "brawler", testMeleeTree())
+	ctx := newTreeContext()
+	ctx.Players["p1"].Position = Vector3{X: 1}
+	enemy := newTreeEnemy(t, "brawler", "melee", Vector3{})
+	enemy.AI.LastAttackTime = time.Now().Add(-time.Minute)
+
+	result := enemy.UpdateAI(ctx)
+	require.NotNil(t, result)
+	assert.Equal(t, AIStateAttack, enemy.AI.State)
+	assert.Equal(t, "p1", result.TargetID)
+	assert.Equal(t, 10.0, result.Damage)
+
+	ctx.Players["p1"].Position = Vector3{X: 1.5}
+	assert.Nil(t, enemy.UpdateAI(ctx), "on cooldown")
+	assert.Equal(t, AIStateChase, enemy.AI.State)
+	assert.Greater(t, enemy.Position.X, 0.0, "closes in while waiting to swing")
+
+	ctx.Players["p1"].Position = Vector3{X: 40}
+	enemy.UpdateAI(ctx)
+	assert.Equal(t, AIStateIdle, enemy.AI.State, "out of aggro range")
+}
+
+func TestBehaviorTree_SummonsConfiguredType(t *testing.T) {
+	summon := btAction("summon")
+	summon.EnemyType = "zombie"
+	withBehaviorTree(t, "raiser", btNode("sequence", btCondition("hasTarget"), btNode("selector", summon, btAction("wait"))))
+	ctx := newTreeContext()
+	ctx.Players["p1"].Position = Vector3{X: 10}
+	enemy := newTreeEnemy(t, "raiser", "summoner", Vector3{})
+
+	result := enemy.UpdateAI(ctx)
+	require.NotNil(t, result)
+	require.Len(t, result.SpawnEnemies, 1)
+	assert.Equal(t, "zombie", result.SpawnEnemies[0].Type)
+	assert.Equal(t, AIStateSupport, enemy.AI.State)
+
+	assert.Nil(t, enemy.UpdateAI(ctx), "summon is on cooldown, so the tree waits")
+}
+
+func TestBehaviorTree_StrafeCirclesTarget(t *testing.T) {
+	withBehaviorTree(t, "circler", btNode("sequence", btCondition("hasTarget"), btAction("strafe")))
+	ctx := newTreeContext()
+	enemy := newTreeEnemy(t, "circler", "ranged", Vector3{X: 10})
+
+	enemy.UpdateAI(ctx)
+	assert.Equal(t, AIStateChase, enemy.AI.State)
+	assert.InDelta(t, 0, enemy.Velocity.X, 0.001, "moves across the line to the target, not along it")
+	assert.NotZero(t, enemy.Velocity.Z)
+	assert.InDelta(t, 10, Distance2D(enemy.Position, Vector3{}), 0.05)
+}
+
+// The trees shipped in enemies.json must pass the server's own checks, not
+// just the validator's copy of them
+func TestConfiguredBehaviorTreesAreValid(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "..", "config", "shared", "enemies.json"))
+	require.NoError(t, err)
+	var enemies config.EnemiesData
+	require.NoError(t, json.Unmarshal(data, &enemies))
+	require.NotEmpty(t, enemies.Behaviors)
+
+	for name, tree := range enemies.Behaviors {
+		tree := tree
+		assert.NoError(t, validateBehaviorTree(&tree), "behavior %s", name)
+	}
+}
+
+// assertScriptMirrors checks that the map literal assigned to a package-level
+// var in a Go source file has the same string keys as table
+func assertScriptMirrors(t *testing.T, file *ast.File, name string, table interface{}) {
+	t.Helper()
+	var want []string
+	for _, key := range reflect.ValueOf(table).MapKeys() {
+		want = append(want, key.String())
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			if len(value.Names) != 1 || value.Names[0].Name != name || len(value.Values) != 1 {
+				continue
+			}
+			literal, ok := value.Values[0].(*ast.CompositeLit)
+			require.True(t, ok, "%s is not a map literal", name)
+			var got []string
+			for _, elt := range literal.Elts {
+				key, err := strconv.Unquote(elt.(*ast.KeyValueExpr).Key.(*ast.BasicLit).Value)
+				require.NoError(t, err)
+				got = append(got, key)
+			}
+			assert.ElementsMatch(t, want, got, "%s has drifted from the server's table", name)
+			return
+		}
+	}
+	assert.Fail(t, "var not found", name)
+}
+
+// The config validator can't import this package, so it keeps its own copy
+// of the behavior tree vocabulary; this keeps the copy in step
+func TestValidatorMirrorsBehaviorVocabulary(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), filepath.Join("..", "..", "..", "scripts", "validate_configs.go"), nil, 0)
+	require.NoError(t, err)
+
+	assertScriptMirrors(t, file, "behaviorConditions", behaviorConditions)
+	assertScriptMirrors(t, file, "behaviorActions", behaviorActions)
+	assertScriptMirrors(t, file, "behaviorRanges", behaviorRanges)
+}
@@ -8,9 +8,10 @@ import (
 // Board represents the hex grid layout for a game world.
 // It holds the metadata for all tiles and manages generation.
 type Board struct {
-	Seed  int64
-	Rings int // number of rings around town (determines board size)
-	rng   *rand.Rand
+	Seed     int64
+	Rings    int  // number of rings around town (determines board size)
+	PvPZones bool // outer-ring tiles become PvP zones as they generate
+	rng      *rand.Rand
 
 	Tiles map[HexCoord]*Tile // all tiles (generated or not)
 }
@@ -126,6 +127,7 @@ func (b *Board) EnsureTileGenerated(coord HexCoord) *Tile {
 		}
 
 		GenerateTile(tile, b.rng, neighborEdges)
+		b.assignPvPZone(tile)
 		log.Printf("[BOARD] Generated tile at (%d, %d, %d) biome=%s type=%s",
 			coord.Q, coord.R, coord.Layer, tile.Biome, tile.TileType)
 	}
@@ -0,0 +1,581 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+)
+
+// BossEventKind says what happened in a boss encounter
+type BossEventKind string
+
+const (
+	BossEventEngage    BossEventKind = "engage"    // Boss noticed the players; the first phase begins
+	BossEventPhase     BossEventKind = "phase"     // Health crossed a phase threshold
+	BossEventTelegraph BossEventKind = "telegraph" // Ground AoE warning; the hit lands after the warning time
+	BossEventEnrage    BossEventKind = "enrage"    // Enrage timer ran out
+	BossEventReset     BossEventKind = "reset"     // Everyone left or died; boss healed back to full
+	BossEventDefeated  BossEventKind = "defeated"  // Boss died and left a loot chest
+)
+
+// BossPhaseEvent is broadcast to everyone in the boss room so clients can
+// show the boss bar, phase changes and AoE warnings
+type BossPhaseEvent struct {
+	BossID     string
+	BossType   string
+	BossName   string
+	Kind       BossEventKind
+	Phase      int
+	PhaseName  string
+	Health     float64
+	MaxHealth  float64
+	Enraged    bool
+	Telegraph  *Telegraph // Set for telegraph events
+	ChestID    string     // Set for defeated events
+	Recipients []string   // Player IDs in the boss room
+}
+
+// Serialize converts the event to a boss_phase message body
+func (e BossPhaseEvent) Serialize() map[string]interface{} {
+	result := map[string]interface{}{
+		"bossID":    e.BossID,
+		"bossType":  e.BossType,
+		"bossName":  e.BossName,
+		"event":     string(e.Kind),
+		"phase":     e.Phase,
+		"phaseName": e.PhaseName,
+		"health":    e.Health,
+		"maxHealth": e.MaxHealth,
+		"enraged":   e.Enraged,
+	}
+	if e.Telegraph != nil {
+		result["telegraph"] = e.Telegraph.Serialize()
+	}
+	if e.ChestID != "" {
+		result["chestID"] = e.ChestID
+	}
+	return result
+}
+
+// Telegraph is a ground AoE that hits everyone inside it once its warning runs out
+type Telegraph struct {
+	ID         string
+	BossID     string
+	Ability    string
+	Name       string
+	Position   Vector3
+	Radius     float64
+	Damage     float64
+	DamageType DamageType
+	CastAt     time.Time
+	HitsAt     time.Time
+}
+
+// Serialize converts the telegraph to a map for JSON. Telegraphs are sent the
+// tick they're cast, so the warning counts from then.
+func (t *Telegraph) Serialize() map[string]interface{} {
+	return map[string]interface{}{
+		"id":             t.ID,
+		"ability":        t.Ability,
+		"name":           t.Name,
+		"position":       t.Position,
+		"radius":         t.Radius,
+		"damageType":     string(t.DamageType),
+		"warningSeconds": math.Max(0, t.HitsAt.Sub(t.CastAt).Seconds()),
+	}
+}
+
+// LootChest is left behind by a defeated boss and spills its items when opened
+type LootChest struct {
+	ID       string
+	BossType string
+	Position Vector3
+	Items    []*Item
+}
+
+// Serialize converts the chest to a map for JSON. Contents stay hidden until opened.
+func (c *LootChest) Serialize() map[string]interface{} {
+	return map[string]interface{}{
+		"id":       c.ID,
+		"bossType": c.BossType,
+		"position": c.Position,
+	}
+}
+
+// BossEncounter is the scripted state machine for a boss. It runs inside
+// EnemyAI.Update and queues what happened for the world to act on.
+type BossEncounter struct {
+	Type    string
+	Config  *config.BossConfig
+	Engaged bool
+	Phase   int
+	Enraged bool
+
+	engagedAt    time.Time
+	abilityReady map[string]time.Time
+	nextID       int
+
+	// Stats before phase and enrage multipliers, restored on reset
+	baseDamage     float64
+	baseMoveSpeed  float64
+	baseChaseSpeed float64
+
+	// Monster level damage multiplier, also applied to ability damage
+	levelDamage float64
+
+	// Drained by the world each tick
+	events     []BossPhaseEvent
+	telegraphs []*Telegraph
+	addWaves   []config.BossAddWaveConfig
+}
+
+// NewBossEncounter prepares the encounter script for a freshly spawned boss
+func NewBossEncounter(bossType string, cfg *config.BossConfig, enemy *Enemy) *BossEncounter {
+	b := &BossEncounter{
+		Type:         bossType,
+		Config:       cfg,
+		abilityReady: make(map[string]time.Time),
+		baseDamage:   enemy.Damage,
+		levelDamage:  1.0,
+	}
+	if enemy.AI != nil {
+		b.baseMoveSpeed = enemy.AI.MoveSpeed
+		b.baseChaseSpeed = enemy.AI.ChaseSpeed
+	}
+	return b
+}
+
+// currentPhase returns the config of the active phase, if the boss has any
+func (b *BossEncounter) currentPhase() (config.BossPhaseConfig, bool) {
+	if b.Phase < 0 || b.Phase >= len(b.Config.Phases) {
+		return config.BossPhaseConfig{}, false
+	}
+	return b.Config.Phases[b.Phase], true
+}
+
+// event builds an event describing the encounter's current state
+func (b *BossEncounter) event(enemy *Enemy, kind BossEventKind) BossPhaseEvent {
+	phase, _ := b.currentPhase()
+	return BossPhaseEvent{
+		BossID:    enemy.ID,
+		BossType:  b.Type,
+		BossName:  b.Config.Name,
+		Kind:      kind,
+		Phase:     b.Phase,
+		PhaseName: phase.Name,
+		Health:    enemy.Health,
+		MaxHealth: enemy.MaxHealth,
+		Enraged:   b.Enraged,
+	}
+}
+
+// update advances the encounter: engage, phase transitions, enrage and
+// ability casts, or a reset once the boss has nobody left to fight
+func (b *BossEncounter) update(enemy *Enemy, ai *EnemyAI, ctx *EnemyAIContext, now time.Time) {
+	if ai.TargetID == "" {
+		if b.Engaged {
+			b.reset(enemy, ai)
+		}
+		return
+	}
+
+	if !b.Engaged {
+		b.Engaged = true
+		b.engagedAt = now
+		b.Phase = 0
+		b.startPhase(enemy, ai, now)
+		b.events = append(b.events, b.event(enemy, BossEventEngage))
+	}
+
+	// Big hits can skip straight through several phases
+	for b.Phase+1 < len(b.Config.Phases) && enemy.Health/enemy.MaxHealth <= b.Config.Phases[b.Phase+1].HealthThreshold {
+		b.Phase++
+		b.startPhase(enemy, ai, now)
+		b.events = append(b.events, b.event(enemy, BossEventPhase))
+	}
+
+	if !b.Enraged && b.Config.EnrageSeconds > 0 && now.Sub(b.engagedAt).Seconds() >= b.Config.EnrageSeconds {
+		b.Enraged = true
+		b.applyStats(enemy, ai)
+		b.events = append(b.events, b.event(enemy, BossEventEnrage))
+	}
+
+	if !enemy.HasStatusEffect(StatusEffectStun) {
+		b.castReadyAbility(enemy, ai, ctx, now)
+	}
+}
+
+// startPhase applies a phase's multipliers and queues its add wave. Each
+// ability waits one cooldown before it's first used in a phase.
+func (b *BossEncounter) startPhase(enemy *Enemy, ai *EnemyAI, now time.Time) {
+	phase, ok := b.currentPhase()
+	if !ok {
+		return
+	}
+	b.applyStats(enemy, ai)
+	if phase.AddWave != nil && phase.AddWave.Count > 0 && len(phase.AddWave.EnemyTypes) > 0 {
+		b.addWaves = append(b.addWaves, *phase.AddWave)
+	}
+	for _, ability := range phase.Abilities {
+		if _, seen := b.abilityReady[ability]; !seen {
+			b.abilityReady[ability] = now.Add(time.Duration(b.Config.Abilities[ability].Cooldown * float64(time.Second) / 2))
+		}
+	}
+}
+
+// applyStats recomputes damage and speed from the base stats, the current
+// phase and enrage
+func (b *BossEncounter) applyStats(enemy *Enemy, ai *EnemyAI) {
+	damageMult, speedMult := 1.0, 1.0
+	if phase, ok := b.currentPhase(); ok {
+		if phase.DamageMultiplier > 0 {
+			damageMult *= phase.DamageMultiplier
+		}
+		if phase.SpeedMultiplier > 0 {
+			speedMult *= phase.SpeedMultiplier
+		}
+	}
+	if b.Enraged {
+		if b.Config.EnrageDamageMultiplier > 0 {
+			damageMult *= b.Config.EnrageDamageMultiplier
+		}
+		if b.Config.EnrageSpeedMultiplier > 0 {
+			speedMult *= b.Config.EnrageSpeedMultiplier
+		}
+	}
+
+	enemy.Damage = b.baseDamage * damageMult
+	ai.MoveSpeed = b.baseMoveSpeed * speedMult
+	ai.ChaseSpeed = b.baseChaseSpeed * speedMult
+}
+
+// reset returns the boss to full health and its first phase
+func (b *BossEncounter) reset(enemy *Enemy, ai *EnemyAI) {
+	b.Engaged = false
+	b.Enraged = false
+	b.Phase = 0
+	b.abilityReady = make(map[string]time.Time)
+	b.addWaves = nil
+	b.telegraphs = nil
+	enemy.Health = enemy.MaxHealth
+	b.applyStats(enemy, ai)
+	b.events = append(b.events, b.event(enemy, BossEventReset))
+}
+
+// castReadyAbility telegraphs the first ability of the current phase that's
+// off cooldown. Only one ability starts per tick.
+func (b *BossEncounter) castReadyAbility(enemy *Enemy, ai *EnemyAI, ctx *EnemyAIContext, now time.Time) {
+	phase, ok := b.currentPhase()
+	if !ok {
+		return
+	}
+
+	for _, abilityID := range phase.Abilities {
+		ability, ok := b.Config.Abilities[abilityID]
+		if !ok || now.Before(b.abilityReady[abilityID]) {
+			continue
+		}
+
+		positions := b.abilityTargets(enemy, ai, ability, ctx)
+		if len(positions) == 0 {
+			continue
+		}
+
+		damageType := DamageType(ability.DamageType)
+		if damageType == "" {
+			damageType = DamageTypePhysical
+		}
+		damage := ability.Damage
+		if b.levelDamage > 0 {
+			damage *= b.levelDamage
+		}
+		if b.baseDamage > 0 {
+			damage *= enemy.Damage / b.baseDamage // Phase and enrage multipliers
+		}
+
+		for _, pos := range positions {
+			b.nextID++
+			b.telegraphs = append(b.telegraphs, &Telegraph{
+				ID:         fmt.Sprintf("telegraph-%s-%d", enemy.ID, b.nextID),
+				BossID:     enemy.ID,
+				Ability:    abilityID,
+				Name:       ability.Name,
+				Position:   pos,
+				Radius:     ability.Radius,
+				Damage:     damage,
+				DamageType: damageType,
+				CastAt:     now,
+				HitsAt:     now.Add(time.Duration(ability.WarningSeconds * float64(time.Second))),
+			})
+		}
+		b.abilityReady[abilityID] = now.Add(time.Duration(ability.Cooldown * float64(time.Second)))
+		return
+	}
+}
+
+// abilityTargets returns where an ability's AoEs land
+func (b *BossEncounter) abilityTargets(enemy *Enemy, ai *EnemyAI, ability config.BossAbilityConfig, ctx *EnemyAIContext) []Vector3 {
+	switch ability.Target {
+	case "self":
+		return []Vector3{enemy.Position}
+	case "players":
+		reach := ability.Range
+		if reach <= 0 {
+			reach = ai.AggroRange
+		}
+		layer := layerFromY(enemy.Position.Y)
+		ids := make([]string, 0, len(ctx.Players))
+		for id, player := range ctx.Players {
+			if player.IsDead() || layerFromY(player.Position.Y) != layer {
+				continue
+			}
+			if Distance2D(enemy.Position, player.Position) <= reach {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		positions := make([]Vector3, 0, len(ids))
+		for _, id := range ids {
+			positions = append(positions, ctx.Players[id].Position)
+		}
+		return positions
+	default: // "target"
+		if pos, ok := ai.targetPosition(ctx); ok {
+			return []Vector3{pos}
+		}
+		return nil
+	}
+}
+
+// pickBossType chooses a boss for a dungeon of the given difficulty, or ""
+// if none is configured
+func pickBossType(difficulty int, rng *rand.Rand) string {
+	types := make([]string, 0, len(config.Enemies.Bosses))
+	for bossType, cfg := range config.Enemies.Bosses {
+		if difficulty >= cfg.MinDifficulty {
+			types = append(types, bossType)
+		}
+	}
+	if len(types) == 0 {
+		return ""
+	}
+	sort.Strings(types) // Map order is random; keep dungeons reproducible from their seed
+	return types[rng.Intn(len(types))]
+}
+
+// spawnBoss places a tile's boss in the middle of the room.
+// Caller must hold w.mu.
+func (w *World) spawnBoss(tile *Tile) {
+	w.nextEncounterID++
+	enemyID := fmt.Sprintf("boss-%s-tile-%d-%d-%d-%d", tile.BossType, tile.Coord.Q, tile.Coord.R, tile.Coord.Layer, w.nextEncounterID)
+	boss := NewEnemy(enemyID, tile.BossType, HexToWorld(tile.Coord))
+	boss.applyLevel(w.monsterLevel(tile.Difficulty))
+	home := tile.Coord
+	boss.HomeTile = &home
+	if boss.AI == nil || boss.AI.Boss == nil {
+		log.Printf("[BOSS] No encounter script for boss type '%s'", tile.BossType)
+	}
+	w.addEnemy(boss)
+	log.Printf("[BOSS] Spawned level %d %s in tile (%d,%d)", boss.Level, tile.BossType, tile.Coord.Q, tile.Coord.R)
+}
+
+// updateBoss carries out what a boss's encounter queued this tick: events,
+// telegraphed AoEs and add waves. Caller must hold w.mu.
+func (w *World) updateBoss(enemy *Enemy) {
+	if enemy.AI == nil || enemy.AI.Boss == nil {
+		return
+	}
+	encounter := enemy.AI.Boss
+
+	for _, event := range encounter.events {
+		if event.Kind == BossEventReset {
+			w.cancelTelegraphs(enemy.ID)
+		}
+		w.queueBossEvent(enemy, event)
+	}
+	encounter.events = nil
+
+	for _, telegraph := range encounter.telegraphs {
+		w.telegraphs = append(w.telegraphs, telegraph)
+		event := encounter.event(enemy, BossEventTelegraph)
+		event.Telegraph = telegraph
+		w.queueBossEvent(enemy, event)
+	}
+	encounter.telegraphs = nil
+
+	for _, wave := range encounter.addWaves {
+		w.spawnAddWave(enemy, wave)
+	}
+	encounter.addWaves = nil
+}
+
+// spawnAddWave brings in reinforcements in a ring around the boss.
+// Caller must hold w.mu.
+func (w *World) spawnAddWave(boss *Enemy, wave config.BossAddWaveConfig) {
+	radius := wave.Radius
+	if radius <= 0 {
+		radius = 5.0
+	}
+	for i := 0; i < wave.Count; i++ {
+		angle := 2 * math.Pi * float64(i) / float64(wave.Count)
+		pos := Vector3{
+			X: boss.Position.X + radius*math.Cos(angle),
+			Y: boss.Position.Y,
+			Z: boss.Position.Z + radius*math.Sin(angle),
+		}
+		if w.Board != nil && w.Board.collidesWithTerrain(pos, layerFromY(pos.Y), entityCollisionRadius, false) {
+			pos = w.resolveEntityMovement(boss.Position, pos)
+		}
+		enemyType := wave.EnemyTypes[i%len(wave.EnemyTypes)]
+		w.nextEncounterID++
+		add := NewEnemy(fmt.Sprintf("enemy-add-%s-%d", boss.ID, w.nextEncounterID), enemyType, pos)
+		add.applyLevel(boss.Level) // Adds fight at their boss's level
+		w.addEnemy(add)
+	}
+}
+
+// cancelTelegraphs drops pending AoEs from a boss that reset or died.
+// Caller must hold w.mu.
+func (w *World) cancelTelegraphs(bossID string) {
+	kept := w.telegraphs[:0]
+	for _, telegraph := range w.telegraphs {
+		if telegraph.BossID != bossID {
+			kept = append(kept, telegraph)
+		}
+	}
+	w.telegraphs = kept
+}
+
+// resolveTelegraphs lands AoEs whose warning has run out.
+// Caller must hold w.mu.
+func (w *World) resolveTelegraphs(now time.Time) {
+	pending := w.telegraphs[:0]
+	for _, telegraph := range w.telegraphs {
+		if now.Before(telegraph.HitsAt) {
+			pending = append(pending, telegraph)
+			continue
+		}
+
+		layer := layerFromY(telegraph.Position.Y)
+		for _, player := range w.players {
+			if layerFromY(player.Position.Y) != layer || Distance2D(telegraph.Position, player.Position) > telegraph.Radius {
+				continue
+			}
+			w.damagePlayer(player, DamageInfo{
+				Amount:      telegraph.Damage,
+				Type:        telegraph.DamageType,
+				SourceID:    telegraph.BossID,
+				TargetID:    player.ID,
+				AbilityType: telegraph.Ability,
+			}, "enemy", w.enemySource(telegraph.BossID))
+		}
+		w.abilityCastEvents = append(w.abilityCastEvents, AbilityCastEvent{
+			CasterID:    telegraph.BossID,
+			CasterType:  "enemy",
+			OwnerID:     telegraph.BossID,
+			AbilityType: telegraph.Ability,
+			Position:    telegraph.Position,
+		})
+	}
+	w.telegraphs = pending
+}
+
+// bossDefeated ends an encounter: pending AoEs are cancelled, the boss room
+// is marked cleared so it never respawns, and a loot chest is left where the
+// boss fell. Caller must hold w.mu.
+func (w *World) bossDefeated(enemy *Enemy) {
+	if enemy.AI == nil || enemy.AI.Boss == nil {
+		return
+	}
+	encounter := enemy.AI.Boss
+	w.cancelTelegraphs(enemy.ID)
+
+	if enemy.HomeTile != nil && w.Board != nil {
+		if tile := w.Board.GetTile(*enemy.HomeTile); tile != nil {
+			tile.BossDefeated = true
+		}
+	}
+
+	w.nextEncounterID++
+	chest := &LootChest{
+		ID:       fmt.Sprintf("chest-%s-%d", encounter.Type, w.nextEncounterID),
+		BossType: encounter.Type,
+		Position: enemy.Position,
+	}
+	itemLevel := enemy.lootItemLevel()
+	for i := 0; i < max(encounter.Config.Loot.Items, 1); i++ {
+		itemID := fmt.Sprintf("item-%d", w.nextItemID)
+		w.nextItemID++
+		item := NewItemWithRarityBonus(itemID, lootItemTypes[rand.Intn(len(lootItemTypes))], itemLevel, encounter.Config.Loot.RarityBonus)
+		item.Unidentified = item.Rarity != ItemRarityNormal
+		chest.Items = append(chest.Items, item)
+	}
+	if w.lootChests == nil {
+		w.lootChests = make(map[string]*LootChest)
+	}
+	w.lootChests[chest.ID] = chest
+
+	event := encounter.event(enemy, BossEventDefeated)
+	event.ChestID = chest.ID
+	w.queueBossEvent(enemy, event)
+	log.Printf("[BOSS] %s defeated, left chest %s with %d items", encounter.Config.Name, chest.ID, len(chest.Items))
+}
+
+// queueBossEvent addresses an event to the players in the boss's room.
+// Caller must hold w.mu.
+func (w *World) queueBossEvent(boss *Enemy, event BossPhaseEvent) {
+	room := WorldToHex(boss.Position, layerFromY(boss.Position.Y))
+	for id, player := range w.players {
+		if WorldToHex(player.Position, layerFromY(player.Position.Y)) == room {
+			event.Recipients = append(event.Recipients, id)
+		}
+	}
+	sort.Strings(event.Recipients)
+	w.bossEvents = append(w.bossEvents, event)
+}
+
+// DrainBossEvents returns and clears boss events waiting to be broadcast
+func (w *World) DrainBossEvents() []BossPhaseEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	events := w.bossEvents
+	w.bossEvents = nil
+	return events
+}
+
+// OpenChest spills a boss chest's items onto the ground around it
+func (w *World) OpenChest(playerID, chestID string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, exists := w.players[playerID]
+	if !exists {
+		return fmt.Errorf("player not found")
+	}
+	if player.IsDead() {
+		return fmt.Errorf("player is dead")
+	}
+
+	chest, exists := w.lootChests[chestID]
+	if !exists {
+		return fmt.Errorf("chest not found")
+	}
+	if layerFromY(player.Position.Y) != layerFromY(chest.Position.Y) || Distance2D(player.Position, chest.Position) > 3.0 {
+		return fmt.Errorf("too far from chest")
+	}
+
+	for _, item := range chest.Items {
+		groundItemID := fmt.Sprintf("ground-%s", item.ID)
+		w.groundItems[groundItemID] = NewGroundItem(groundItemID, item, w.findOpenDropPosition(chest.Position))
+	}
+	delete(w.lootChests, chestID)
+
+	log.Printf("[BOSS] Player %s opened %s", player.Username, chestID)
+	return nil
+}
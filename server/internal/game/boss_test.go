@@ -0,0 +1,243 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withTestBoss registers a three-phase boss for the duration of a test
+func withTestBoss(t *testing.T) {
+	t.Helper()
+	withEnemyConfig(t, "test_boss", config.EnemyConfig{
+		Name: "Test Boss", Health: 1000, MaxHealth: 1000, MoveSpeed: 2, Damage: 20, XPReward: 100,
+		AI: config.EnemyAI{Type: "melee", AggroRange: 15, ChaseSpeed: 2, AttackRange: 3},
+	})
+	withEnemyConfig(t, "skeleton", *createTestEnemyConfig("melee", 10))
+
+	previous := config.Enemies.Bosses
+	config.Enemies.Bosses = map[string]config.BossConfig{
+		"test_boss": {
+			Name:                   "Test Boss",
+			EnrageSeconds:          60,
+			EnrageDamageMultiplier: 2,
+			Abilities: map[string]config.BossAbilityConfig{
+				"slam":   {Name: "Slam", Damage: 40, Radius: 4, WarningSeconds: 1, Cooldown: 8, Target: "self"},
+				"spikes": {Name: "Spikes", Damage: 25, DamageType: "cold", Radius: 2, WarningSeconds: 1, Cooldown: 6, Target: "players"},
+			},
+			Phases: []config.BossPhaseConfig{
+				{Name: "One", HealthThreshold: 1.0, Abilities: []string{"slam"}},
+				{Name: "Two", HealthThreshold: 0.6, Abilities: []string{"slam", "spikes"},
+					AddWave: &config.BossAddWaveConfig{EnemyTypes: []string{"skeleton"}, Count: 3, Radius: 4}},
+				{Name: "Three", HealthThreshold: 0.25, Abilities: []string{"slam", "spikes"}, DamageMultiplier: 1.5},
+			},
+			Loot: config.BossLootConfig{Items: 3, RarityBonus: 1.0},
+		},
+	}
+	t.Cleanup(func() { config.Enemies.Bosses = previous })
+}
+
+// newBossTestWorld returns a world with the test boss and one player next to it
+func newBossTestWorld(t *testing.T) (*World, *Enemy, *Player) {
+	t.Helper()
+	withTestBoss(t)
+	world := newTestWorldWithoutEnemies()
+	boss := NewEnemy("boss-1", "test_boss", Vector3{})
+	require.NotNil(t, boss.AI.Boss)
+	world.addEnemy(boss)
+
+	player := NewPlayer("p1", "One")
+	player.Position = Vector3{X: 2}
+	world.players[player.ID] = player
+	return world, boss, player
+}
+
+// tickBoss runs one AI update for the boss and hands its queue to the world
+func tickBoss(world *World, boss *Enemy) {
+	ctx := &EnemyAIContext{Players: world.players, Enemies: world.enemies, Minions: world.minions, DeltaSeconds: 0.05, World: world}
+	boss.UpdateAI(ctx)
+	world.updateBoss(boss)
+}
+
+func bossEventKinds(events []BossPhaseEvent) []BossEventKind {
+	kinds := make([]BossEventKind, 0, len(events))
+	for _, event := range events {
+		kinds = append(kinds, event.Kind)
+	}
+	return kinds
+}
+
+func TestBoss_PhasesFollowHealth(t *testing.T) {
+	world, boss, player := newBossTestWorld(t)
+
+	tickBoss(world, boss)
+	events := world.DrainBossEvents()
+	require.Equal(t, []BossEventKind{BossEventEngage}, bossEventKinds(events))
+	assert.Equal(t, "One", events[0].PhaseName)
+	assert.Equal(t, []string{player.ID}, events[0].Recipients)
+
+	boss.Health = 200 // Skips straight past phase two
+	tickBoss(world, boss)
+	events = world.DrainBossEvents()
+	require.Equal(t, []BossEventKind{BossEventPhase, BossEventPhase}, bossEventKinds(events))
+	assert.Equal(t, "Two", events[0].PhaseName)
+	assert.Equal(t, "Three", events[1].PhaseName)
+	assert.Equal(t, 30.0, boss.Damage, "phase three hits harder")
+	assert.Len(t, world.enemies, 4, "phase two's add wave still arrives")
+}
+
+func TestBoss_TelegraphWarnsThenHits(t *testing.T) {
+	world, boss, player := newBossTestWorld(t)
+	tickBoss(world, boss)
+	world.DrainBossEvents()
+
+	boss.AI.Boss.abilityReady["slam"] = time.Now().Add(-time.Second)
+	tickBoss(world, boss)
+	events := world.DrainBossEvents()
+	require.Equal(t, []BossEventKind{BossEventTelegraph}, bossEventKinds(events))
+	require.Len(t, world.telegraphs, 1)
+	telegraph := world.telegraphs[0]
+	assert.Equal(t, "slam", telegraph.Ability)
+	assert.InDelta(t, 0, Distance2D(boss.Position, telegraph.Position), 0.5, "slam is centered on the boss")
+
+	healthBefore := player.Health
+	world.resolveTelegraphs(time.Now())
+	assert.Equal(t, healthBefore, player.Health, "nothing lands during the warning")
+
+	world.resolveTelegraphs(telegraph.HitsAt)
+	assert.Equal(t, healthBefore-40, player.Health)
+	assert.Empty(t, world.telegraphs)
+}
+
+func TestBoss_EnrageAndReset(t *testing.T) {
+	world, boss, player := newBossTestWorld(t)
+	tickBoss(world, boss)
+	world.DrainBossEvents()
+
+	boss.AI.Boss.engagedAt = time.Now().Add(-61 * time.Second)
+	tickBoss(world, boss)
+	assert.Contains(t, bossEventKinds(world.DrainBossEvents()), BossEventEnrage)
+	assert.True(t, boss.AI.Boss.Enraged)
+	assert.Equal(t, 40.0, boss.Damage)
+
+	boss.Health = 500
+	world.telegraphs = append(world.telegraphs, &Telegraph{BossID: boss.ID, HitsAt: time.Now().Add(time.Hour)})
+	delete(world.players, player.ID)
+	tickBoss(world, boss)
+	assert.Equal(t, []BossEventKind{BossEventReset}, bossEventKinds(world.DrainBossEvents()))
+	assert.Equal(t, boss.MaxHealth, boss.Health)
+	assert.False(t, boss.AI.Boss.Enraged)
+	assert.Equal(t, 20.0, boss.Damage)
+	assert.Empty(t, world.telegraphs, "pending AoEs are cancelled on reset")
+}
+
+func TestBoss_DefeatLeavesChest(t *testing.T) {
+	world, boss, player := newBossTestWorld(t)
+	boss.applyLevel(7)
+	tickBoss(world, boss)
+	world.DrainBossEvents()
+
+	world.hitEnemy(player.ID, player.ID, &Ability{Type: "bolt", DamageType: DamageTypeFire}, boss.MaxHealth, boss)
+	require.True(t, boss.Dead)
+	events := world.DrainBossEvents()
+	require.Len(t, events, 1)
+	assert.Equal(t, BossEventDefeated, events[0].Kind)
+	assert.Empty(t, world.groundItems, "bosses don't drop regular loot")
+
+	chest := world.lootChests[events[0].ChestID]
+	require.NotNil(t, chest)
+	require.Len(t, chest.Items, 3)
+	for _, item := range chest.Items {
+		assert.Equal(t, ItemRarityUnique, item.Rarity)
+		assert.Equal(t, boss.lootItemLevel(), item.Level, "chest loot scales with the boss's level")
+	}
+	assert.Equal(t, 7, boss.lootItemLevel())
+
+	player.Position = Vector3{X: 10}
+	assert.EqualError(t, world.OpenChest(player.ID, chest.ID), "too far from chest")
+	player.Position = Vector3{X: 1}
+	require.NoError(t, world.OpenChest(player.ID, chest.ID))
+	assert.Len(t, world.groundItems, 3)
+	assert.Empty(t, world.lootChests)
+}
+
+func TestBoss_IgnoresPlayersOnOtherLayers(t *testing.T) {
+	world, boss, player := newBossTestWorld(t)
+	boss.Position = Vector3{Y: -20}
+	player.Position = Vector3{X: 2, Y: -20}
+	above := NewPlayer("p2", "Two")
+	above.Position = Vector3{X: 2}
+	world.players[above.ID] = above
+
+	ctx := &EnemyAIContext{Players: world.players, Enemies: world.enemies, World: world}
+	spikes := config.Enemies.Bosses["test_boss"].Abilities["spikes"]
+	assert.Equal(t, []Vector3{player.Position}, boss.AI.Boss.abilityTargets(boss, boss.AI, spikes, ctx),
+		"the overworld player standing above the boss room isn't targeted")
+
+	chest := &LootChest{ID: "chest-1", Position: Vector3{X: 1, Y: -20}}
+	world.lootChests = map[string]*LootChest{chest.ID: chest}
+	assert.EqualError(t, world.OpenChest(above.ID, chest.ID), "too far from chest")
+	require.NoError(t, world.OpenChest(player.ID, chest.ID))
+}
+
+func TestGenerateDungeon_BossRoomGetsBoss(t *testing.T) {
+	withTestBoss(t)
+	tiles := GenerateDungeon(HexCoord{Q: 2, R: 0}, "forest", 1, rand.New(rand.NewSource(1)))
+
+	bossRoom := tiles[len(tiles)-1]
+	assert.Equal(t, "test_boss", bossRoom.BossType)
+	assert.Equal(t, "test_boss", bossRoom.Serialize()["bossType"])
+	for _, tile := range tiles[:len(tiles)-1] {
+		assert.Empty(t, tile.BossType)
+	}
+}
+
+func TestBoss_DefeatedRoomStaysCleared(t *testing.T) {
+	withTestBoss(t)
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	room := world.Board.GetTile(HexCoord{})
+	room.Active = true
+	room.BossType = "test_boss"
+	room.Spawns = []EnemySpawnPoint{{Position: Vector3{Z: 5}, EnemyTypes: []string{"skeleton"}, Count: 2}}
+	player := NewPlayer("p1", "One")
+	world.players[player.ID] = player
+
+	world.checkTileRespawns()
+	var boss *Enemy
+	for _, enemy := range world.enemies {
+		if enemy.AI != nil && enemy.AI.Boss != nil {
+			boss = enemy
+		}
+	}
+	require.NotNil(t, boss)
+
+	world.hitEnemy(player.ID, player.ID, &Ability{Type: "bolt"}, boss.MaxHealth, boss)
+	require.True(t, boss.Dead)
+	assert.True(t, room.BossDefeated)
+	assert.Equal(t, true, room.Serialize()["bossDefeated"])
+	for _, enemy := range world.enemies {
+		enemy.Dead = true // the escort falls too
+	}
+
+	world.checkTileRespawns()
+	for _, enemy := range world.enemies {
+		assert.True(t, enemy.Dead, "%s respawned in a cleared boss room", enemy.ID)
+	}
+	assert.Len(t, world.lootChests, 1)
+}
+
+func TestSpawnBoss_UniqueIDs(t *testing.T) {
+	withTestBoss(t)
+	world := newTestWorldWithoutEnemies()
+	room := NewTile(HexCoord{}, "forest", TileTypeDungeon, 1)
+	room.BossType = "test_boss"
+
+	world.spawnBoss(room)
+	world.spawnBoss(room)
+	assert.Len(t, world.enemies, 2)
+}
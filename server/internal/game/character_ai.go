@@ -1,8 +1,11 @@
 package game
 
 import (
+	"encoding/json"
+	"log"
 	"math"
 	"math/rand"
+	"time"
 )
 
 // CharacterMood represents the character's emotional state
@@ -43,9 +46,15 @@ type CharacterAI struct {
 	PriorityTargetID string
 
 	// Internal state
-	lastDecisionAge float64 // seconds since last decision
-	decisionRate    float64 // how often to make decisions (seconds)
-	consecutiveBadCalls int // how many times player sent them into danger
+	lastDecisionAge     float64         // seconds since last decision
+	decisionRate        float64         // how often to make decisions (seconds)
+	consecutiveBadCalls int             // how many times player sent them into danger
+	pending             chan *LLMAction // answer to the LLM request in flight, if any
+	llmTimeout          float64         // how long an LLM answer is worth waiting for (seconds)
+
+	// Footwork ordered by a dodge or retreat (see character_ai_movement.go)
+	moveDir  Vector3
+	moveLeft float64 // seconds
 }
 
 // NewCharacterAI creates a new character AI with default personality
@@ -56,9 +65,75 @@ func NewCharacterAI() *CharacterAI {
 		Aggression:   0.5,
 		Preference:   AbilityFireball,
 		decisionRate: 0.5, // Decide every 0.5 seconds
+		llmTimeout:   3.0, // Local models can take a few decisions to answer
 	}
 }
 
+// RollPersonality gives a new character its own temperament: how
+// aggressively it fights and which attack it reaches for first. Rolled once
+// when the character is created and kept for its lifetime.
+func (ai *CharacterAI) RollPersonality() {
+	ai.Aggression = 0.2 + rand.Float64()*0.6
+
+	var offensive []AbilityType
+	for _, aType := range RegisteredAbilities() {
+		if ability, ok := GetAbility(aType); ok && ability.IsOffensive() {
+			offensive = append(offensive, aType)
+		}
+	}
+	if len(offensive) > 0 {
+		ai.Preference = offensive[rand.Intn(len(offensive))]
+	}
+}
+
+// characterAISave is the part of a character's AI that lasts between sessions
+type characterAISave struct {
+	Trust               float64       `json:"trust"`
+	Mood                CharacterMood `json:"mood"`
+	Aggression          float64       `json:"aggression"`
+	Preference          AbilityType   `json:"preference"`
+	ConsecutiveBadCalls int           `json:"consecutiveBadCalls"`
+}
+
+// ToSaveData serializes the character AI for database persistence
+func (ai *CharacterAI) ToSaveData() (json.RawMessage, error) {
+	return json.Marshal(characterAISave{
+		Trust:               ai.Trust,
+		Mood:                ai.Mood,
+		Aggression:          ai.Aggression,
+		Preference:          ai.Preference,
+		ConsecutiveBadCalls: ai.consecutiveBadCalls,
+	})
+}
+
+// RestoreFromSave restores the character AI from database data. Returns false,
+// leaving the AI untouched, if there's nothing saved.
+func (ai *CharacterAI) RestoreFromSave(data json.RawMessage) bool {
+	var saved characterAISave
+	if len(data) == 0 || string(data) == "{}" || string(data) == "null" {
+		return false
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		log.Printf("[LOAD] Failed to unmarshal character AI: %v", err)
+		return false
+	}
+
+	ai.Trust = math.Max(0, math.Min(100, saved.Trust))
+	ai.Aggression = math.Max(0, math.Min(1, saved.Aggression))
+	ai.consecutiveBadCalls = saved.ConsecutiveBadCalls
+	switch saved.Mood {
+	case MoodNeutral, MoodConfident, MoodAnxious, MoodFrustrated, MoodRefusing:
+		ai.Mood = saved.Mood
+	default:
+		ai.Mood = MoodNeutral
+	}
+	// Abilities can be removed from config; keep the default if it's gone
+	if _, ok := GetAbility(saved.Preference); ok {
+		ai.Preference = saved.Preference
+	}
+	return true
+}
+
 // Update runs one AI tick. Returns an action if the character wants to do something.
 func (ai *CharacterAI) Update(delta float64, player *Player, enemies map[string]*Enemy) *AIAction {
 	ai.lastDecisionAge += delta
@@ -120,6 +195,7 @@ func (ai *CharacterAI) Update(delta float64, player *Player, enemies map[string]
 		ai.tendMood(MoodAnxious, 0.3)
 		return &AIAction{
 			Type:      "dodge",
+			Ability:   ai.chooseMovementAbility(player),
 			Direction: Vector3{X: -dir.X, Z: -dir.Z},
 			Mood:      ai.Mood,
 			Dialogue:  "Too close!",
@@ -211,7 +287,7 @@ func (ai *CharacterAI) chooseAbility(player *Player, target *Enemy) *Ability {
 	dist := Distance2D(player.Position, target.Position)
 
 	// Try abilities in priority order based on range and preference
-	abilityOrder := []AbilityType{ai.Preference, AbilityFireball, AbilityFrostbolt, AbilityLightning, AbilityBasicAttack}
+	abilityOrder := append([]AbilityType{ai.Preference}, RegisteredAbilities()...)
 	seen := make(map[AbilityType]bool)
 
 	for _, aType := range abilityOrder {
@@ -220,12 +296,12 @@ func (ai *CharacterAI) chooseAbility(player *Player, target *Enemy) *Ability {
 		}
 		seen[aType] = true
 
-		if !player.Abilities.CanUseAbility(aType) {
+		if !player.Abilities.CanUseAbility(aType) || !player.Abilities.CanAfford(aType, player.Mana) {
 			continue
 		}
 
 		ability := player.Abilities.abilities[aType]
-		if ability == nil {
+		if ability == nil || !ability.IsOffensive() {
 			continue
 		}
 
@@ -246,6 +322,25 @@ func (ai *CharacterAI) chooseAbility(player *Player, target *Enemy) *Ability {
 	return nil
 }
 
+// chooseMovementAbility returns a ready movement ability to dodge with, or ""
+// if none is available and the character should just step away.
+func (ai *CharacterAI) chooseMovementAbility(player *Player) AbilityType {
+	if player.Abilities == nil {
+		return ""
+	}
+
+	for _, aType := range RegisteredAbilities() {
+		ability, ok := player.Abilities.GetAbility(aType)
+		if !ok || !ability.IsMovement() {
+			continue
+		}
+		if player.Abilities.CanUseAbility(aType) && player.Abilities.CanAfford(aType, player.Mana) {
+			return aType
+		}
+	}
+	return ""
+}
+
 // tendMood gradually shifts mood toward a target mood
 func (ai *CharacterAI) tendMood(target CharacterMood, strength float64) {
 	// Simple: just set it if strength is high enough
@@ -303,42 +398,59 @@ func (ai *CharacterAI) pickDialogue(ability AbilityType, mood CharacterMood) str
 	}
 }
 
-// UpdateWithLLM attempts to use the LLM for decision-making.
-// If the LLM returns nil (unavailable or error), falls back to the behavior tree.
+// UpdateWithLLM uses the LLM for decision-making without blocking the game
+// loop: one request is kept in flight and its answer acted on in whichever
+// tick it arrives. Decisions that come due while the LLM is still thinking,
+// and requests that can't be answered (LLM down, queue full, too slow), fall
+// back to the behavior tree.
 func (ai *CharacterAI) UpdateWithLLM(delta float64, player *Player, enemies map[string]*Enemy, llm *LLMManager) *AIAction {
+	if ai.pending != nil {
+		select {
+		case llmAction := <-ai.pending:
+			ai.pending = nil
+			if llmAction != nil {
+				// Aim at the world as it is now, not as it was when asked
+				action, err := llmAction.ToAIAction(player, enemies)
+				if err == nil {
+					ai.Mood = action.Mood
+					return action
+				}
+				log.Printf("[LLM] Rejected action for player %s: %v (falling back)", player.ID, err)
+			}
+			// LLM returned nil or an unusable action = fallback to behavior tree
+			if len(enemies) > 0 {
+				return ai.behaviorTreeDecision(player, enemies)
+			}
+		default:
+			// Still thinking
+		}
+	}
+
 	ai.lastDecisionAge += delta
 	if ai.lastDecisionAge < ai.decisionRate {
 		return nil
 	}
+	ai.lastDecisionAge = 0
 
 	if len(enemies) == 0 {
-		ai.lastDecisionAge = 0
+		ai.pending = nil
 		ai.tendMood(MoodNeutral, 0.1)
 		return nil
 	}
 
-	// Try LLM if available
-	if llm != nil {
-		snapshot := BuildStateSnapshot(player, enemies)
-		resultCh := llm.RequestDecision(player.ID, snapshot)
+	if ai.pending != nil {
+		// Keep fighting while the LLM thinks; its answer is used when it lands
+		return ai.behaviorTreeDecision(player, enemies)
+	}
 
-		// Non-blocking check with tiny timeout (don't block the game loop)
-		select {
-		case action := <-resultCh:
-			ai.lastDecisionAge = 0
-			if action != nil {
-				// LLM produced a result - apply mood
-				ai.Mood = action.Mood
-				return action
-			}
-			// LLM returned nil = fallback to behavior tree
-		default:
-			// LLM is busy, use behavior tree this tick
-		}
+	if llm != nil && llm.Ready() {
+		snapshot := BuildStateSnapshot(player, enemies)
+		timeout := time.Duration(ai.llmTimeout * float64(time.Second))
+		ai.pending = llm.RequestDecision(player.ID, snapshot, timeout)
+		return nil
 	}
 
 	// Behavior tree fallback (reuse existing Update logic)
-	ai.lastDecisionAge = 0
 	return ai.behaviorTreeDecision(player, enemies)
 }
 
@@ -384,6 +496,7 @@ func (ai *CharacterAI) behaviorTreeDecision(player *Player, enemies map[string]*
 		ai.tendMood(MoodAnxious, 0.3)
 		return &AIAction{
 			Type:      "dodge",
+			Ability:   ai.chooseMovementAbility(player),
 			Direction: Vector3{X: -dir.X, Z: -dir.Z},
 			Mood:      ai.Mood,
 			Dialogue:  "Too close!",
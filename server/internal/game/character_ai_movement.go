@@ -0,0 +1,162 @@
+package game
+
+import "math"
+
+const (
+	// aiDodgeLookahead is how many seconds ahead the character watches for
+	// projectiles about to hit it
+	aiDodgeLookahead = 0.75
+	// aiDodgeMargin is the clearance kept beyond a projectile's hit radius
+	aiDodgeMargin = 0.5
+
+	// aiRetreatHealthPct is the health fraction below which the character
+	// falls back toward its allies
+	aiRetreatHealthPct = 0.3
+	// aiAllyCloseRange is how close an ally must be to count as reached
+	aiAllyCloseRange = 2.0
+
+	// aiKiteMinRange and aiKiteMaxRange are the distances the character keeps
+	// from the nearest enemy at aggression 1 and 0
+	aiKiteMinRange = 2.0
+	aiKiteMaxRange = 9.0
+	// aiKiteSlack is how far inside the preferred range an enemy may get
+	// before the character backs off
+	aiKiteSlack = 1.0
+
+	// aiMoveOrderSeconds is how long a dodge or retreat keeps the character moving
+	aiMoveOrderSeconds = 0.6
+)
+
+// AISteerContext is what the character reacts to when it steers
+type AISteerContext struct {
+	Enemies     map[string]*Enemy
+	Projectiles []*Projectile // Enemy projectiles in flight
+	Allies      []*Player     // Living players who can't harm the character
+}
+
+// Steer returns the direction the character wants to walk this tick, or zero
+// to stand still. It only handles short-term footwork; where the party goes
+// is the player's call, so the character never walks toward enemies and any
+// move input from the player takes precedence. In priority order it
+// sidesteps incoming projectiles, carries out a dodge or retreat it decided
+// on, falls back toward allies at low health, and backs off from enemies
+// inside its preferred range.
+func (ai *CharacterAI) Steer(delta float64, player *Player, ctx *AISteerContext) Vector3 {
+	if ai.moveLeft > 0 {
+		ai.moveLeft -= delta
+	}
+
+	// The player is steering
+	if player.Velocity != (Vector3{}) {
+		ai.moveLeft = 0
+		return Vector3{}
+	}
+
+	if dir, ok := sidestepDirection(player, ctx.Projectiles); ok {
+		return dir
+	}
+	if ai.moveLeft > 0 {
+		return ai.moveDir
+	}
+
+	nearest := findNearestEnemy(player.Position, ctx.Enemies)
+	if nearest == nil {
+		return Vector3{}
+	}
+	if player.Health/player.MaxHealth < aiRetreatHealthPct {
+		return retreatDirection(player, nearest, ctx.Allies)
+	}
+
+	if Distance2D(player.Position, nearest.Position) < ai.preferredRange()-aiKiteSlack {
+		return awayFrom(player.Position, nearest.Position)
+	}
+	return Vector3{}
+}
+
+// OrderMove keeps the character walking in dir for a moment, for dodge and
+// retreat actions that don't use a movement ability
+func (ai *CharacterAI) OrderMove(dir Vector3) {
+	dir = Normalize2D(Vector3{X: dir.X, Z: dir.Z})
+	if dir == (Vector3{}) {
+		return
+	}
+	ai.moveDir = dir
+	ai.moveLeft = aiMoveOrderSeconds
+}
+
+// preferredRange is how far from enemies the character likes to fight:
+// aggressive characters stay close, cautious ones keep their distance
+func (ai *CharacterAI) preferredRange() float64 {
+	aggression := math.Max(0, math.Min(1, ai.Aggression))
+	return aiKiteMinRange + (1-aggression)*(aiKiteMaxRange-aiKiteMinRange)
+}
+
+// sidestepDirection returns the way out of the path of the enemy projectile
+// that will hit the player soonest, if any will within the lookahead
+func sidestepDirection(player *Player, projectiles []*Projectile) (Vector3, bool) {
+	var best Vector3
+	soonest := aiDodgeLookahead
+	found := false
+
+	for _, projectile := range projectiles {
+		speed := math.Sqrt(projectile.Velocity.X*projectile.Velocity.X + projectile.Velocity.Z*projectile.Velocity.Z)
+		if speed < 0.001 {
+			continue
+		}
+		heading := Vector3{X: projectile.Velocity.X / speed, Z: projectile.Velocity.Z / speed}
+		toPlayer := Vector3{X: player.Position.X - projectile.Position.X, Z: player.Position.Z - projectile.Position.Z}
+
+		along := Dot2D(toPlayer, heading)
+		if along <= 0 {
+			continue // already past
+		}
+		impact := along / speed
+		if impact > soonest {
+			continue
+		}
+
+		// Same hit radius the world uses for enemy projectiles
+		offset := Vector3{X: toPlayer.X - heading.X*along, Z: toPlayer.Z - heading.Z*along}
+		if math.Sqrt(offset.X*offset.X+offset.Z*offset.Z) > projectile.Radius+0.5+aiDodgeMargin {
+			continue
+		}
+
+		// Step further out on the side the player is already on
+		side := Vector3{X: -heading.Z, Z: heading.X}
+		if Dot2D(offset, side) < 0 {
+			side = Vector3{X: heading.Z, Z: -heading.X}
+		}
+		best, soonest, found = side, impact, true
+	}
+	return best, found
+}
+
+// retreatDirection falls back from the nearest enemy, toward the closest ally
+// when there is one worth running to
+func retreatDirection(player *Player, nearest *Enemy, allies []*Player) Vector3 {
+	away := awayFrom(player.Position, nearest.Position)
+
+	var closest *Player
+	closestDist := math.MaxFloat64
+	for _, ally := range allies {
+		if d := Distance2D(player.Position, ally.Position); d < closestDist {
+			closest, closestDist = ally, d
+		}
+	}
+	if closest == nil || closestDist <= aiAllyCloseRange {
+		return away
+	}
+
+	toAlly := Normalize2D(Vector3{X: closest.Position.X - player.Position.X, Z: closest.Position.Z - player.Position.Z})
+	dir := Normalize2D(Vector3{X: away.X + toAlly.X, Z: away.Z + toAlly.Z})
+	if dir == (Vector3{}) {
+		// The ally is behind the enemy; get clear first
+		return away
+	}
+	return dir
+}
+
+// awayFrom returns the unit direction from threat to pos
+func awayFrom(pos, threat Vector3) Vector3 {
+	return Normalize2D(Vector3{X: pos.X - threat.X, Z: pos.Z - threat.Z})
+}
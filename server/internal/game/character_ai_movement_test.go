@@ -0,0 +1,118 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSteerTest(aggression float64) (*CharacterAI, *Player, *AISteerContext) {
+	ai := NewCharacterAI()
+	ai.Aggression = aggression
+	player := NewPlayer("p1", "One")
+	player.CharAI = ai
+	return ai, player, &AISteerContext{Enemies: map[string]*Enemy{}}
+}
+
+func TestSteer_KitesAtPreferredRange(t *testing.T) {
+	ai, player, ctx := newSteerTest(0)
+	enemy := createTestEnemy("e1", "zombie", Vector3{X: 4}, createTestEnemyConfig("melee", 10))
+	ctx.Enemies[enemy.ID] = enemy
+
+	dir := ai.Steer(0.1, player, ctx)
+	assert.InDelta(t, -1, dir.X, 0.001, "a cautious character backs away")
+
+	ai.Aggression = 1
+	assert.Equal(t, Vector3{}, ai.Steer(0.1, player, ctx), "an aggressive one holds its ground")
+
+	ai.Aggression = 0
+	enemy.Position = Vector3{X: 20}
+	assert.Equal(t, Vector3{}, ai.Steer(0.1, player, ctx), "it never walks toward enemies")
+}
+
+func TestSteer_SidestepsIncomingProjectiles(t *testing.T) {
+	ai, player, ctx := newSteerTest(1)
+	incoming := NewEnemyProjectile("proj-1", "e1", Vector3{X: -5, Z: 0.2}, Vector3{X: 1}, 10, DamageTypePhysical)
+	ctx.Projectiles = []*Projectile{incoming}
+
+	dir := ai.Steer(0.1, player, ctx)
+	assert.InDelta(t, 0, dir.X, 0.001)
+	assert.InDelta(t, -1, dir.Z, 0.001, "steps out on the side it's already on")
+
+	incoming.Position = Vector3{X: -20}
+	assert.Equal(t, Vector3{}, ai.Steer(0.1, player, ctx), "too far out to worry about yet")
+
+	incoming.Position = Vector3{X: 2}
+	assert.Equal(t, Vector3{}, ai.Steer(0.1, player, ctx), "already past")
+
+	incoming.Position = Vector3{X: -3, Z: 4}
+	assert.Equal(t, Vector3{}, ai.Steer(0.1, player, ctx), "going to miss")
+}
+
+func TestSteer_RetreatsTowardAlliesAtLowHealth(t *testing.T) {
+	ai, player, ctx := newSteerTest(1)
+	enemy := createTestEnemy("e1", "zombie", Vector3{X: 3}, createTestEnemyConfig("melee", 10))
+	ctx.Enemies[enemy.ID] = enemy
+	player.Health = player.MaxHealth * 0.2
+
+	dir := ai.Steer(0.1, player, ctx)
+	assert.InDelta(t, -1, dir.X, 0.001, "alone, it just gets away")
+
+	ally := NewPlayer("p2", "Two")
+	ally.Position = Vector3{Z: -10}
+	ctx.Allies = []*Player{ally}
+	dir = ai.Steer(0.1, player, ctx)
+	assert.Less(t, dir.X, 0.0)
+	assert.Less(t, dir.Z, 0.0, "and with an ally, toward them")
+}
+
+func TestSteer_PlayerInputTakesPrecedence(t *testing.T) {
+	ai, player, ctx := newSteerTest(0)
+	enemy := createTestEnemy("e1", "zombie", Vector3{X: 1}, createTestEnemyConfig("melee", 10))
+	ctx.Enemies[enemy.ID] = enemy
+	ai.OrderMove(Vector3{Z: 1})
+
+	player.SetVelocity(Vector3{X: 1})
+	assert.Equal(t, Vector3{}, ai.Steer(0.1, player, ctx))
+
+	player.SetVelocity(Vector3{})
+	assert.InDelta(t, -1, ai.Steer(0.1, player, ctx).X, 0.001, "input cancelled the ordered move")
+}
+
+func TestSteer_OrderedMoveExpires(t *testing.T) {
+	ai, player, ctx := newSteerTest(1)
+	ai.OrderMove(Vector3{Z: 3})
+
+	assert.Equal(t, Vector3{Z: 1}, ai.Steer(0.1, player, ctx))
+	assert.Equal(t, Vector3{}, ai.Steer(aiMoveOrderSeconds, player, ctx))
+}
+
+func TestPlayerUpdate_AIVelocityOnlyWithoutInput(t *testing.T) {
+	player := NewPlayer("p1", "One")
+	player.MoveSpeed = 1
+	player.AIVelocity = Vector3{Z: 1}
+
+	player.Update(1)
+	assert.InDelta(t, 1, player.Position.Z, 0.001)
+
+	player.SetVelocity(Vector3{X: 1})
+	player.Update(1)
+	assert.InDelta(t, 1, player.Position.X, 0.001)
+	assert.InDelta(t, 1, player.Position.Z, 0.001, "input overrides the AI")
+}
+
+func TestAlliesOf(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	p1 := NewPlayer("p1", "One")
+	p2 := NewPlayer("p2", "Two")
+	below := NewPlayer("p3", "Three")
+	below.Position.Y = -20
+	for _, p := range []*Player{p1, p2, below} {
+		world.players[p.ID] = p
+	}
+
+	assert.Equal(t, []*Player{p2}, world.alliesOf(p1))
+
+	world.Rules.PvP = PvPEverywhere
+	assert.Empty(t, world.alliesOf(p1), "everyone's a threat")
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCharacterAI_SaveRoundTrip(t *testing.T) {
+	ai := NewCharacterAI()
+	ai.Trust = 42
+	ai.Mood = MoodAnxious
+	ai.Aggression = 0.3
+	ai.Preference = AbilityFrostbolt
+	ai.consecutiveBadCalls = 2
+
+	data, err := ai.ToSaveData()
+	require.NoError(t, err)
+
+	restored := NewCharacterAI()
+	require.True(t, restored.RestoreFromSave(data))
+	assert.Equal(t, 42.0, restored.Trust)
+	assert.Equal(t, MoodAnxious, restored.Mood)
+	assert.Equal(t, 0.3, restored.Aggression)
+	assert.Equal(t, AbilityFrostbolt, restored.Preference)
+	assert.Equal(t, 2, restored.consecutiveBadCalls)
+}
+
+func TestCharacterAI_RestoreFromSave_Sanitizes(t *testing.T) {
+	ai := NewCharacterAI()
+	for _, empty := range []string{"", "{}", "null", "not json"} {
+		assert.False(t, ai.RestoreFromSave(json.RawMessage(empty)), "%q", empty)
+	}
+	assert.Equal(t, 75.0, ai.Trust, "left untouched")
+
+	require.True(t, ai.RestoreFromSave(json.RawMessage(`{"trust":250,"mood":"ecstatic","aggression":-1,"preference":"retired_spell"}`)))
+	assert.Equal(t, 100.0, ai.Trust)
+	assert.Equal(t, MoodNeutral, ai.Mood)
+	assert.Equal(t, 0.0, ai.Aggression)
+	assert.Equal(t, AbilityFireball, ai.Preference, "an ability that no longer exists keeps the default")
+}
+
+func TestCharacterAI_RollPersonality(t *testing.T) {
+	aggressions := make(map[float64]bool)
+	for i := 0; i < 20; i++ {
+		ai := NewCharacterAI()
+		ai.RollPersonality()
+		assert.GreaterOrEqual(t, ai.Aggression, 0.2)
+		assert.LessOrEqual(t, ai.Aggression, 0.8)
+		ability, ok := GetAbility(ai.Preference)
+		require.True(t, ok)
+		assert.True(t, ability.IsOffensive(), "prefers an attack, not %s", ai.Preference)
+		aggressions[ai.Aggression] = true
+	}
+	assert.Greater(t, len(aggressions), 1, "characters differ")
+}
+
+func TestPlayer_LoadCharacterAI(t *testing.T) {
+	player := NewPlayer("p1", "One")
+	player.LoadCharacterAI(json.RawMessage(`{"trust":12,"mood":"frustrated","aggression":0.9,"preference":"fireball"}`))
+	assert.Equal(t, 12.0, player.CharAI.Trust)
+	assert.Equal(t, 0.9, player.CharAI.Aggression)
+
+	fresh := NewPlayer("p2", "Two")
+	fresh.LoadCharacterAI(nil)
+	assert.Equal(t, 75.0, fresh.CharAI.Trust, "new characters start with the default trust")
+	assert.GreaterOrEqual(t, fresh.CharAI.Aggression, 0.2)
+	assert.LessOrEqual(t, fresh.CharAI.Aggression, 0.8)
+}
@@ -0,0 +1,162 @@
+package game
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Clock tells the time for gameplay timers
+type Clock interface {
+	Now() time.Time
+}
+
+// SimClock is a world's simulation time. It only moves when the world ticks,
+// by the tick's delta times the time scale, so a paused world stands still,
+// a fast-forwarded one runs ahead, and a tick that runs late doesn't change
+// anything but how much time it covers.
+type SimClock struct {
+	mu     sync.RWMutex
+	now    time.Time
+	paused bool
+	scale  float64
+}
+
+// NewSimClock returns a running clock at normal speed starting at the given time
+func NewSimClock(start time.Time) *SimClock {
+	return &SimClock{now: start, scale: 1.0}
+}
+
+// Now returns the current simulation time
+func (c *SimClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+// Advance moves the clock forward by a tick's real delta and returns how much
+// simulation time passed: nothing while paused, delta times the scale otherwise
+func (c *SimClock) Advance(delta time.Duration) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused || delta <= 0 {
+		return 0
+	}
+	simDelta := time.Duration(float64(delta) * c.scale)
+	c.now = c.now.Add(simDelta)
+	return simDelta
+}
+
+// SetPaused stops or restarts the clock
+func (c *SimClock) SetPaused(paused bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paused = paused
+}
+
+// Paused returns true if the clock is stopped
+func (c *SimClock) Paused() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.paused
+}
+
+// SetScale sets how much simulation time passes per second of real time.
+// 1 is normal speed; non-positive values are ignored.
+func (c *SimClock) SetScale(scale float64) {
+	if scale <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scale = scale
+}
+
+// Scale returns how much simulation time passes per second of real time
+func (c *SimClock) Scale() float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.scale
+}
+
+// simTime gives an entity its world's clock. Entities created outside a
+// world (lobby characters, tests) run on the wall clock until they're added
+// to one.
+type simTime struct {
+	clock Clock
+}
+
+// now returns the time on the entity's clock
+func (s simTime) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
+	}
+	return s.clock.Now()
+}
+
+// attached returns true once the entity has been given a world's clock
+func (s simTime) attached() bool {
+	return s.clock != nil
+}
+
+// simClock returns the world's clock, creating it on first use
+func (w *World) simClock() *SimClock {
+	if w.clock == nil {
+		w.clock = NewSimClock(time.Now())
+	}
+	return w.clock
+}
+
+// Now returns the world's simulation time
+func (w *World) Now() time.Time {
+	return w.simClock().Now()
+}
+
+// SetPaused pauses or resumes the world. A paused world keeps accepting
+// players but nothing moves and no timer runs down.
+func (w *World) SetPaused(paused bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.simClock().SetPaused(paused)
+	log.Printf("[WORLD] %s paused=%v", w.ID, paused)
+}
+
+// IsPaused returns true if the world's clock is stopped
+func (w *World) IsPaused() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.simClock().Paused()
+}
+
+// SetTimeScale runs the world faster or slower than real time; 1 is normal speed
+func (w *World) SetTimeScale(scale float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.simClock().SetScale(scale)
+}
+
+// attachPlayer puts a player joining the world on its clock. Caller must hold w.mu.
+func (w *World) attachPlayer(player *Player) {
+	if !player.attached() {
+		player.LastUpdate = w.Now()
+	}
+	player.clock = w.simClock()
+	player.Abilities.clock = w.simClock()
+}
+
+// attachEnemy puts an enemy entering the world on its clock. Enemies made
+// outside the world carry wall-clock stamps, which are moved onto the world
+// clock the first time they're added. Caller must hold w.mu.
+func (w *World) attachEnemy(enemy *Enemy) {
+	if !enemy.attached() {
+		now := w.Now()
+		enemy.LastUpdate = now
+		if enemy.AI != nil {
+			enemy.AI.LastAttackTime = now
+		}
+	}
+	enemy.clock = w.simClock()
+	if enemy.AI != nil {
+		enemy.AI.clock = w.simClock()
+	}
+}
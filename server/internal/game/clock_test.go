@@ -0,0 +1,66 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimClock_AdvancePauseAndScale(t *testing.T) {
+	start := time.Now()
+	clock := NewSimClock(start)
+
+	assert.Equal(t, 50*time.Millisecond, clock.Advance(50*time.Millisecond))
+	assert.Equal(t, start.Add(50*time.Millisecond), clock.Now())
+
+	clock.SetPaused(true)
+	assert.Zero(t, clock.Advance(time.Second))
+	assert.Equal(t, start.Add(50*time.Millisecond), clock.Now(), "paused clocks stand still")
+
+	clock.SetPaused(false)
+	clock.SetScale(4)
+	assert.Equal(t, 200*time.Millisecond, clock.Advance(50*time.Millisecond))
+
+	clock.SetScale(0)
+	assert.Equal(t, 4.0, clock.Scale(), "non-positive scales are ignored")
+}
+
+func TestWorld_PausedWorldFreezesTimers(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	player := NewPlayer("p1", "One")
+	world.players[player.ID] = player
+	world.attachPlayer(player)
+	player.SetVelocity(Vector3{X: 1})
+
+	enemy := createTestEnemy("e1", "zombie", Vector3{X: 20}, createTestEnemyConfig("melee", 5))
+	world.addEnemy(enemy)
+	enemy.ApplyStatusEffect(NewStatusEffect(StatusEffectStun, 1.0, 1.0, player.ID))
+	require.True(t, enemy.HasStatusEffect(StatusEffectStun))
+
+	world.SetPaused(true)
+	world.Update(5 * time.Second)
+	assert.Equal(t, Vector3{}, player.Position, "nothing moves while paused")
+	assert.True(t, enemy.HasStatusEffect(StatusEffectStun), "timers don't run down while paused")
+
+	world.SetPaused(false)
+	world.Update(1100 * time.Millisecond)
+	assert.False(t, enemy.HasStatusEffect(StatusEffectStun), "a long tick covers the whole duration at once")
+	assert.Greater(t, player.Position.X, 0.0)
+}
+
+func TestWorld_AttachMovesStampsOntoWorldClock(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.clock = NewSimClock(time.Now().Add(-time.Hour))
+
+	enemy := createTestEnemy("e1", "zombie", Vector3{}, createTestEnemyConfig("melee", 5))
+	world.addEnemy(enemy)
+	assert.Equal(t, world.Now(), enemy.LastUpdate)
+	assert.Equal(t, world.Now(), enemy.AI.LastAttackTime, "wall-clock stamps would leave it unable to attack for an hour")
+	assert.False(t, enemy.AI.attackReady())
+
+	world.clock.Advance(time.Duration(enemy.AI.AttackCooldown*float64(time.Second)) + time.Millisecond)
+	assert.True(t, enemy.AI.attackReady())
+}
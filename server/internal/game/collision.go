@@ -0,0 +1,258 @@
+package game
+
+import "math"
+
+// entityCollisionRadius is the footprint of players and enemies against terrain
+const entityCollisionRadius = 0.4
+
+// sightStep is the distance between samples along a line of sight. It is
+// smaller than the narrowest feature that blocks sight.
+const sightStep = 0.5
+
+// CollisionShapeKind identifies the footprint of a terrain feature
+type CollisionShapeKind string
+
+const (
+	CollisionCircle CollisionShapeKind = "circle"
+	CollisionBox    CollisionShapeKind = "box"
+)
+
+// CollisionShape is the area a terrain feature blocks on the ground plane,
+// before the feature's scale and rotation are applied
+type CollisionShape struct {
+	Kind        CollisionShapeKind
+	Radius      float64 // For circles
+	HalfWidth   float64 // For boxes, along the feature's local X axis
+	HalfDepth   float64 // For boxes, along the feature's local Z axis
+	BlocksSight bool    // Tall features block line of sight and stop projectiles
+}
+
+// featureCollisionShapes maps blocking feature types to their footprint.
+// Bushes, flower patches and staircases can be walked through.
+var featureCollisionShapes = map[TerrainFeatureType]CollisionShape{
+	FeatureTreeOak:     {Kind: CollisionCircle, Radius: 0.6, BlocksSight: true},
+	FeatureRockSmall:   {Kind: CollisionCircle, Radius: 0.5},
+	FeatureRockLarge:   {Kind: CollisionCircle, Radius: 1.2, BlocksSight: true},
+	FeatureRuinPillar:  {Kind: CollisionCircle, Radius: 0.6, BlocksSight: true},
+	FeatureCampfire:    {Kind: CollisionCircle, Radius: 0.5},
+	FeatureMarketStall: {Kind: CollisionBox, HalfWidth: 1.5, HalfDepth: 1.0, BlocksSight: true},
+	FeatureBrazier:     {Kind: CollisionCircle, Radius: 0.4},
+}
+
+// featureCollision returns the collision shape for a feature, if it blocks movement
+func featureCollision(feature TerrainFeature) (CollisionShape, bool) {
+	shape, ok := featureCollisionShapes[feature.Type]
+	return shape, ok
+}
+
+// featureContains returns true if a circle of the given radius at pos overlaps
+// the feature's collision shape
+func featureContains(feature TerrainFeature, shape CollisionShape, pos Vector3, radius float64) bool {
+	scale := feature.Scale
+	if scale <= 0 {
+		scale = 1.0
+	}
+
+	switch shape.Kind {
+	case CollisionCircle:
+		return Distance2D(pos, feature.Position) < shape.Radius*scale+radius
+	case CollisionBox:
+		// Cheap bounding circle check before rotating
+		reach := math.Hypot(shape.HalfWidth, shape.HalfDepth)*scale + radius
+		if Distance2D(pos, feature.Position) >= reach {
+			return false
+		}
+		// Rotate into the feature's local space (rotation is around Y, in degrees)
+		rad := -feature.Rotation * math.Pi / 180.0
+		dx := pos.X - feature.Position.X
+		dz := pos.Z - feature.Position.Z
+		localX := dx*math.Cos(rad) - dz*math.Sin(rad)
+		localZ := dx*math.Sin(rad) + dz*math.Cos(rad)
+		return math.Abs(localX) < shape.HalfWidth*scale+radius && math.Abs(localZ) < shape.HalfDepth*scale+radius
+	}
+	return false
+}
+
+// collidesWithTerrain returns true if a circle of the given radius at pos
+// overlaps a blocking feature or lies off the board. If sightOnly is true, only
+// features that block line of sight count.
+func (b *Board) collidesWithTerrain(pos Vector3, layer int, radius float64, sightOnly bool) bool {
+	tile := b.GetTile(WorldToHex(pos, layer))
+	if tile == nil {
+		return true
+	}
+
+	for _, feature := range tile.Features {
+		shape, ok := featureCollision(feature)
+		if !ok || (sightOnly && !shape.BlocksSight) {
+			continue
+		}
+		if featureContains(feature, shape, pos, radius) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBlockedByTerrain returns true if the position is inside a blocking terrain feature
+func (b *Board) isBlockedByTerrain(pos Vector3, layer int) bool {
+	return b.collidesWithTerrain(pos, layer, 0, false)
+}
+
+// canCrossEdge returns true if movement can pass directly between two tiles.
+// Neighboring tiles are connected only where a path runs across the shared edge.
+func (b *Board) canCrossEdge(from, to HexCoord) bool {
+	if from == to {
+		return true
+	}
+
+	fromTile := b.GetTile(from)
+	toTile := b.GetTile(to)
+	if fromTile == nil || toTile == nil {
+		return false
+	}
+
+	for dir := 0; dir < 6; dir++ {
+		if HexNeighbor(from, dir) == to {
+			return fromTile.EdgePaths[dir] || toTile.EdgePaths[(dir+3)%6]
+		}
+	}
+	return false // Not adjacent
+}
+
+// canOccupy returns true if an entity of the given radius can move from one
+// position to another nearby position in a single step
+func (b *Board) canOccupy(from, to Vector3, layer int, radius float64) bool {
+	if !b.canCrossEdge(WorldToHex(from, layer), WorldToHex(to, layer)) {
+		return false
+	}
+	return !b.collidesWithTerrain(to, layer, radius, false)
+}
+
+// ResolveMovement returns where an entity of the given radius ends up when it
+// tries to move from one position to another in a single tick. Blocked moves
+// slide along the obstacle on one axis when possible. An entity that starts
+// inside terrain (e.g. spawned on top of a feature) may move freely to get out.
+func (b *Board) ResolveMovement(from, to Vector3, layer int, radius float64) Vector3 {
+	if Distance2D(from, to) < 0.0001 {
+		return to
+	}
+
+	if b.collidesWithTerrain(from, layer, radius, false) {
+		if b.canCrossEdge(WorldToHex(from, layer), WorldToHex(to, layer)) {
+			return to
+		}
+		return from
+	}
+
+	if b.canOccupy(from, to, layer, radius) {
+		return to
+	}
+
+	// Slide along whichever axis is still free
+	slideX := Vector3{X: to.X, Y: to.Y, Z: from.Z}
+	if b.canOccupy(from, slideX, layer, radius) {
+		return slideX
+	}
+	slideZ := Vector3{X: from.X, Y: to.Y, Z: to.Z}
+	if b.canOccupy(from, slideZ, layer, radius) {
+		return slideZ
+	}
+
+	return Vector3{X: from.X, Y: to.Y, Z: from.Z}
+}
+
+// walkSegment samples the straight path between two positions every step units,
+// calling visit at each point after the start. It returns false as soon as the
+// path crosses a closed tile edge or leaves the board, or visit returns false.
+func (b *Board) walkSegment(from, to Vector3, layer int, step float64, visit func(point Vector3) bool) bool {
+	steps := int(math.Ceil(Distance2D(from, to) / step))
+	lastCoord := WorldToHex(from, layer)
+
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		point := Vector3{
+			X: from.X + (to.X-from.X)*t,
+			Y: from.Y,
+			Z: from.Z + (to.Z-from.Z)*t,
+		}
+
+		coord := WorldToHex(point, layer)
+		if !b.canCrossEdge(lastCoord, coord) {
+			return false
+		}
+		lastCoord = coord
+
+		if !visit(point) {
+			return false
+		}
+	}
+	return true
+}
+
+// LineOfSight returns true if nothing tall stands between two positions on the
+// same layer. Closed tile edges and the edge of the board block sight; features
+// the endpoints are standing in are ignored.
+func (b *Board) LineOfSight(from, to Vector3, layer int) bool {
+	inStart := b.collidesWithTerrain(from, layer, 0, true)
+	blocked := false
+	clear := b.walkSegment(from, to, layer, sightStep, func(point Vector3) bool {
+		if !b.collidesWithTerrain(point, layer, 0, true) {
+			inStart = false
+			return !blocked
+		}
+		if !inStart {
+			blocked = true
+		}
+		return true
+	})
+	if !clear {
+		return false
+	}
+
+	// A blocker that runs all the way to the target is the one it stands in
+	return !blocked || b.collidesWithTerrain(to, layer, 0, true)
+}
+
+// SweepProjectile returns true if a projectile moving between two positions
+// this tick hits a tall feature, a closed tile edge or the edge of the board
+func (b *Board) SweepProjectile(from, to Vector3, layer int) bool {
+	return !b.walkSegment(from, to, layer, movementStep, func(point Vector3) bool {
+		return !b.collidesWithTerrain(point, layer, 0, true)
+	})
+}
+
+// hasLineOfSight is LineOfSight for entities on whatever layer from is on,
+// treating a missing board as open ground. Caller must hold w.mu.
+func (w *World) hasLineOfSight(from, to Vector3) bool {
+	if w.Board == nil {
+		return true
+	}
+	layer := layerFromY(from.Y)
+	if layerFromY(to.Y) != layer {
+		return false
+	}
+	return w.Board.LineOfSight(from, to, layer)
+}
+
+// projectileHitTerrain returns true if a projectile that moved this tick from
+// from struck terrain on the layer it was fired on. Caller must hold w.mu.
+func (w *World) projectileHitTerrain(projectile *Projectile, from Vector3) bool {
+	if w.Board == nil {
+		return false
+	}
+	return w.Board.SweepProjectile(from, projectile.Position, projectile.Layer)
+}
+
+// resolveEntityMovement keeps an entity that moved this tick out of terrain
+// and closed tile edges. Caller must hold w.mu.
+func (w *World) resolveEntityMovement(from, to Vector3) Vector3 {
+	if w.Board == nil {
+		return to
+	}
+	layer := layerFromY(from.Y)
+	if layerFromY(to.Y) != layer {
+		return to // Layer transitions are teleports
+	}
+	return w.Board.ResolveMovement(from, to, layer, entityCollisionRadius)
+}
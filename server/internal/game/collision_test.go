@@ -0,0 +1,162 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addTestFeature places a terrain feature on the origin tile of a test board
+func addTestFeature(board *Board, featureType TerrainFeatureType, pos Vector3, rotation float64) {
+	tile := board.GetTile(HexCoord{Q: 0, R: 0})
+	tile.Features = append(tile.Features, TerrainFeature{Type: featureType, Position: pos, Rotation: rotation, Scale: 1.0})
+}
+
+// addTestDungeonTile adds the dungeon tile below the origin of a test board
+func addTestDungeonTile(board *Board) *Tile {
+	tile := NewTile(HexCoord{Q: 0, R: 0, Layer: -1}, "crypt", TileTypeDungeon, 1)
+	tile.Generated = true
+	board.Tiles[tile.Coord] = tile
+	return tile
+}
+
+func TestCollidesWithTerrain_Shapes(t *testing.T) {
+	board := newTestBoard(true)
+	addTestFeature(board, FeatureMarketStall, Vector3{}, 90)
+	addTestFeature(board, FeatureBush, Vector3{X: 5}, 0)
+
+	assert.True(t, board.collidesWithTerrain(Vector3{Z: 1.3}, 0, 0, false), "rotated stall is long along Z")
+	assert.False(t, board.collidesWithTerrain(Vector3{X: 1.3}, 0, 0, false), "rotated stall is short along X")
+	assert.True(t, board.collidesWithTerrain(Vector3{X: 1.3}, 0, 0.5, false), "entity radius pads the shape")
+	assert.False(t, board.collidesWithTerrain(Vector3{X: 5}, 0, 0, false), "bushes can be walked through")
+}
+
+func TestResolveMovement_SlidesAlongObstacle(t *testing.T) {
+	board := newTestBoard(true)
+	addTestFeature(board, FeatureRockLarge, Vector3{X: 3}, 0)
+
+	end := board.ResolveMovement(Vector3{X: 1.2}, Vector3{X: 1.6, Z: 0.5}, 0, entityCollisionRadius)
+
+	assert.InDelta(t, 1.2, end.X, 0.001, "blocked axis is dropped")
+	assert.InDelta(t, 0.5, end.Z, 0.001, "free axis still moves")
+}
+
+func TestResolveMovement_EscapesOverlap(t *testing.T) {
+	board := newTestBoard(true)
+	addTestFeature(board, FeatureRockLarge, Vector3{X: 3}, 0)
+
+	end := board.ResolveMovement(Vector3{X: 3}, Vector3{X: 3.1}, 0, entityCollisionRadius)
+	assert.InDelta(t, 3.1, end.X, 0.001)
+}
+
+func TestResolveMovement_ClosedEdge(t *testing.T) {
+	board := newTestBoard(false)
+	from := HexToWorld(HexCoord{Q: 0, R: 0})
+	to := HexToWorld(HexCoord{Q: 1, R: 0})
+	edge := board.ClampMovement(from, to, 0, true)
+
+	end := board.ResolveMovement(edge, Vector3{X: edge.X + 0.5, Z: edge.Z}, 0, entityCollisionRadius)
+	assert.Equal(t, HexCoord{Q: 0, R: 0}, WorldToHex(end, 0))
+}
+
+func TestLineOfSight(t *testing.T) {
+	board := newTestBoard(true)
+	assert.True(t, board.LineOfSight(Vector3{X: -4}, Vector3{X: 4}, 0))
+
+	addTestFeature(board, FeatureRockSmall, Vector3{}, 0)
+	assert.True(t, board.LineOfSight(Vector3{X: -4}, Vector3{X: 4}, 0), "small rocks are short enough to see over")
+
+	addTestFeature(board, FeatureTreeOak, Vector3{}, 0)
+	assert.False(t, board.LineOfSight(Vector3{X: -4}, Vector3{X: 4}, 0))
+	assert.True(t, board.LineOfSight(Vector3{X: -4, Z: 3}, Vector3{X: 4, Z: 3}, 0), "sight passes beside the tree")
+	assert.True(t, board.LineOfSight(Vector3{X: -4}, Vector3{X: 0.2}, 0), "a target standing in the tree is visible")
+}
+
+func TestLineOfSight_ClosedEdge(t *testing.T) {
+	from := HexToWorld(HexCoord{Q: 0, R: 0})
+	to := HexToWorld(HexCoord{Q: 1, R: 0})
+
+	assert.True(t, newTestBoard(true).LineOfSight(from, to, 0))
+	assert.False(t, newTestBoard(false).LineOfSight(from, to, 0))
+}
+
+func TestProjectileDestroyedByTerrain(t *testing.T) {
+	world, attacker, victim := newPvPTestWorld(GameRules{PvP: PvPEverywhere})
+	addTestFeature(world.Board, FeatureTreeOak, Vector3{X: 2.5}, 0)
+
+	fireball := &Ability{Type: "fireball", Shape: AbilityShapeProjectile, Damage: 20, Speed: 10}
+	result := world.CastPlayerAbility(attacker, fireball, Vector3{X: 1}, CastOptions{})
+	require.NotEmpty(t, result.ProjectileID)
+
+	for i := 0; i < 10; i++ {
+		world.Update(50 * time.Millisecond)
+	}
+
+	assert.NotContains(t, world.projectiles, result.ProjectileID)
+	assert.Equal(t, victim.MaxHealth, victim.Health, "the tree should absorb the shot")
+}
+
+func TestDungeonProjectileIgnoresOverworldTerrain(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	addTestFeature(world.Board, FeatureTreeOak, Vector3{X: 2.5}, 0)
+	below := addTestDungeonTile(world.Board)
+
+	player := NewPlayer("p1", "One")
+	player.Position = HexToWorld(below.Coord)
+	world.players[player.ID] = player
+
+	fireball := &Ability{Type: "fireball", Shape: AbilityShapeProjectile, Damage: 20, Speed: 10}
+	result := world.CastPlayerAbility(player, fireball, Vector3{X: 1}, CastOptions{ProjectileHeight: 0.9})
+	require.NotEmpty(t, result.ProjectileID)
+	assert.Equal(t, -1, world.projectiles[result.ProjectileID].Layer)
+
+	for i := 0; i < 10; i++ {
+		world.Update(50 * time.Millisecond)
+	}
+
+	assert.Contains(t, world.projectiles, result.ProjectileID, "the tree is on the overworld above")
+}
+
+func TestPlayerMovementBlockedByTerrain(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	addTestFeature(world.Board, FeatureRockLarge, Vector3{X: 3}, 0)
+
+	player := NewPlayer("p1", "One")
+	player.Velocity = Vector3{X: 5}
+	world.players[player.ID] = player
+
+	for i := 0; i < 20; i++ {
+		world.Update(50 * time.Millisecond)
+	}
+
+	assert.Less(t, player.Position.X, 3-1.2-entityCollisionRadius+0.001)
+}
+
+func TestRangedEnemyNeedsLineOfSight(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	addTestFeature(world.Board, FeatureTreeOak, Vector3{}, 0)
+
+	player := NewPlayer("p1", "One")
+	player.Position = Vector3{X: 4}
+	world.players[player.ID] = player
+
+	enemy := createTestEnemy("archer", "skeleton", Vector3{X: -4}, createTestEnemyConfig("ranged", 20.0))
+	world.enemies[enemy.ID] = enemy
+	ctx := &EnemyAIContext{Players: world.players, Enemies: world.enemies, DeltaSeconds: 0.016, World: world}
+
+	enemy.AI.findTarget(enemy, ctx)
+	assert.Equal(t, "", enemy.AI.TargetID, "enemies don't notice players they can't see")
+
+	enemy.AI.TargetID = player.ID
+	enemy.AI.updateState(enemy, ctx)
+	assert.Equal(t, AIStateChase, enemy.AI.State, "no clear shot, so move in")
+
+	enemy.Position = Vector3{X: -4, Z: 3}
+	enemy.AI.updateState(enemy, ctx)
+	assert.Equal(t, AIStateAttack, enemy.AI.State)
+}
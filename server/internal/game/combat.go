@@ -16,24 +16,32 @@ const (
 
 // DamageInfo contains information about damage dealt
 type DamageInfo struct {
-	Amount     float64
-	Type       DamageType
-	SourceID   string // ID of entity that dealt damage
-	TargetID   string // ID of entity that received damage
+	Amount      float64
+	Type        DamageType
+	SourceID    string // ID of entity that dealt damage
+	TargetID    string // ID of entity that received damage
+	AbilityType string // Ability that dealt the damage, if any
 }
 
 // DamageEvent represents a damage event to broadcast
 type DamageEvent struct {
-	TargetID string
-	Damage   float64
-	Type     DamageType
+	TargetID    string
+	TargetType  string // "player", "enemy" or "minion"
+	Damage      float64
+	Type        DamageType
+	SourceID    string  // Player credited with the hit, or the attacking enemy
+	SourceType  string  // "player" or "enemy"
+	SourceName  string  // Enemy type or attacker username
+	AbilityType string  // Ability that dealt the damage, for player hits
+	Overkill    float64 // Damage beyond the target's remaining health
 }
 
 // DeathEvent represents an entity death to broadcast
 type DeathEvent struct {
 	EntityID   string
-	EntityType string // "player" or "enemy"
+	EntityType string // "player", "enemy" or "minion"
 	KillerID   string
+	KillerType string // "player" or "enemy"
 }
 
 // AbilityCastEvent represents an ability cast by a minion to broadcast
@@ -77,23 +85,6 @@ func Distance2D(a, b Vector3) float64 {
 	return math.Sqrt(dx*dx + dz*dz)
 }
 
-// CheckProjectileCollision checks if a projectile collides with any entities
-// Uses 2D distance (X,Z plane) to allow projectiles at different heights to hit
-func CheckProjectileCollision(projectile *Projectile, enemies map[string]*Enemy, radius float64) *Enemy {
-	for _, enemy := range enemies {
-		if enemy.IsDead() {
-			continue
-		}
-
-		// Use 2D distance so projectiles at different Y heights can still hit
-		distance := Distance2D(projectile.Position, enemy.Position)
-		if distance <= radius {
-			return enemy
-		}
-	}
-	return nil
-}
-
 // ApplyDamage applies damage to an entity and returns true if it died
 func ApplyDamage(target Damageable, damage DamageInfo) bool {
 	finalDamage := CalculateDamage(damage.Amount, damage.Type)
@@ -121,21 +112,26 @@ func CheckLineCollision(origin, direction Vector3, maxRange, lineWidth float64,
 	if enemy.IsDead() {
 		return false
 	}
+	return lineContains(origin, direction, maxRange, lineWidth, enemy.Position)
+}
 
-	// Vector from origin to enemy
-	toEnemy := Vector3{
-		X: enemy.Position.X - origin.X,
+// lineContains returns true if a position is within range of the origin and
+// close to the line along the direction
+func lineContains(origin, direction Vector3, maxRange, lineWidth float64, position Vector3) bool {
+	// Vector from origin to target
+	toTarget := Vector3{
+		X: position.X - origin.X,
 		Y: 0,
-		Z: enemy.Position.Z - origin.Z,
+		Z: position.Z - origin.Z,
 	}
 
 	// Normalize direction (2D)
 	dir := Normalize2D(direction)
 
 	// Distance along the direction
-	distanceAlong := Dot2D(toEnemy, dir)
+	distanceAlong := Dot2D(toTarget, dir)
 
-	// Check if enemy is within range
+	// Check if target is within range
 	if distanceAlong < 0 || distanceAlong > maxRange {
 		return false
 	}
@@ -147,7 +143,7 @@ func CheckLineCollision(origin, direction Vector3, maxRange, lineWidth float64,
 		Z: origin.Z + dir.Z*distanceAlong,
 	}
 
-	perpDistance := Distance2D(enemy.Position, projectedPoint)
+	perpDistance := Distance2D(position, projectedPoint)
 
 	// Check if within line width
 	return perpDistance <= lineWidth
@@ -159,31 +155,36 @@ func CheckConeCollision(origin, direction Vector3, maxRange, coneAngleDegrees fl
 	if enemy.IsDead() {
 		return false
 	}
+	return coneContains(origin, direction, maxRange, coneAngleDegrees, enemy.Position)
+}
 
-	// Vector from origin to enemy
-	toEnemy := Vector3{
-		X: enemy.Position.X - origin.X,
+// coneContains returns true if a position is within range of the origin and
+// inside the cone around the direction
+func coneContains(origin, direction Vector3, maxRange, coneAngleDegrees float64, position Vector3) bool {
+	// Vector from origin to target
+	toTarget := Vector3{
+		X: position.X - origin.X,
 		Y: 0,
-		Z: enemy.Position.Z - origin.Z,
+		Z: position.Z - origin.Z,
 	}
 
-	// Check if enemy is within range
-	distance := math.Sqrt(toEnemy.X*toEnemy.X + toEnemy.Z*toEnemy.Z)
+	// Check if target is within range
+	distance := math.Sqrt(toTarget.X*toTarget.X + toTarget.Z*toTarget.Z)
 	if distance > maxRange {
 		return false
 	}
 
 	// Normalize both vectors
 	dir := Normalize2D(direction)
-	toEnemyNorm := Normalize2D(toEnemy)
+	toTargetNorm := Normalize2D(toTarget)
 
-	// Calculate angle between direction and toEnemy
-	dotProduct := Dot2D(dir, toEnemyNorm)
+	// Calculate angle between direction and toTarget
+	dotProduct := Dot2D(dir, toTargetNorm)
 
 	// Convert cone angle to radians and calculate half angle
 	halfAngleRad := (coneAngleDegrees / 2.0) * (math.Pi / 180.0)
 	cosHalfAngle := math.Cos(halfAngleRad)
 
-	// Check if enemy is within cone
+	// Check if target is within cone
 	return dotProduct >= cosHalfAngle
 }
@@ -0,0 +1,97 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CombatLog appends combat events to a file as JSON lines for offline
+// analysis. A nil CombatLog discards everything, so callers needn't check.
+type CombatLog struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// OpenCombatLog opens (or creates) a combat log file for appending
+func OpenCombatLog(path string) (*CombatLog, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &CombatLog{file: file, writer: bufio.NewWriter(file)}, nil
+}
+
+// Write appends one event. The time is added automatically.
+func (l *CombatLog) Write(worldID, event string, fields map[string]interface{}) {
+	if l == nil {
+		return
+	}
+
+	entry := make(map[string]interface{}, len(fields)+3)
+	for k, v := range fields {
+		entry[k] = v
+	}
+	entry["time"] = time.Now().UnixMilli()
+	entry["world"] = worldID
+	entry["event"] = event
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("[COMBATLOG] Failed to encode %s event: %v", event, err)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.writer == nil {
+		return // Closed
+	}
+	l.writer.Write(line)
+	l.writer.WriteByte('\n')
+}
+
+// Flush writes buffered events to the file
+func (l *CombatLog) Flush() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.writer == nil {
+		return
+	}
+	if err := l.writer.Flush(); err != nil {
+		log.Printf("[COMBATLOG] Flush failed: %v", err)
+	}
+}
+
+// Close flushes and closes the file. Later writes are discarded.
+func (l *CombatLog) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.writer == nil {
+		return nil
+	}
+	err := l.writer.Flush()
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.writer = nil
+	return err
+}
@@ -0,0 +1,295 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+)
+
+// CombatStats accumulates one player's combat numbers over a span of time
+type CombatStats struct {
+	Start        time.Time
+	LastActivity time.Time
+
+	DamageByAbility     map[string]float64     // Damage done, by ability type
+	DamageByType        map[DamageType]float64 // Damage done, by damage type
+	DamageTakenBySource map[string]float64     // Damage taken, by enemy type or attacker name
+	CastsByAbility      map[string]int
+	Kills               int
+	Deaths              int
+	Healing             float64
+	Overkill            float64 // Damage dealt beyond what was needed to kill
+}
+
+// newCombatStats starts an empty span at now
+func newCombatStats(now time.Time) *CombatStats {
+	return &CombatStats{
+		Start:               now,
+		LastActivity:        now,
+		DamageByAbility:     make(map[string]float64),
+		DamageByType:        make(map[DamageType]float64),
+		DamageTakenBySource: make(map[string]float64),
+		CastsByAbility:      make(map[string]int),
+	}
+}
+
+// DamageDone returns the total damage the player dealt
+func (s *CombatStats) DamageDone() float64 {
+	total := 0.0
+	for _, amount := range s.DamageByAbility {
+		total += amount
+	}
+	return total
+}
+
+// DamageTaken returns the total damage the player received
+func (s *CombatStats) DamageTaken() float64 {
+	total := 0.0
+	for _, amount := range s.DamageTakenBySource {
+		total += amount
+	}
+	return total
+}
+
+// Serialize converts the stats to a map for JSON. DPS figures are averaged
+// over seconds, which is floored at one to keep single hits sensible.
+func (s *CombatStats) Serialize(seconds float64) map[string]interface{} {
+	seconds = math.Max(seconds, 1.0)
+	damageDone := s.DamageDone()
+
+	byAbility := make(map[string]interface{}, len(s.DamageByAbility))
+	for ability, amount := range s.DamageByAbility {
+		share := 0.0
+		if damageDone > 0 {
+			share = amount / damageDone
+		}
+		byAbility[ability] = map[string]interface{}{
+			"damage": amount,
+			"dps":    amount / seconds,
+			"share":  share,
+			"casts":  s.CastsByAbility[ability],
+		}
+	}
+
+	byType := make(map[string]interface{}, len(s.DamageByType))
+	for damageType, amount := range s.DamageByType {
+		byType[string(damageType)] = map[string]interface{}{
+			"damage": amount,
+			"dps":    amount / seconds,
+		}
+	}
+
+	taken := make(map[string]float64, len(s.DamageTakenBySource))
+	for source, amount := range s.DamageTakenBySource {
+		taken[source] = amount
+	}
+
+	return map[string]interface{}{
+		"duration":            seconds,
+		"damageDone":          damageDone,
+		"dps":                 damageDone / seconds,
+		"damageByAbility":     byAbility,
+		"damageByType":        byType,
+		"damageTaken":         s.DamageTaken(),
+		"damageTakenBySource": taken,
+		"kills":               s.Kills,
+		"deaths":              s.Deaths,
+		"healing":             s.Healing,
+		"hps":                 s.Healing / seconds,
+		"overkill":            s.Overkill,
+	}
+}
+
+// playerCombatRecord holds a player's stats for their whole session in the
+// world and for their current (or most recent) encounter
+type playerCombatRecord struct {
+	Session   *CombatStats
+	Encounter *CombatStats
+}
+
+// CombatTracker keeps combat statistics for every player in a world. An
+// encounter ends once a player goes a while without dealing, taking or
+// healing damage; the next activity starts a fresh one.
+type CombatTracker struct {
+	players          map[string]*playerCombatRecord
+	encounterTimeout time.Duration
+}
+
+// NewCombatTracker creates an empty tracker using the configured encounter timeout
+func NewCombatTracker() *CombatTracker {
+	timeout := config.Combat.Stats.EncounterTimeoutSeconds
+	if timeout <= 0 {
+		timeout = 5.0
+	}
+	return &CombatTracker{
+		players:          make(map[string]*playerCombatRecord),
+		encounterTimeout: time.Duration(timeout * float64(time.Second)),
+	}
+}
+
+// touch returns a player's record, starting a new encounter if the last one
+// has timed out, and marks both spans active at now
+func (t *CombatTracker) touch(playerID string, now time.Time) *playerCombatRecord {
+	record, ok := t.players[playerID]
+	if !ok {
+		record = &playerCombatRecord{Session: newCombatStats(now), Encounter: newCombatStats(now)}
+		t.players[playerID] = record
+	} else if now.Sub(record.Encounter.LastActivity) > t.encounterTimeout {
+		record.Encounter = newCombatStats(now)
+	}
+	record.Session.LastActivity = now
+	record.Encounter.LastActivity = now
+	return record
+}
+
+// RecordDamage credits a hit to the player who dealt it and the player who took it
+func (t *CombatTracker) RecordDamage(event DamageEvent, now time.Time) {
+	if event.SourceType == "player" && event.SourceID != "" {
+		record := t.touch(event.SourceID, now)
+		for _, stats := range []*CombatStats{record.Session, record.Encounter} {
+			stats.DamageByAbility[event.AbilityType] += event.Damage
+			stats.DamageByType[event.Type] += event.Damage
+			stats.Overkill += event.Overkill
+		}
+	}
+	if event.TargetType == "player" {
+		record := t.touch(event.TargetID, now)
+		record.Session.DamageTakenBySource[event.SourceName] += event.Damage
+		record.Encounter.DamageTakenBySource[event.SourceName] += event.Damage
+	}
+}
+
+// RecordDeath counts a kill for the killing player and a death for a player who died
+func (t *CombatTracker) RecordDeath(event DeathEvent, now time.Time) {
+	if event.KillerType == "player" && event.KillerID != "" && event.KillerID != event.EntityID {
+		record := t.touch(event.KillerID, now)
+		record.Session.Kills++
+		record.Encounter.Kills++
+	}
+	if event.EntityType == "player" {
+		record := t.touch(event.EntityID, now)
+		record.Session.Deaths++
+		record.Encounter.Deaths++
+	}
+}
+
+// RecordHealing credits healing done by a player
+func (t *CombatTracker) RecordHealing(healerID string, amount float64, now time.Time) {
+	record := t.touch(healerID, now)
+	record.Session.Healing += amount
+	record.Encounter.Healing += amount
+}
+
+// RecordCast counts an ability cast by a player or one of their minions
+func (t *CombatTracker) RecordCast(playerID, abilityType string, now time.Time) {
+	record := t.touch(playerID, now)
+	record.Session.CastsByAbility[abilityType]++
+	record.Encounter.CastsByAbility[abilityType]++
+}
+
+// Remove forgets a player's stats when they leave the world
+func (t *CombatTracker) Remove(playerID string) {
+	delete(t.players, playerID)
+}
+
+// Serialize returns a player's session and encounter stats, or nil if they
+// haven't fought yet
+func (t *CombatTracker) Serialize(playerID string, now time.Time) map[string]interface{} {
+	record, ok := t.players[playerID]
+	if !ok {
+		return nil
+	}
+
+	encounterActive := now.Sub(record.Encounter.LastActivity) <= t.encounterTimeout
+	encounter := record.Encounter.Serialize(record.Encounter.LastActivity.Sub(record.Encounter.Start).Seconds())
+	encounter["active"] = encounterActive
+
+	return map[string]interface{}{
+		"session":   record.Session.Serialize(now.Sub(record.Session.Start).Seconds()),
+		"encounter": encounter,
+	}
+}
+
+// combatTracker returns the world's combat tracker, creating it on first use.
+// Caller must hold w.mu.
+func (w *World) combatTracker() *CombatTracker {
+	if w.combatStats == nil {
+		w.combatStats = NewCombatTracker()
+	}
+	return w.combatStats
+}
+
+// emitDamage records a damage event for broadcast, the damage meter and the
+// combat log. Caller must hold w.mu.
+func (w *World) emitDamage(event DamageEvent) {
+	now := w.Now()
+	w.damageEvents = append(w.damageEvents, event)
+	w.combatTracker().RecordDamage(event, now)
+	w.CombatLog.Write(w.ID, "damage", map[string]interface{}{
+		"sourceID":    event.SourceID,
+		"sourceType":  event.SourceType,
+		"sourceName":  event.SourceName,
+		"targetID":    event.TargetID,
+		"targetType":  event.TargetType,
+		"abilityType": event.AbilityType,
+		"damageType":  string(event.Type),
+		"amount":      event.Damage,
+		"overkill":    event.Overkill,
+	})
+}
+
+// emitDeath records a death event for broadcast, the damage meter and the
+// combat log. Caller must hold w.mu.
+func (w *World) emitDeath(event DeathEvent) {
+	w.deathEvents = append(w.deathEvents, event)
+	w.combatTracker().RecordDeath(event, w.Now())
+	w.CombatLog.Write(w.ID, "death", map[string]interface{}{
+		"entityID":   event.EntityID,
+		"entityType": event.EntityType,
+		"killerID":   event.KillerID,
+		"killerType": event.KillerType,
+	})
+}
+
+// recordHealing credits healing to the damage meter and combat log.
+// Caller must hold w.mu.
+func (w *World) recordHealing(healerID, targetID string, amount float64) {
+	w.combatTracker().RecordHealing(healerID, amount, w.Now())
+	w.CombatLog.Write(w.ID, "heal", map[string]interface{}{
+		"sourceID": healerID,
+		"targetID": targetID,
+		"amount":   amount,
+	})
+}
+
+// recordCast credits an ability cast to its owner in the damage meter and
+// combat log. Caller must hold w.mu.
+func (w *World) recordCast(casterID, ownerID, abilityType string) {
+	w.combatTracker().RecordCast(ownerID, abilityType, w.Now())
+	w.CombatLog.Write(w.ID, "cast", map[string]interface{}{
+		"casterID":    casterID,
+		"ownerID":     ownerID,
+		"abilityType": abilityType,
+	})
+}
+
+// GetCombatStats returns session and encounter stats for every player in the
+// world who has been in combat, keyed by player ID
+func (w *World) GetCombatStats() map[string]interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.Now()
+	tracker := w.combatTracker()
+	result := make(map[string]interface{}, len(w.players))
+	for id, player := range w.players {
+		stats := tracker.Serialize(id, now)
+		if stats == nil {
+			continue
+		}
+		stats["username"] = player.Username
+		result[id] = stats
+	}
+	return result
+}
@@ -0,0 +1,124 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCombatStats_DamageDoneAndKills(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	player := NewPlayer("p1", "One")
+	world.players[player.ID] = player
+	cfg := createTestEnemyConfig("melee", 0)
+	cfg.Health = 50
+	world.addEnemy(createTestEnemy("e1", "zombie", Vector3{X: 3}, cfg))
+
+	bolt := &Ability{Type: "bolt", Shape: AbilityShapeLine, Damage: 30, DamageType: DamageTypeCold, Range: 10, Radius: 1}
+	world.CastPlayerAbility(player, bolt, Vector3{X: 1}, CastOptions{})
+	world.CastPlayerAbility(player, bolt, Vector3{X: 1}, CastOptions{})
+
+	record := world.combatTracker().players[player.ID]
+	require.NotNil(t, record)
+	session := record.Session
+	assert.Equal(t, 60.0, session.DamageByAbility["bolt"])
+	assert.Equal(t, 60.0, session.DamageByType[DamageTypeCold])
+	assert.Equal(t, 2, session.CastsByAbility["bolt"])
+	assert.Equal(t, 1, session.Kills)
+	assert.Equal(t, 10.0, session.Overkill)
+	assert.Equal(t, 60.0, record.Encounter.DamageDone())
+}
+
+func TestCombatStats_DamageTakenAndDeaths(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	player := NewPlayer("p1", "One")
+	world.players[player.ID] = player
+	world.addEnemy(createTestEnemy("e1", "skeleton", Vector3{X: 3}, createTestEnemyConfig("melee", 0)))
+
+	world.damagePlayer(player, DamageInfo{Amount: 40, Type: DamageTypePhysical, SourceID: "e1"}, "enemy", "Skeleton")
+	world.damagePlayer(player, DamageInfo{Amount: 100, Type: DamageTypePhysical, SourceID: "e1"}, "enemy", "Skeleton")
+
+	session := world.combatTracker().players[player.ID].Session
+	assert.Equal(t, 140.0, session.DamageTakenBySource["skeleton"], "damage taken is keyed by enemy type")
+	assert.Equal(t, 1, session.Deaths)
+	assert.Equal(t, 0, session.Kills, "enemies aren't credited in the meter")
+}
+
+func TestCombatStats_HealingAndPvPKills(t *testing.T) {
+	world, attacker, victim := newPvPTestWorld(GameRules{PvP: PvPEverywhere})
+	victim.Health = victim.MaxHealth - 25
+
+	world.healPlayer(attacker, victim, 40)
+	world.hitPlayer(attacker, victim, victim.MaxHealth, DamageTypeFire, "fireball")
+
+	session := world.combatTracker().players[attacker.ID].Session
+	assert.Equal(t, 25.0, session.Healing, "only health actually restored counts")
+	assert.Equal(t, 1, session.Kills)
+	assert.Equal(t, victim.MaxHealth, session.DamageByAbility["fireball"])
+	assert.Equal(t, victim.MaxHealth, world.combatTracker().players[victim.ID].Session.DamageTakenBySource["Attacker"])
+}
+
+func TestCombatTracker_EncountersTimeOut(t *testing.T) {
+	tracker := NewCombatTracker()
+	tracker.encounterTimeout = 5 * time.Second
+	start := time.Now()
+	hit := DamageEvent{TargetID: "e1", TargetType: "enemy", Damage: 10, Type: DamageTypeFire, SourceID: "p1", SourceType: "player", AbilityType: "fireball"}
+
+	tracker.RecordDamage(hit, start)
+	tracker.RecordDamage(hit, start.Add(4*time.Second))
+	assert.Equal(t, 20.0, tracker.players["p1"].Encounter.DamageDone())
+
+	tracker.RecordDamage(hit, start.Add(10*time.Second))
+	assert.Equal(t, 10.0, tracker.players["p1"].Encounter.DamageDone(), "a quiet gap starts a new encounter")
+	assert.Equal(t, 30.0, tracker.players["p1"].Session.DamageDone())
+
+	stats := tracker.Serialize("p1", start.Add(20*time.Second))
+	session := stats["session"].(map[string]interface{})
+	assert.InDelta(t, 1.5, session["dps"], 0.001, "session DPS averages over the whole session")
+	encounter := stats["encounter"].(map[string]interface{})
+	assert.Equal(t, false, encounter["active"])
+	assert.Equal(t, 10.0, encounter["dps"], "a single hit encounter counts as one second")
+
+	assert.Nil(t, tracker.Serialize("nobody", start))
+}
+
+func TestCombatLog_WritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "combat.jsonl")
+	combatLog, err := OpenCombatLog(path)
+	require.NoError(t, err)
+
+	world := newTestWorldWithoutEnemies()
+	world.CombatLog = combatLog
+	player := NewPlayer("p1", "One")
+	world.players[player.ID] = player
+	world.addEnemy(createTestEnemy("e1", "zombie", Vector3{X: 3}, createTestEnemyConfig("melee", 0)))
+
+	bolt := &Ability{Type: "bolt", Shape: AbilityShapeLine, Damage: 10, DamageType: DamageTypeLightning, Range: 10, Radius: 1}
+	world.CastPlayerAbility(player, bolt, Vector3{X: 1}, CastOptions{})
+	require.NoError(t, combatLog.Close())
+	combatLog.Write(world.ID, "damage", nil) // Discarded after close
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var events []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		events = append(events, entry)
+	}
+	require.Len(t, events, 2)
+	assert.Equal(t, "cast", events[0]["event"])
+	assert.Equal(t, "damage", events[1]["event"])
+	assert.Equal(t, "bolt", events[1]["abilityType"])
+	assert.Equal(t, "test-world", events[1]["world"])
+}
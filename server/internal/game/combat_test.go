@@ -1,7 +1,9 @@
 package game
 
 import (
+	"fmt"
 	"testing"
+	"time"
 )
 
 func TestCalculateDamage(t *testing.T) {
@@ -53,54 +55,75 @@ func TestDistance2DIgnoresHeight(t *testing.T) {
 	}
 }
 
-func TestCheckProjectileCollision(t *testing.T) {
+func TestProjectileEnemyHits(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.addEnemy(&Enemy{ID: "e1", Position: Vector3{X: 5, Y: 0, Z: 5.3}})
+	world.addEnemy(&Enemy{ID: "e2", Position: Vector3{X: 10, Y: 0, Z: 10}})
+
 	projectile := &Projectile{
 		Position: Vector3{X: 5, Y: 0, Z: 5},
-	}
-
-	enemies := map[string]*Enemy{
-		"e1": {
-			ID:       "e1",
-			Position: Vector3{X: 5, Y: 0, Z: 5.3},
-			Dead:     false,
-		},
-		"e2": {
-			ID:       "e2",
-			Position: Vector3{X: 10, Y: 0, Z: 10},
-			Dead:     false,
-		},
+		Radius:   0.5,
 	}
 
 	// Should hit e1 (within 0.5 radius)
-	hit := CheckProjectileCollision(projectile, enemies, 0.5)
-	if hit == nil || hit.ID != "e1" {
-		t.Errorf("Expected to hit enemy e1, got %v", hit)
+	hits := world.projectileEnemyHits(projectile)
+	if len(hits) != 1 || hits[0].ID != "e1" {
+		t.Errorf("Expected to hit enemy e1, got %v", hits)
 	}
 
 	// Should not hit with very small radius
-	hit = CheckProjectileCollision(projectile, enemies, 0.1)
-	if hit != nil {
-		t.Errorf("Expected no hit with small radius, got %v", hit)
+	projectile.Radius = 0.1
+	if hits := world.projectileEnemyHits(projectile); len(hits) != 0 {
+		t.Errorf("Expected no hit with small radius, got %v", hits)
 	}
 }
 
-func TestCheckProjectileCollisionWithDeadEnemy(t *testing.T) {
+func TestProjectileEnemyHitsSkipsDeadAndPierced(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.addEnemy(&Enemy{ID: "dead", Position: Vector3{X: 5, Y: 0, Z: 5}, Dead: true})
+	world.addEnemy(&Enemy{ID: "pierced", Position: Vector3{X: 5, Y: 0, Z: 5}})
+
 	projectile := &Projectile{
-		Position: Vector3{X: 5, Y: 0, Z: 5},
+		Position:   Vector3{X: 5, Y: 0, Z: 5},
+		Radius:     1.0,
+		IsPiercing: true,
+		MaxPierces: -1,
 	}
+	projectile.MarkEnemyHit("pierced")
 
-	enemies := map[string]*Enemy{
-		"e1": {
-			ID:       "e1",
-			Position: Vector3{X: 5, Y: 0, Z: 5},
-			Dead:     true, // Dead enemy
-		},
+	// Should not hit dead enemies or ones already pierced
+	if hits := world.projectileEnemyHits(projectile); len(hits) != 0 {
+		t.Errorf("Expected no hits, got %v", hits)
 	}
+}
+
+func TestPiercingProjectileHitsEnemiesInALine(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	player := NewPlayer("p1", "One")
+	world.players[player.ID] = player
 
-	// Should not hit dead enemies
-	hit := CheckProjectileCollision(projectile, enemies, 1.0)
-	if hit != nil {
-		t.Errorf("Expected no hit on dead enemy, got %v", hit)
+	cfg := createTestEnemyConfig("idle", 0)
+	for i, x := range []float64{2, 2.2, 2.4, 8} {
+		world.addEnemy(createTestEnemy(fmt.Sprintf("e%d", i), "zombie", Vector3{X: x}, cfg))
+	}
+
+	bolt := &Ability{Type: "bolt", Shape: AbilityShapeProjectile, Damage: 10, Speed: 10, Lifetime: 5, Radius: 1}
+	result := world.CastPlayerAbility(player, bolt, Vector3{X: 1}, CastOptions{Piercing: true})
+	for i := 0; i < 20; i++ {
+		world.Update(50 * time.Millisecond)
+	}
+
+	for _, id := range []string{"e0", "e1", "e2"} {
+		if enemy := world.enemies[id]; enemy.Health != enemy.MaxHealth-10 {
+			t.Errorf("Expected %s to be hit once, health %f", id, enemy.Health)
+		}
+	}
+	if enemy := world.enemies["e3"]; enemy.Health != enemy.MaxHealth {
+		t.Errorf("Expected the projectile to stop after 3 pierces, e3 health %f", enemy.Health)
+	}
+	if _, exists := world.projectiles[result.ProjectileID]; exists {
+		t.Error("Expected the projectile to be used up")
 	}
 }
 
@@ -0,0 +1,203 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+)
+
+// ConsumableEffect is what happens when a consumable is used
+type ConsumableEffect string
+
+const (
+	EffectRestoreHealth ConsumableEffect = "restore_health"
+	EffectRestoreMana   ConsumableEffect = "restore_mana"
+	EffectTownPortal    ConsumableEffect = "town_portal"
+	EffectIdentify      ConsumableEffect = "identify"
+)
+
+// defaultPotionCooldown is used when items.json doesn't set one
+const defaultPotionCooldown = 1.5
+
+// isPotion returns true for effects that share the potion cooldown
+func (e ConsumableEffect) isPotion() bool {
+	return e == EffectRestoreHealth || e == EffectRestoreMana
+}
+
+// ItemUseResult describes the outcome of using a consumable
+type ItemUseResult struct {
+	Effect          ConsumableEffect
+	Restored        float64  // Health or mana actually restored
+	Position        *Vector3 // Set when the player was moved (town portal)
+	IdentifiedItem  *Item    // Set when an item was identified
+	PotionCooldown  float64  // Seconds until another potion can be used
+	RemainingInSlot int
+}
+
+// NewConsumable creates a stack of a consumable type from items.json
+func NewConsumable(id, consumableType string, quantity int) (*Item, error) {
+	cfg, ok := config.GetConsumableConfig(consumableType)
+	if !ok {
+		return nil, fmt.Errorf("unknown consumable type: %s", consumableType)
+	}
+	if quantity < 1 {
+		quantity = 1
+	}
+
+	return &Item{
+		ID:             id,
+		Name:           cfg.Name,
+		Type:           ItemTypeConsumable,
+		Rarity:         ItemRarityNormal,
+		Level:          1,
+		Affixes:        make([]ItemAffix, 0),
+		Description:    cfg.Description,
+		ConsumableType: consumableType,
+		Quantity:       min(quantity, consumableMaxStack(consumableType)),
+	}, nil
+}
+
+// consumableMaxStack returns how many of a consumable fit in one bag slot
+func consumableMaxStack(consumableType string) int {
+	if cfg, ok := config.GetConsumableConfig(consumableType); ok && cfg.MaxStack > 0 {
+		return cfg.MaxStack
+	}
+	return 1
+}
+
+// potionCooldown returns the shared potion cooldown in seconds
+func potionCooldown() float64 {
+	if config.Items.PotionCooldownSeconds > 0 {
+		return config.Items.PotionCooldownSeconds
+	}
+	return defaultPotionCooldown
+}
+
+// rollConsumableType picks a consumable type weighted by dropWeight.
+// Returns false if none are configured.
+func rollConsumableType() (string, bool) {
+	types := make([]string, 0, len(config.Items.Consumables))
+	total := 0.0
+	for consumableType, cfg := range config.Items.Consumables {
+		if cfg.DropWeight > 0 {
+			types = append(types, consumableType)
+			total += cfg.DropWeight
+		}
+	}
+	if total <= 0 {
+		return "", false
+	}
+	sort.Strings(types) // Map order is random; keep rolls reproducible
+
+	roll := rand.Float64() * total
+	for _, consumableType := range types {
+		roll -= config.Items.Consumables[consumableType].DropWeight
+		if roll < 0 {
+			return consumableType, true
+		}
+	}
+	return types[len(types)-1], true
+}
+
+// UseItem uses the consumable in a bag slot. targetSlot is the bag slot of the
+// item to identify, and is ignored by other consumables.
+func (w *World) UseItem(playerID string, bagSlot, targetSlot int) (ItemUseResult, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, exists := w.players[playerID]
+	if !exists {
+		return ItemUseResult{}, errors.New("player not found")
+	}
+	if player.IsDead() {
+		return ItemUseResult{}, errors.New("player is dead")
+	}
+
+	item := player.Inventory.GetBagItem(bagSlot)
+	if item == nil {
+		return ItemUseResult{}, errors.New("no item in bag slot")
+	}
+	if item.Type != ItemTypeConsumable {
+		return ItemUseResult{}, errors.New("item is not usable")
+	}
+	cfg, ok := config.GetConsumableConfig(item.ConsumableType)
+	if !ok {
+		return ItemUseResult{}, fmt.Errorf("unknown consumable type: %s", item.ConsumableType)
+	}
+
+	now := w.Now()
+	effect := ConsumableEffect(cfg.Effect)
+	result := ItemUseResult{Effect: effect}
+
+	if effect.isPotion() {
+		if remaining := player.PotionCooldownUntil.Sub(now); remaining > 0 {
+			return ItemUseResult{PotionCooldown: remaining.Seconds()}, errors.New("potion on cooldown")
+		}
+	}
+
+	switch effect {
+	case EffectRestoreHealth:
+		if player.Health >= player.MaxHealth {
+			return ItemUseResult{}, errors.New("already at full health")
+		}
+		before := player.Health
+		player.Health = math.Min(player.MaxHealth, player.Health+cfg.Amount)
+		result.Restored = player.Health - before
+		w.recordHealing(player.ID, player.ID, result.Restored)
+
+	case EffectRestoreMana:
+		if player.Mana >= player.MaxMana {
+			return ItemUseResult{}, errors.New("already at full mana")
+		}
+		before := player.Mana
+		player.Mana = math.Min(player.MaxMana, player.Mana+cfg.Amount)
+		result.Restored = player.Mana - before
+
+	case EffectTownPortal:
+		if w.Board == nil {
+			return ItemUseResult{}, errors.New("no town to return to")
+		}
+		pos := w.Board.GetSpawnPoint()
+		player.Position = pos
+		player.CurrentTile = WorldToHex(pos, 0)
+		player.Velocity = Vector3{}
+		player.Movement = nil
+		result.Position = &pos
+
+	case EffectIdentify:
+		target := player.Inventory.GetBagItem(targetSlot)
+		if target == nil {
+			return ItemUseResult{}, errors.New("no item to identify")
+		}
+		if !target.Unidentified {
+			return ItemUseResult{}, errors.New("item is already identified")
+		}
+		target.Unidentified = false
+		result.IdentifiedItem = target
+
+	default:
+		return ItemUseResult{}, fmt.Errorf("unknown consumable effect: %s", cfg.Effect)
+	}
+
+	if effect.isPotion() {
+		player.PotionCooldownUntil = now.Add(time.Duration(potionCooldown() * float64(time.Second)))
+	}
+	if remaining := player.PotionCooldownUntil.Sub(now); remaining > 0 {
+		result.PotionCooldown = remaining.Seconds()
+	}
+
+	item.Quantity--
+	if item.Quantity <= 0 {
+		player.Inventory.Bags[bagSlot] = nil
+	}
+	result.RemainingInSlot = max(item.Quantity, 0)
+
+	log.Printf("[ITEM] Player %s used %s", player.Username, cfg.Name)
+	return result, nil
+}
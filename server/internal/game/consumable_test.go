@@ -0,0 +1,206 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withTestConsumables installs a known set of consumables for the duration of a test
+func withTestConsumables(t *testing.T) {
+	t.Helper()
+	previous := config.Items
+	config.Items = config.ItemsData{
+		PotionCooldownSeconds: 2,
+		Consumables: map[string]config.ConsumableConfig{
+			"health_potion":      {Name: "Health Potion", Effect: "restore_health", Amount: 50, MaxStack: 5, DropWeight: 1},
+			"mana_potion":        {Name: "Mana Potion", Effect: "restore_mana", Amount: 30, MaxStack: 5, DropWeight: 1},
+			"town_portal_scroll": {Name: "Scroll of Town Portal", Effect: "town_portal", MaxStack: 5},
+			"identify_scroll":    {Name: "Scroll of Identify", Effect: "identify", MaxStack: 5},
+		},
+	}
+	t.Cleanup(func() { config.Items = previous })
+}
+
+// newConsumableTestWorld returns a world with one wounded player standing away from town
+func newConsumableTestWorld(t *testing.T) (*World, *Player) {
+	t.Helper()
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	player := NewPlayer("p1", "One")
+	player.Position = HexToWorld(HexCoord{Q: 1, R: 0})
+	player.Health = 10
+	player.Mana = 0
+	world.players[player.ID] = player
+	return world, player
+}
+
+func addTestConsumable(t *testing.T, player *Player, consumableType string, quantity int) int {
+	t.Helper()
+	item, err := NewConsumable("item-"+consumableType, consumableType, quantity)
+	require.NoError(t, err)
+	slot, err := player.Inventory.AddToBag(item)
+	require.NoError(t, err)
+	return slot
+}
+
+func TestConsumables_StackInBag(t *testing.T) {
+	withTestConsumables(t)
+	inv := NewInventory()
+
+	first, _ := NewConsumable("a", "health_potion", 3)
+	second, _ := NewConsumable("b", "health_potion", 4)
+	slotA, err := inv.AddToBag(first)
+	require.NoError(t, err)
+	slotB, err := inv.AddToBag(second)
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, inv.Bags[slotA].Quantity, "first stack fills to the max")
+	assert.NotEqual(t, slotA, slotB)
+	assert.Equal(t, 2, inv.Bags[slotB].Quantity, "overflow starts a new stack")
+
+	_, err = NewConsumable("c", "bogus", 1)
+	assert.Error(t, err)
+}
+
+func TestConsumables_CanAddToFullBagByStacking(t *testing.T) {
+	withTestConsumables(t)
+	inv := NewInventory()
+	potion, _ := NewConsumable("a", "health_potion", 1)
+	inv.Bags[0] = potion
+	for i := 1; i < inv.MaxBagSlots; i++ {
+		inv.Bags[i] = NewItem("filler", ItemTypeRing, 1)
+	}
+
+	another, _ := NewConsumable("b", "health_potion", 2)
+	assert.True(t, inv.CanAdd(another))
+	assert.False(t, inv.CanAdd(NewItem("ring", ItemTypeRing, 1)))
+
+	scroll, _ := NewConsumable("c", "identify_scroll", 1)
+	assert.False(t, inv.CanAdd(scroll))
+}
+
+func TestUseItem_HealthPotionSharesCooldown(t *testing.T) {
+	withTestConsumables(t)
+	world, player := newConsumableTestWorld(t)
+	healthSlot := addTestConsumable(t, player, "health_potion", 2)
+	manaSlot := addTestConsumable(t, player, "mana_potion", 1)
+
+	result, err := world.UseItem(player.ID, healthSlot, -1)
+	require.NoError(t, err)
+	assert.Equal(t, 60.0, player.Health)
+	assert.Equal(t, 50.0, result.Restored)
+	assert.Equal(t, 1, result.RemainingInSlot)
+	assert.InDelta(t, 2.0, result.PotionCooldown, 0.1)
+
+	_, err = world.UseItem(player.ID, manaSlot, -1)
+	assert.EqualError(t, err, "potion on cooldown", "mana potions share the cooldown")
+	assert.Equal(t, 0.0, player.Mana)
+
+	player.PotionCooldownUntil = time.Now().Add(-time.Second)
+	_, err = world.UseItem(player.ID, manaSlot, -1)
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, player.Mana)
+	assert.Nil(t, player.Inventory.Bags[manaSlot], "empty stacks leave the bag")
+}
+
+func TestUseItem_FullHealthKeepsPotion(t *testing.T) {
+	withTestConsumables(t)
+	world, player := newConsumableTestWorld(t)
+	player.Health = player.MaxHealth
+	slot := addTestConsumable(t, player, "health_potion", 1)
+
+	_, err := world.UseItem(player.ID, slot, -1)
+	assert.Error(t, err)
+	assert.Equal(t, 1, player.Inventory.Bags[slot].Quantity)
+	assert.True(t, player.PotionCooldownUntil.IsZero())
+}
+
+func TestUseItem_TownPortal(t *testing.T) {
+	withTestConsumables(t)
+	world, player := newConsumableTestWorld(t)
+	slot := addTestConsumable(t, player, "town_portal_scroll", 1)
+
+	result, err := world.UseItem(player.ID, slot, -1)
+	require.NoError(t, err)
+	require.NotNil(t, result.Position)
+	assert.Equal(t, world.Board.GetSpawnPoint(), player.Position)
+}
+
+func TestUseItem_Identify(t *testing.T) {
+	withTestConsumables(t)
+	world, player := newConsumableTestWorld(t)
+	scrollSlot := addTestConsumable(t, player, "identify_scroll", 2)
+	ring := NewItem("ring", ItemTypeRing, 1)
+	ring.Unidentified = true
+	ringSlot, err := player.Inventory.AddToBag(ring)
+	require.NoError(t, err)
+
+	_, err = world.UseItem(player.ID, scrollSlot, scrollSlot)
+	assert.Error(t, err, "scrolls aren't unidentified items")
+
+	result, err := world.UseItem(player.ID, scrollSlot, ringSlot)
+	require.NoError(t, err)
+	assert.Equal(t, ring, result.IdentifiedItem)
+	assert.False(t, ring.Unidentified)
+
+	_, err = world.UseItem(player.ID, scrollSlot, ringSlot)
+	assert.EqualError(t, err, "item is already identified")
+}
+
+func TestUseItem_Rejected(t *testing.T) {
+	withTestConsumables(t)
+	world, player := newConsumableTestWorld(t)
+	ringSlot, _ := player.Inventory.AddToBag(NewItem("ring", ItemTypeRing, 1))
+	potionSlot := addTestConsumable(t, player, "health_potion", 1)
+
+	_, err := world.UseItem(player.ID, ringSlot, -1)
+	assert.EqualError(t, err, "item is not usable")
+	_, err = world.UseItem(player.ID, 59, -1)
+	assert.EqualError(t, err, "no item in bag slot")
+
+	player.Health = 0
+	_, err = world.UseItem(player.ID, potionSlot, -1)
+	assert.EqualError(t, err, "player is dead")
+}
+
+func TestUnidentifiedItemsGrantNoStats(t *testing.T) {
+	inv := NewInventory()
+	ring := &Item{ID: "ring", Type: ItemTypeRing, Affixes: []ItemAffix{{Stat: StatDamage, Value: 5}}, Unidentified: true}
+	_, err := inv.EquipItem(ring)
+	require.NoError(t, err)
+
+	assert.Zero(t, inv.GetAllStats()[StatDamage])
+	ring.Unidentified = false
+	assert.Equal(t, 5.0, inv.GetAllStats()[StatDamage])
+}
+
+func TestConsumable_SerializeRoundTrip(t *testing.T) {
+	withTestConsumables(t)
+	potion, err := NewConsumable("p", "mana_potion", 3)
+	require.NoError(t, err)
+
+	restored := DeserializeItem(potion.Serialize())
+	assert.Equal(t, ItemTypeConsumable, restored.Type)
+	assert.Equal(t, "mana_potion", restored.ConsumableType)
+	assert.Equal(t, 3, restored.Quantity)
+}
+
+func TestDropLoot_Consumables(t *testing.T) {
+	withTestConsumables(t)
+	config.Items.ConsumableDropChance = 1.0
+	world := newTestWorldWithoutEnemies()
+	enemy := createTestEnemy("e1", "zombie", Vector3{}, createTestEnemyConfig("melee", 10.0))
+
+	for i := 0; i < 50 && len(world.groundItems) == 0; i++ {
+		world.dropLoot(enemy)
+	}
+	require.NotEmpty(t, world.groundItems)
+	for _, ground := range world.groundItems {
+		assert.Equal(t, ItemTypeConsumable, ground.Item.Type)
+		assert.Contains(t, []string{"health_potion", "mana_potion"}, ground.Item.ConsumableType, "only weighted types drop")
+	}
+}
@@ -0,0 +1,379 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+)
+
+// DeathPenalty is what a player loses when they die
+type DeathPenalty string
+
+const (
+	DeathPenaltyNone DeathPenalty = "none"
+	DeathPenaltyXP   DeathPenalty = "xp" // Lose a percentage of experience
+)
+
+// DamageRecord is one hit taken by a player, kept for the death recap
+type DamageRecord struct {
+	SourceID   string
+	SourceType string // "enemy" or "player"
+	SourceName string // Enemy name or player username
+	Amount     float64
+	Type       DamageType
+	At         time.Time
+}
+
+// DeathRecap summarizes a player's death for their client
+type DeathRecap struct {
+	PlayerID   string
+	KillerID   string
+	KillerType string
+	KillerName string
+	DiedAt     time.Time
+	RespawnAt  time.Time
+	XPLost     float64
+	Damage     []DamageRecord // Oldest first
+}
+
+// Serialize converts the recap to a map for JSON. Times are relative to the
+// death, since recaps are sent the tick it happens.
+func (r DeathRecap) Serialize() map[string]interface{} {
+	diedAt := r.DiedAt
+	if diedAt.IsZero() && len(r.Damage) > 0 {
+		diedAt = r.Damage[len(r.Damage)-1].At
+	}
+
+	total := 0.0
+	damage := make([]map[string]interface{}, 0, len(r.Damage))
+	for _, record := range r.Damage {
+		total += record.Amount
+		damage = append(damage, map[string]interface{}{
+			"sourceID":   record.SourceID,
+			"sourceType": record.SourceType,
+			"sourceName": record.SourceName,
+			"amount":     record.Amount,
+			"damageType": string(record.Type),
+			"secondsAgo": math.Round(diedAt.Sub(record.At).Seconds()*10) / 10,
+		})
+	}
+
+	return map[string]interface{}{
+		"killerID":    r.KillerID,
+		"killerType":  r.KillerType,
+		"killerName":  r.KillerName,
+		"respawnIn":   math.Max(r.RespawnAt.Sub(diedAt).Seconds(), 0),
+		"xpLost":      r.XPLost,
+		"totalDamage": total,
+		"damage":      damage,
+	}
+}
+
+// deathSettings returns the death rules from player.json with defaults filled
+// in for anything left unset
+func deathSettings() config.DeathConfig {
+	cfg := config.Player.Death
+	if cfg.RespawnSeconds <= 0 {
+		cfg.RespawnSeconds = 5.0
+	}
+	if cfg.RespawnSecondsPerDifficulty < 0 {
+		cfg.RespawnSecondsPerDifficulty = 0
+	}
+	if cfg.MaxRespawnSeconds <= 0 {
+		cfg.MaxRespawnSeconds = 60.0
+	}
+	if cfg.Penalty == "" {
+		cfg.Penalty = string(DeathPenaltyNone)
+	}
+	cfg.XPLossPercent = math.Max(0, math.Min(cfg.XPLossPercent, 100))
+	if cfg.RecapSeconds <= 0 {
+		cfg.RecapSeconds = 10.0
+	}
+	if cfg.RecapMaxEntries <= 0 {
+		cfg.RecapMaxEntries = 8
+	}
+	return cfg
+}
+
+// recordDamage remembers a hit for the death recap, forgetting hits that are
+// too old or beyond the recap size
+func (p *Player) recordDamage(record DamageRecord) {
+	settings := deathSettings()
+	cutoff := record.At.Add(-time.Duration(settings.RecapSeconds * float64(time.Second)))
+
+	kept := p.recentDamage[:0]
+	for _, old := range p.recentDamage {
+		if old.At.After(cutoff) {
+			kept = append(kept, old)
+		}
+	}
+	kept = append(kept, record)
+	if len(kept) > settings.RecapMaxEntries {
+		kept = kept[len(kept)-settings.RecapMaxEntries:]
+	}
+	p.recentDamage = kept
+}
+
+// applyDeathPenalty takes the configured penalty from a player who just died
+// and returns the experience lost
+func (p *Player) applyDeathPenalty() float64 {
+	settings := deathSettings()
+	switch DeathPenalty(settings.Penalty) {
+	case DeathPenaltyXP:
+		lost := math.Floor(p.Experience * settings.XPLossPercent / 100)
+		p.Experience -= lost
+		return lost
+	default:
+		return 0
+	}
+}
+
+// enemyDisplayName returns the configured name of an enemy, or its type
+func enemyDisplayName(enemy *Enemy) string {
+	if cfg, ok := config.GetEnemyConfig(enemy.Type); ok && cfg.Name != "" {
+		return cfg.Name
+	}
+	return enemy.Type
+}
+
+// enemySource returns the recap name for damage from an enemy that may
+// already have been removed. Caller must hold w.mu.
+func (w *World) enemySource(enemyID string) string {
+	if enemy, ok := w.enemies[enemyID]; ok {
+		return enemyDisplayName(enemy)
+	}
+	return "enemy"
+}
+
+// damagePlayer applies a hit to a living player, records it for the death
+// recap and kills the player if their health runs out. Returns true if the hit
+// was fatal. Caller must hold w.mu.
+func (w *World) damagePlayer(victim *Player, damage DamageInfo, sourceType, sourceName string) bool {
+	if victim.IsDead() {
+		return false
+	}
+
+	healthBefore := victim.Health
+	died := ApplyDamage(victim, damage)
+	victim.recordDamage(DamageRecord{
+		SourceID:   damage.SourceID,
+		SourceType: sourceType,
+		SourceName: sourceName,
+		Amount:     damage.Amount,
+		Type:       damage.Type,
+		At:         w.Now(),
+	})
+
+	// Damage taken is broken down by enemy type, or by attacker for PvP
+	meterSource := sourceName
+	if enemy, ok := w.enemies[damage.SourceID]; ok && sourceType == "enemy" {
+		meterSource = enemy.Type
+	}
+	event := DamageEvent{
+		TargetID:    victim.ID,
+		TargetType:  "player",
+		Damage:      damage.Amount,
+		Type:        damage.Type,
+		SourceID:    damage.SourceID,
+		SourceType:  sourceType,
+		SourceName:  meterSource,
+		AbilityType: damage.AbilityType,
+	}
+	if died {
+		event.Overkill = math.Max(0, damage.Amount-healthBefore)
+	}
+	w.emitDamage(event)
+
+	if died {
+		w.killPlayer(victim, damage.SourceID, sourceType, sourceName)
+	}
+	return died
+}
+
+// damageMinion applies an enemy's hit to a minion and removes the minion if
+// its health runs out. Returns true if the hit destroyed it.
+// Caller must hold w.mu.
+func (w *World) damageMinion(minion *Minion, damage DamageInfo, sourceName string) bool {
+	healthBefore := minion.Health
+	died := ApplyDamage(minion, damage)
+
+	event := DamageEvent{
+		TargetID:   minion.ID,
+		TargetType: "minion",
+		Damage:     damage.Amount,
+		Type:       damage.Type,
+		SourceID:   damage.SourceID,
+		SourceType: "enemy",
+		SourceName: sourceName,
+	}
+	if died {
+		event.Overkill = math.Max(0, damage.Amount-healthBefore)
+	}
+	w.emitDamage(event)
+
+	if died {
+		delete(w.minions, minion.ID)
+		w.emitDeath(DeathEvent{
+			EntityID:   minion.ID,
+			EntityType: "minion",
+			KillerID:   damage.SourceID,
+			KillerType: "enemy",
+		})
+	}
+	return died
+}
+
+// killPlayer puts a player into the death state: they stop moving, pay the
+// death penalty and must wait out a respawn timer. Caller must hold w.mu.
+func (w *World) killPlayer(victim *Player, killerID, killerType, killerName string) {
+	now := w.Now()
+	victim.Health = 0
+	victim.Velocity = Vector3{}
+	victim.Movement = nil
+	victim.DiedAt = now
+	victim.RespawnAt = now.Add(time.Duration(w.respawnDelay(victim.Position) * float64(time.Second)))
+
+	xpLost := victim.applyDeathPenalty()
+
+	w.emitDeath(DeathEvent{
+		EntityID:   victim.ID,
+		EntityType: "player",
+		KillerID:   killerID,
+		KillerType: killerType,
+	})
+
+	damage := make([]DamageRecord, len(victim.recentDamage))
+	copy(damage, victim.recentDamage)
+	w.deathRecaps = append(w.deathRecaps, DeathRecap{
+		PlayerID:   victim.ID,
+		KillerID:   killerID,
+		KillerType: killerType,
+		KillerName: killerName,
+		DiedAt:     now,
+		RespawnAt:  victim.RespawnAt,
+		XPLost:     xpLost,
+		Damage:     damage,
+	})
+
+	log.Printf("[DEATH] %s was killed by %s (respawn in %.0fs, lost %.0f xp)",
+		victim.Username, killerName, victim.RespawnAt.Sub(now).Seconds(), xpLost)
+}
+
+// respawnDelay returns how long a player who died at pos must wait, scaling
+// with the difficulty of the tile. Caller must hold w.mu.
+func (w *World) respawnDelay(pos Vector3) float64 {
+	settings := deathSettings()
+	difficulty := 0
+	if w.Board != nil {
+		if tile := w.Board.GetTile(WorldToHex(pos, layerFromY(pos.Y))); tile != nil {
+			difficulty = tile.Difficulty
+		}
+	}
+	delay := settings.RespawnSeconds + settings.RespawnSecondsPerDifficulty*float64(difficulty)
+	return math.Min(delay, settings.MaxRespawnSeconds)
+}
+
+// respawnPoint returns where a player who died at pos comes back: the
+// entrance room of the dungeon they died in, or town. Caller must hold w.mu.
+func (w *World) respawnPoint(pos Vector3) Vector3 {
+	if w.Board == nil {
+		return Vector3{}
+	}
+
+	layer := layerFromY(pos.Y)
+	if layer < 0 {
+		deathCoord := WorldToHex(pos, layer)
+		var entrance *HexCoord
+		bestDistance := 0
+		for coord, tile := range w.Board.Tiles {
+			if coord.Layer != layer || tile.DungeonExitTarget == nil {
+				continue
+			}
+			distance := HexDistance(deathCoord, coord)
+			if entrance == nil || distance < bestDistance || (distance == bestDistance && hexLess(coord, *entrance)) {
+				c := coord
+				entrance = &c
+				bestDistance = distance
+			}
+		}
+		if entrance != nil {
+			return HexToWorld(*entrance)
+		}
+	}
+
+	spawn := w.Board.GetSpawnPoint()
+	spawn.Y = 0
+	return spawn
+}
+
+// hexLess orders hex coordinates so ties between equally close tiles are deterministic
+func hexLess(a, b HexCoord) bool {
+	if a.Q != b.Q {
+		return a.Q < b.Q
+	}
+	return a.R < b.R
+}
+
+// RespawnPlayer brings a dead player back at full health once their respawn
+// timer has run out. Returns the respawn position.
+func (w *World) RespawnPlayer(playerID string) (Vector3, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.players[playerID]
+	if !ok {
+		return Vector3{}, errors.New("player not found")
+	}
+	if !player.IsDead() {
+		return Vector3{}, errors.New("player is not dead")
+	}
+	if remaining := player.RespawnAt.Sub(w.Now()); remaining > 0 {
+		return Vector3{}, fmt.Errorf("respawn available in %.0fs", math.Ceil(remaining.Seconds()))
+	}
+
+	pos := w.respawnPoint(player.Position)
+	player.Position = pos
+	player.CurrentTile = WorldToHex(pos, layerFromY(pos.Y))
+	player.Velocity = Vector3{}
+	player.Health = player.MaxHealth
+	player.Mana = player.MaxMana
+	player.DiedAt = time.Time{}
+	player.RespawnAt = time.Time{}
+	player.recentDamage = nil
+
+	log.Printf("[RESPAWN] Player %s respawned at (%.1f, %.1f, %.1f)", player.Username, pos.X, pos.Y, pos.Z)
+	return pos, nil
+}
+
+// DrainDeathRecaps returns and clears the death recaps waiting to be sent
+func (w *World) DrainDeathRecaps() []DeathRecap {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	recaps := w.deathRecaps
+	w.deathRecaps = nil
+	return recaps
+}
+
+// awardExperience credits a player with the experience for killing an enemy.
+// Caller must hold w.mu.
+func (w *World) awardExperience(playerID string, enemy *Enemy) {
+	player, ok := w.players[playerID]
+	if !ok || player.IsDead() {
+		return
+	}
+	if cfg, ok := config.GetEnemyConfig(enemy.Type); ok {
+		xp := float64(cfg.XPReward) * enemy.xpMultiplier()
+		if enemy.SummonerID != "" {
+			xp *= summonSettings().XPMultiplier
+		}
+		if rankCfg, ok := eliteRankConfig(enemy.Rank); ok && rankCfg.XPMultiplier > 0 {
+			xp *= rankCfg.XPMultiplier
+		}
+		player.Experience += xp
+	}
+}
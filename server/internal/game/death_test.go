@@ -0,0 +1,161 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withDeathConfig swaps in death rules for the duration of a test
+func withDeathConfig(t *testing.T, cfg config.DeathConfig) {
+	t.Helper()
+	previous := config.Player.Death
+	config.Player.Death = cfg
+	t.Cleanup(func() { config.Player.Death = previous })
+}
+
+// withEnemyConfig registers an enemy type for the duration of a test
+func withEnemyConfig(t *testing.T, enemyType string, cfg config.EnemyConfig) {
+	t.Helper()
+	if config.Enemies.EnemyTypes == nil {
+		config.Enemies.EnemyTypes = make(map[string]config.EnemyConfig)
+	}
+	previous, existed := config.Enemies.EnemyTypes[enemyType]
+	config.Enemies.EnemyTypes[enemyType] = cfg
+	t.Cleanup(func() {
+		if existed {
+			config.Enemies.EnemyTypes[enemyType] = previous
+		} else {
+			delete(config.Enemies.EnemyTypes, enemyType)
+		}
+	})
+}
+
+func TestDamagePlayer_KillsAndRecordsRecap(t *testing.T) {
+	withDeathConfig(t, config.DeathConfig{RespawnSeconds: 5})
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	player := NewPlayer("p1", "One")
+	world.players[player.ID] = player
+
+	died := world.damagePlayer(player, DamageInfo{Amount: 30, Type: DamageTypePhysical, SourceID: "e1"}, "enemy", "Zombie")
+	assert.False(t, died)
+	died = world.damagePlayer(player, DamageInfo{Amount: player.MaxHealth, Type: DamageTypeFire, SourceID: "e2"}, "enemy", "Imp")
+	require.True(t, died)
+
+	assert.True(t, player.IsDead())
+	assert.False(t, player.RespawnAt.IsZero())
+	require.Len(t, world.deathEvents, 1)
+	assert.Equal(t, "e2", world.deathEvents[0].KillerID)
+
+	recaps := world.DrainDeathRecaps()
+	require.Len(t, recaps, 1)
+	assert.Equal(t, "Imp", recaps[0].KillerName)
+	require.Len(t, recaps[0].Damage, 2)
+	assert.Equal(t, "Zombie", recaps[0].Damage[0].SourceName)
+	assert.Empty(t, world.DrainDeathRecaps())
+
+	assert.False(t, world.damagePlayer(player, DamageInfo{Amount: 10, SourceID: "e1"}, "enemy", "Zombie"), "corpses take no damage")
+}
+
+func TestDeadPlayerCannotAct(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	player := NewPlayer("p1", "One")
+	world.players[player.ID] = player
+	world.killPlayer(player, "e1", "enemy", "Zombie")
+
+	player.SetVelocity(Vector3{X: 5})
+	world.Update(50 * time.Millisecond)
+	assert.Equal(t, Vector3{}, player.Position)
+
+	bolt := &Ability{Type: "bolt", Shape: AbilityShapeProjectile, Damage: 10, Speed: 10}
+	result := world.CastPlayerAbility(player, bolt, Vector3{X: 1}, CastOptions{})
+	assert.Empty(t, result.ProjectileID)
+
+	assert.EqualError(t, world.PickupItem(player.ID, "missing"), "player is dead")
+}
+
+func TestRespawnPlayer_WaitsForTimer(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	player := NewPlayer("p1", "One")
+	player.Position = Vector3{X: 3, Z: 2}
+	world.players[player.ID] = player
+
+	_, err := world.RespawnPlayer(player.ID)
+	assert.EqualError(t, err, "player is not dead")
+
+	world.killPlayer(player, "e1", "enemy", "Zombie")
+	_, err = world.RespawnPlayer(player.ID)
+	assert.Error(t, err, "respawn timer still running")
+
+	player.RespawnAt = time.Now().Add(-time.Second)
+	pos, err := world.RespawnPlayer(player.ID)
+	require.NoError(t, err)
+	assert.Equal(t, world.Board.GetSpawnPoint(), pos)
+	assert.False(t, player.IsDead())
+	assert.Equal(t, player.MaxHealth, player.Health)
+}
+
+func TestRespawnDelay_ScalesWithDifficulty(t *testing.T) {
+	withDeathConfig(t, config.DeathConfig{RespawnSeconds: 5, RespawnSecondsPerDifficulty: 2, MaxRespawnSeconds: 12})
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	tile := world.Board.GetTile(HexCoord{Q: 0, R: 0})
+
+	tile.Difficulty = 1
+	assert.Equal(t, 7.0, world.respawnDelay(Vector3{}))
+	tile.Difficulty = 10
+	assert.Equal(t, 12.0, world.respawnDelay(Vector3{}), "capped at the maximum")
+}
+
+func TestRespawnPoint_DungeonEntrance(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+
+	exit := HexCoord{Q: 0, R: 0}
+	entrance := NewTile(HexCoord{Q: 0, R: 0, Layer: -1}, "dungeon", TileTypeDungeon, 3)
+	entrance.DungeonExitTarget = &exit
+	room := NewTile(HexCoord{Q: 2, R: 0, Layer: -1}, "dungeon", TileTypeDungeon, 3)
+	world.Board.Tiles[entrance.Coord] = entrance
+	world.Board.Tiles[room.Coord] = room
+
+	deathPos := HexToWorld(room.Coord)
+	assert.Equal(t, HexToWorld(entrance.Coord), world.respawnPoint(deathPos))
+	assert.Equal(t, world.Board.GetSpawnPoint(), world.respawnPoint(Vector3{X: 4}), "overworld deaths go back to town")
+}
+
+func TestDeathPenalty_Experience(t *testing.T) {
+	withDeathConfig(t, config.DeathConfig{Penalty: "xp", XPLossPercent: 10})
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	player := NewPlayer("p1", "One")
+	player.Experience = 255
+	world.players[player.ID] = player
+
+	world.killPlayer(player, "e1", "enemy", "Zombie")
+
+	assert.Equal(t, 230.0, player.Experience)
+	recaps := world.DrainDeathRecaps()
+	require.Len(t, recaps, 1)
+	assert.Equal(t, 25.0, recaps[0].XPLost)
+}
+
+func TestAwardExperience(t *testing.T) {
+	withEnemyConfig(t, "test_zombie", config.EnemyConfig{Name: "Zombie", XPReward: 15})
+	world := newTestWorldWithoutEnemies()
+	player := NewPlayer("p1", "One")
+	world.players[player.ID] = player
+	enemy := createTestEnemy("e1", "test_zombie", Vector3{}, createTestEnemyConfig("melee", 10.0))
+
+	world.awardExperience(player.ID, enemy)
+	assert.Equal(t, 15.0, player.Experience)
+
+	player.Health = 0
+	world.awardExperience(player.ID, enemy)
+	assert.Equal(t, 15.0, player.Experience, "dead players earn nothing")
+}
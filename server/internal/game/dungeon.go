@@ -210,6 +210,18 @@ func generateDungeonTileContent(tile *Tile, allCoords []HexCoord, index int, isB
 	}
 
 	if isBoss {
+		tile.BossType = pickBossType(tile.Difficulty, rng)
+	}
+
+	if isBoss && tile.BossType != "" {
+		// Scripted boss in the middle of the room, with a small escort; the
+		// rest of the fight comes from its add waves
+		tile.Spawns = append(tile.Spawns, EnemySpawnPoint{
+			Position:   Vector3{X: center.X, Y: center.Y, Z: center.Z + 5},
+			EnemyTypes: enemyTypes,
+			Count:      1 + tile.Difficulty/2,
+		})
+	} else if isBoss {
 		// Boss room: one big cluster + boss enemy type
 		bossTypes := append([]string{"tank"}, enemyTypes...)
 		tile.Spawns = append(tile.Spawns, EnemySpawnPoint{
@@ -0,0 +1,317 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+)
+
+// EliteRank marks an enemy as a member of an elite pack
+type EliteRank string
+
+const (
+	RankNormal   EliteRank = ""
+	RankElite    EliteRank = "elite"    // Pack member with boosted stats
+	RankChampion EliteRank = "champion" // Pack leader, carries the affixes
+)
+
+// EliteAffix is a champion modifier from the elites section of enemies.json
+type EliteAffix string
+
+const (
+	AffixFast          EliteAffix = "fast"
+	AffixVampiric      EliteAffix = "vampiric"
+	AffixShielded      EliteAffix = "shielded"
+	AffixFireEnchanted EliteAffix = "fire_enchanted"
+	AffixTeleporter    EliteAffix = "teleporter"
+)
+
+// eliteRankConfig returns the multipliers for a rank. Normal enemies get false.
+func eliteRankConfig(rank EliteRank) (config.EliteRankConfig, bool) {
+	switch rank {
+	case RankElite:
+		return config.Enemies.Elites.Elite, true
+	case RankChampion:
+		return config.Enemies.Elites.Champion, true
+	}
+	return config.EliteRankConfig{}, false
+}
+
+// eliteAffixConfig returns an affix's config, or the zero config if it isn't defined
+func eliteAffixConfig(affix EliteAffix) config.EliteAffixConfig {
+	return config.Enemies.Elites.Affixes[string(affix)]
+}
+
+// eliteChance returns the chance that a spawn group on a tile of the given
+// difficulty becomes an elite pack
+func eliteChance(difficulty int) float64 {
+	cfg := config.Enemies.Elites
+	if len(cfg.Affixes) == 0 || difficulty < cfg.MinDifficulty {
+		return 0
+	}
+	chance := cfg.BaseChance + cfg.ChancePerDifficulty*float64(difficulty-cfg.MinDifficulty)
+	if cfg.MaxChance > 0 {
+		chance = math.Min(chance, cfg.MaxChance)
+	}
+	return chance
+}
+
+// rollEliteAffixes picks distinct affixes for a champion. Harder tiles roll
+// one more affix for every two difficulty levels above the minimum.
+func rollEliteAffixes(difficulty int) []EliteAffix {
+	cfg := config.Enemies.Elites
+	names := make([]string, 0, len(cfg.Affixes))
+	for name := range cfg.Affixes {
+		names = append(names, name)
+	}
+	sort.Strings(names) // Map order is random; keep rolls reproducible
+
+	count := max(cfg.MinAffixes, 1) + max(difficulty-cfg.MinDifficulty, 0)/2
+	if cfg.MaxAffixes > 0 {
+		count = min(count, cfg.MaxAffixes)
+	}
+	count = min(count, len(names))
+
+	rand.Shuffle(len(names), func(i, j int) { names[i], names[j] = names[j], names[i] })
+	affixes := make([]EliteAffix, count)
+	for i := range affixes {
+		affixes[i] = EliteAffix(names[i])
+	}
+	return affixes
+}
+
+// makeElite promotes an enemy to an elite rank, scaling its stats and
+// applying its affixes
+func (e *Enemy) makeElite(rank EliteRank, affixes []EliteAffix) {
+	e.Rank = rank
+
+	if rankCfg, ok := eliteRankConfig(rank); ok {
+		if rankCfg.HealthMultiplier > 0 {
+			e.MaxHealth *= rankCfg.HealthMultiplier
+			e.Health = e.MaxHealth
+		}
+		if rankCfg.DamageMultiplier > 0 {
+			e.Damage *= rankCfg.DamageMultiplier
+			if e.AI != nil {
+				e.AI.ExplosionDamage *= rankCfg.DamageMultiplier
+			}
+		}
+	}
+
+	for _, affix := range affixes {
+		cfg := eliteAffixConfig(affix)
+		e.Affixes = append(e.Affixes, affix)
+
+		if e.AI != nil && cfg.SpeedMultiplier > 0 {
+			e.AI.MoveSpeed *= cfg.SpeedMultiplier
+			e.AI.ChaseSpeed *= cfg.SpeedMultiplier
+			e.AI.ChargeSpeed *= cfg.SpeedMultiplier
+		}
+		if e.AI != nil && cfg.AttackSpeedMultiplier > 0 {
+			e.AI.AttackCooldown /= cfg.AttackSpeedMultiplier
+		}
+		if cfg.ShieldFraction > 0 {
+			e.MaxShield += e.MaxHealth * cfg.ShieldFraction
+			e.Shield = e.MaxShield
+		}
+	}
+}
+
+// HasAffix returns true if the enemy rolled the given affix
+func (e *Enemy) HasAffix(affix EliteAffix) bool {
+	for _, a := range e.Affixes {
+		if a == affix {
+			return true
+		}
+	}
+	return false
+}
+
+// absorbWithShield soaks up damage with the enemy's shield and returns what's
+// left over for its health
+func (e *Enemy) absorbWithShield(amount float64) float64 {
+	e.lastDamagedAt = e.now()
+	if e.Shield <= 0 || amount <= 0 {
+		return amount
+	}
+	absorbed := math.Min(e.Shield, amount)
+	e.Shield -= absorbed
+	return amount - absorbed
+}
+
+// attackDamageType returns the damage type of an attack after affixes that convert it
+func (e *Enemy) attackDamageType(base DamageType) DamageType {
+	for _, affix := range e.Affixes {
+		if cfg := eliteAffixConfig(affix); cfg.DamageType != "" {
+			return DamageType(cfg.DamageType)
+		}
+	}
+	return base
+}
+
+// lifeStealFraction returns the fraction of damage dealt the enemy heals
+func (e *Enemy) lifeStealFraction() float64 {
+	total := 0.0
+	for _, affix := range e.Affixes {
+		total += eliteAffixConfig(affix).LifeSteal
+	}
+	return total
+}
+
+// rollElitePack decides whether a spawn group becomes an elite pack and, if
+// so, promotes its leader (the first enemy) to champion and the rest to elites
+func rollElitePack(enemies []*Enemy, difficulty int) {
+	if len(enemies) == 0 || rand.Float64() >= eliteChance(difficulty) {
+		return
+	}
+
+	enemies[0].makeElite(RankChampion, rollEliteAffixes(difficulty))
+	for _, enemy := range enemies[1:] {
+		enemy.makeElite(RankElite, nil)
+	}
+}
+
+// applyLifeSteal heals an enemy for part of the damage it dealt.
+// Caller must hold w.mu.
+func (w *World) applyLifeSteal(enemy *Enemy, dealt float64) {
+	if enemy == nil || enemy.Dead || dealt <= 0 {
+		return
+	}
+	if fraction := enemy.lifeStealFraction(); fraction > 0 {
+		enemy.Health = math.Min(enemy.MaxHealth, enemy.Health+dealt*fraction)
+	}
+}
+
+// triggerDeathAffixes fires affixes that go off when an elite dies.
+// Caller must hold w.mu.
+func (w *World) triggerDeathAffixes(enemy *Enemy) {
+	for _, affix := range enemy.Affixes {
+		cfg := eliteAffixConfig(affix)
+		if cfg.DeathExplosionDamage <= 0 || cfg.DeathExplosionRadius <= 0 {
+			continue
+		}
+
+		damageType := DamageTypeFire
+		if cfg.DamageType != "" {
+			damageType = DamageType(cfg.DamageType)
+		}
+		layer := layerFromY(enemy.Position.Y)
+		for _, player := range w.players {
+			if layerFromY(player.Position.Y) != layer {
+				continue
+			}
+			if Distance2D(enemy.Position, player.Position) <= cfg.DeathExplosionRadius {
+				w.damagePlayer(player, DamageInfo{
+					Amount:   cfg.DeathExplosionDamage,
+					Type:     damageType,
+					SourceID: enemy.ID,
+					TargetID: player.ID,
+				}, "enemy", enemyDisplayName(enemy))
+			}
+		}
+		w.abilityCastEvents = append(w.abilityCastEvents, AbilityCastEvent{
+			CasterID:    enemy.ID,
+			CasterType:  "enemy",
+			OwnerID:     enemy.ID,
+			AbilityType: "elite_" + string(affix),
+			Position:    enemy.Position,
+		})
+	}
+}
+
+// updateEliteAffixes runs per-tick affixes: shield regeneration and
+// teleporting toward the target. Caller must hold w.mu.
+func (w *World) updateEliteAffixes(enemy *Enemy, ctx *EnemyAIContext) {
+	if enemy.Dead || len(enemy.Affixes) == 0 {
+		return
+	}
+	now := w.Now()
+
+	for _, affix := range enemy.Affixes {
+		cfg := eliteAffixConfig(affix)
+
+		if cfg.ShieldFraction > 0 && enemy.Shield < enemy.MaxShield &&
+			now.Sub(enemy.lastDamagedAt).Seconds() >= cfg.ShieldRegenSeconds {
+			enemy.Shield = enemy.MaxShield
+		}
+
+		if cfg.TeleportRange > 0 {
+			w.eliteTeleport(enemy, ctx, cfg, now)
+		}
+	}
+}
+
+// eliteTeleport blinks a chasing enemy toward its target, stopping short of
+// melee range. Caller must hold w.mu.
+func (w *World) eliteTeleport(enemy *Enemy, ctx *EnemyAIContext, cfg config.EliteAffixConfig, now time.Time) {
+	ai := enemy.AI
+	if ai == nil || ai.State != AIStateChase || now.Sub(enemy.lastTeleportAt).Seconds() < cfg.TeleportCooldown {
+		return
+	}
+	if enemy.HasStatusEffect(StatusEffectStun) {
+		return
+	}
+	targetPos, ok := ai.targetPosition(ctx)
+	if !ok {
+		return
+	}
+
+	dx := targetPos.X - enemy.Position.X
+	dz := targetPos.Z - enemy.Position.Z
+	distance := math.Sqrt(dx*dx + dz*dz)
+	step := math.Min(distance-ai.AttackRange*0.8, cfg.TeleportRange)
+	if step <= 1.0 {
+		return // Close enough to walk
+	}
+
+	direction := Vector3{X: dx / distance, Z: dz / distance}
+	dest := Vector3{
+		X: enemy.Position.X + direction.X*step,
+		Y: enemy.Position.Y,
+		Z: enemy.Position.Z + direction.Z*step,
+	}
+	if w.Board != nil {
+		if w.Board.collidesWithTerrain(dest, layerFromY(dest.Y), entityCollisionRadius, false) ||
+			!w.hasLineOfSight(enemy.Position, dest) {
+			return
+		}
+	}
+
+	enemy.Position = dest
+	enemy.lastTeleportAt = now
+	w.markEnemiesMoved()
+	w.abilityCastEvents = append(w.abilityCastEvents, AbilityCastEvent{
+		CasterID:    enemy.ID,
+		CasterType:  "enemy",
+		OwnerID:     enemy.ID,
+		AbilityType: "elite_teleport",
+		Position:    dest,
+		Direction:   direction,
+	})
+}
+
+// serializeElite adds rank, affix and shield info for elites. Normal enemies
+// are left unchanged.
+func (e *Enemy) serializeElite(result map[string]interface{}) {
+	if e.Rank == RankNormal {
+		return
+	}
+
+	affixes := make([]map[string]interface{}, 0, len(e.Affixes))
+	for _, affix := range e.Affixes {
+		name := eliteAffixConfig(affix).Name
+		if name == "" {
+			name = string(affix)
+		}
+		affixes = append(affixes, map[string]interface{}{"id": string(affix), "name": name})
+	}
+	result["rank"] = string(e.Rank)
+	result["affixes"] = affixes
+	if e.MaxShield > 0 {
+		result["shield"] = e.Shield
+		result["maxShield"] = e.MaxShield
+	}
+}
@@ -0,0 +1,174 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withEliteConfig installs a known elites section for the duration of a test
+func withEliteConfig(t *testing.T) {
+	t.Helper()
+	previous := config.Enemies.Elites
+	config.Enemies.Elites = config.ElitesConfig{
+		MinDifficulty:       2,
+		BaseChance:          1.0,
+		ChancePerDifficulty: 0,
+		MinAffixes:          1,
+		MaxAffixes:          3,
+		Elite:               config.EliteRankConfig{HealthMultiplier: 1.5, DamageMultiplier: 1.2, XPMultiplier: 2},
+		Champion:            config.EliteRankConfig{HealthMultiplier: 3, DamageMultiplier: 1.5, XPMultiplier: 5, ExtraDrops: 2},
+		Affixes: map[string]config.EliteAffixConfig{
+			"fast":           {Name: "Fast", SpeedMultiplier: 1.5, AttackSpeedMultiplier: 2},
+			"vampiric":       {Name: "Vampiric", LifeSteal: 0.5},
+			"shielded":       {Name: "Shielded", ShieldFraction: 0.5, ShieldRegenSeconds: 6},
+			"fire_enchanted": {Name: "Fire Enchanted", DamageType: "fire", DeathExplosionDamage: 30, DeathExplosionRadius: 3},
+			"teleporter":     {Name: "Teleporter", TeleportRange: 10, TeleportCooldown: 5},
+		},
+	}
+	t.Cleanup(func() { config.Enemies.Elites = previous })
+}
+
+func TestEliteChance_ScalesWithDifficulty(t *testing.T) {
+	withEliteConfig(t)
+	config.Enemies.Elites.BaseChance = 0.1
+	config.Enemies.Elites.ChancePerDifficulty = 0.1
+	config.Enemies.Elites.MaxChance = 0.25
+
+	assert.Zero(t, eliteChance(1), "easy tiles never spawn elites")
+	assert.InDelta(t, 0.1, eliteChance(2), 0.0001)
+	assert.InDelta(t, 0.2, eliteChance(3), 0.0001)
+	assert.InDelta(t, 0.25, eliteChance(6), 0.0001, "capped at maxChance")
+
+	assert.Len(t, rollEliteAffixes(2), 1)
+	assert.Len(t, rollEliteAffixes(4), 2)
+	assert.Len(t, rollEliteAffixes(20), 3, "capped at maxAffixes")
+}
+
+func TestRollElitePack_PromotesLeaderAndMembers(t *testing.T) {
+	withEliteConfig(t)
+	cfg := createTestEnemyConfig("melee", 10)
+	group := []*Enemy{
+		createTestEnemy("e1", "zombie", Vector3{}, cfg),
+		createTestEnemy("e2", "zombie", Vector3{}, cfg),
+	}
+
+	rollElitePack(group, 2)
+
+	leader, member := group[0], group[1]
+	assert.Equal(t, RankChampion, leader.Rank)
+	assert.Len(t, leader.Affixes, 1)
+	assert.Equal(t, 300.0, leader.MaxHealth)
+	assert.Equal(t, 15.0, leader.Damage)
+
+	assert.Equal(t, RankElite, member.Rank)
+	assert.Empty(t, member.Affixes)
+	assert.Equal(t, 150.0, member.Health)
+
+	normal := []*Enemy{createTestEnemy("e3", "zombie", Vector3{}, cfg)}
+	rollElitePack(normal, 1)
+	assert.Equal(t, RankNormal, normal[0].Rank)
+}
+
+func TestEliteAffixes_FastAndShielded(t *testing.T) {
+	withEliteConfig(t)
+	enemy := createTestEnemy("e1", "zombie", Vector3{}, createTestEnemyConfig("melee", 10))
+	enemy.makeElite(RankChampion, []EliteAffix{AffixFast, AffixShielded})
+
+	assert.Equal(t, 7.5, enemy.AI.ChaseSpeed)
+	assert.Equal(t, 0.5, enemy.AI.AttackCooldown)
+	assert.Equal(t, 150.0, enemy.MaxShield)
+
+	enemy.TakeDamage(DamageInfo{Amount: 100})
+	assert.Equal(t, 50.0, enemy.Shield)
+	assert.Equal(t, 300.0, enemy.Health, "the shield absorbs damage first")
+	enemy.TakeDamage(DamageInfo{Amount: 100})
+	assert.Zero(t, enemy.Shield)
+	assert.Equal(t, 250.0, enemy.Health)
+
+	world := newTestWorldWithoutEnemies()
+	world.addEnemy(enemy)
+	world.updateEliteAffixes(enemy, &EnemyAIContext{World: world})
+	assert.Zero(t, enemy.Shield, "the shield waits for a lull in damage")
+
+	enemy.lastDamagedAt = time.Now().Add(-7 * time.Second)
+	world.updateEliteAffixes(enemy, &EnemyAIContext{World: world})
+	assert.Equal(t, 150.0, enemy.Shield)
+
+	serialized := enemy.Serialize()
+	assert.Equal(t, "champion", serialized["rank"])
+	assert.Equal(t, 150.0, serialized["maxShield"])
+	assert.Len(t, serialized["affixes"], 2)
+}
+
+func TestEliteAffixes_VampiricAndFireEnchanted(t *testing.T) {
+	withEliteConfig(t)
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	player := NewPlayer("p1", "One")
+	player.Position = Vector3{X: 1}
+	world.players[player.ID] = player
+
+	enemy := createTestEnemy("e1", "zombie", Vector3{}, createTestEnemyConfig("melee", 10))
+	enemy.makeElite(RankChampion, []EliteAffix{AffixVampiric, AffixFireEnchanted})
+	world.addEnemy(enemy)
+	assert.Equal(t, DamageTypeFire, enemy.attackDamageType(DamageTypePhysical))
+
+	enemy.Health = 100
+	world.applyLifeSteal(enemy, 40)
+	assert.Equal(t, 120.0, enemy.Health)
+
+	healthBefore := player.Health
+	world.triggerDeathAffixes(enemy)
+	assert.Equal(t, healthBefore-30, player.Health)
+}
+
+func TestEliteAffixes_DeathExplosionStaysOnLayer(t *testing.T) {
+	withEliteConfig(t)
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	below := addTestDungeonTile(world.Board)
+	room := HexToWorld(below.Coord)
+
+	beside := NewPlayer("p1", "One")
+	beside.Position = Vector3{X: room.X + 1, Y: room.Y, Z: room.Z}
+	above := NewPlayer("p2", "Two")
+	above.Position = Vector3{X: room.X + 1, Z: room.Z}
+	world.players[beside.ID] = beside
+	world.players[above.ID] = above
+
+	enemy := createTestEnemy("e1", "zombie", room, createTestEnemyConfig("melee", 10))
+	enemy.makeElite(RankChampion, []EliteAffix{AffixFireEnchanted})
+	world.addEnemy(enemy)
+
+	world.triggerDeathAffixes(enemy)
+	assert.Equal(t, beside.MaxHealth-30, beside.Health)
+	assert.Equal(t, above.MaxHealth, above.Health, "the overworld player above the dungeon is untouched")
+}
+
+func TestEliteLootAndExperience(t *testing.T) {
+	withEliteConfig(t)
+	withEnemyConfig(t, "zombie", config.EnemyConfig{XPReward: 10})
+	world := newTestWorldWithoutEnemies()
+	player := NewPlayer("p1", "One")
+	world.players[player.ID] = player
+
+	champion := createTestEnemy("e1", "zombie", Vector3{}, createTestEnemyConfig("melee", 10))
+	champion.makeElite(RankChampion, nil)
+
+	world.dropLoot(champion)
+	assert.Len(t, world.groundItems, 3, "champions always drop, plus extra drops")
+
+	world.awardExperience(player.ID, champion)
+	assert.Equal(t, 50.0, player.Experience)
+}
+
+func TestRollRarity_BonusShiftsRolls(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		require.Equal(t, ItemRarityUnique, rollRarity(1.0))
+		require.NotEqual(t, ItemRarityNormal, rollRarity(0.6))
+	}
+}
@@ -2,6 +2,7 @@ package game
 
 import (
 	"math"
+	"math/rand"
 	"time"
 
 	"github.com/PersonThing/cs-crawler/server/internal/config"
@@ -16,6 +17,7 @@ const (
 	AIStateAttack  EnemyAIState = "attack"
 	AIStateFlee    EnemyAIState = "flee"
 	AIStateSupport EnemyAIState = "support" // For support enemies (shaman, necromancer)
+	AIStateReturn  EnemyAIState = "return"  // Leashed: walking back home, ignoring targets
 )
 
 // EnemyBehaviorType represents how the enemy behaves
@@ -33,6 +35,8 @@ const (
 
 // EnemyAI contains AI-related state and configuration
 type EnemyAI struct {
+	simTime // Timers run on the enemy's world clock
+
 	State          EnemyAIState
 	Behavior       EnemyBehaviorType
 	TargetID       string    // Current target player ID
@@ -60,28 +64,83 @@ type EnemyAI struct {
 	SummonCooldown float64   // Cooldown for summoning
 	LastSummonTime time.Time // When last summoned
 	MaxSummons     int       // Maximum number of summons allowed
-	CurrentSummons int       // Current summon count
+	CurrentSummons int       // Living summons, recounted by the world each tick
 
 	// Pack behavior
-	PackID         string // ID of the pack this enemy belongs to
-	IsPackLeader   bool   // Whether this enemy is the pack leader
-	PackBuffActive bool   // Whether pack buff is currently active
+	PackID         string    // ID of the pack this enemy belongs to
+	IsPackLeader   bool      // Whether this enemy is the pack leader
+	PackBuffActive bool      // Whether pack buff is currently active
+	FlankOffset    Vector3   // Where this member stands relative to the pack's target
+	FleeUntil      time.Time // Set when the pack's leader dies and this member panics
+
+	// Scripted encounter (bosses only)
+	Boss *BossEncounter
+
+	// Behavior tree from enemies.json (see behavior_tree.go); nil uses the
+	// built-in state machine for Behavior
+	Tree           *config.BehaviorNodeConfig
+	strafeSign     float64   // Which way the enemy circles its target
+	strafeSwitchAt time.Time // When it next changes direction
+
+	// Cached route around obstacles toward the target (see pathfinding.go)
+	Path *EnemyPath
+
+	// Leashing (see leash.go)
+	Home        *Vector3 // Where the enemy spawned; nil if it never leashes
+	LeashRadius float64  // How far from home it will follow a target
 
 	// Rage mode
 	RageMode       bool    // Whether enemy is enraged
 	RageThreshold  float64 // Health percentage that triggers rage (0-1)
 	RageDamageMult float64 // Damage multiplier when enraged
 	RageSpeedMult  float64 // Speed multiplier when enraged
+
+	// Threat
+	Threat            *ThreatTable // Who this enemy is angriest at
+	ThreatSwitchRatio float64      // A rival must exceed the current target's threat by this factor
 }
 
 // EnemyAIContext provides context for AI decisions
 type EnemyAIContext struct {
 	Players      map[string]*Player
 	Enemies      map[string]*Enemy
+	Minions      map[string]*Minion // Minions can draw aggro like players
 	DeltaSeconds float64
 	World        *World // Reference to world for spawning projectiles etc.
 }
 
+// hasLineOfSight returns true if nothing blocks sight between two positions.
+// Contexts without a world (e.g. in tests) treat everything as visible.
+func (ctx *EnemyAIContext) hasLineOfSight(from, to Vector3) bool {
+	if ctx.World == nil {
+		return true
+	}
+	return ctx.World.hasLineOfSight(from, to)
+}
+
+// behaviorTypes maps ai.type in enemies.json to a built-in behavior
+var behaviorTypes = map[string]EnemyBehaviorType{
+	"idle":     BehaviorIdle,
+	"chase":    BehaviorMelee,
+	"melee":    BehaviorMelee,
+	"ranged":   BehaviorRanged,
+	"charger":  BehaviorCharger,
+	"exploder": BehaviorExploder,
+	"support":  BehaviorSupport,
+	"summoner": BehaviorSummoner,
+}
+
+// defaultAttackRanges is the attack range of each behavior when enemies.json
+// doesn't give one
+var defaultAttackRanges = map[EnemyBehaviorType]float64{
+	BehaviorMelee:    2.0,
+	BehaviorRanged:   12.0,
+	BehaviorCharger:  10.0, // Start charging from this distance
+	BehaviorExploder: 1.0,  // Explode on contact
+	BehaviorSupport:  15.0, // Keep distance from players
+	BehaviorSummoner: 20.0, // Keep far from players
+}
+
 // NewEnemyAI creates a new enemy AI from config
 func NewEnemyAI(cfg *config.EnemyConfig) *EnemyAI {
 	ai := &EnemyAI{
@@ -90,47 +149,46 @@ func NewEnemyAI(cfg *config.EnemyConfig) *EnemyAI {
 		AggroRange:     cfg.AI.AggroRange,
 		MoveSpeed:      cfg.MoveSpeed,
 		ChaseSpeed:     cfg.AI.ChaseSpeed,
-		AttackRange:    2.0, // Default melee range
+		AttackRange:    cfg.AI.AttackRange,
 		AttackCooldown: 1.0, // Default 1 second between attacks
 		LastAttackTime: time.Now(),
 		FleeHealth:     cfg.AI.FleeHealth,
 		RageThreshold:  cfg.AI.RageThreshold,
 		RageDamageMult: 1.5,
 		RageSpeedMult:  1.3,
+		Threat:         NewThreatTable(),
+	}
+
+	ai.ThreatSwitchRatio = threatSettings().SwitchRatio
+	if cfg.AI.ThreatSwitch >= 1.0 {
+		ai.ThreatSwitchRatio = cfg.AI.ThreatSwitch
+	}
+
+	// Set behavior based on AI type
+	if behavior, ok := behaviorTypes[cfg.AI.Type]; ok {
+		ai.Behavior = behavior
 	}
 
 	// Apply config overrides
-	if cfg.AI.AttackRange > 0 {
-		ai.AttackRange = cfg.AI.AttackRange
+	if ai.AttackRange <= 0 {
+		ai.AttackRange = defaultAttackRanges[ai.Behavior]
+		if ai.AttackRange <= 0 {
+			ai.AttackRange = 2.0 // Default melee range
+		}
 	}
 	if cfg.AI.AttackCooldown > 0 {
 		ai.AttackCooldown = cfg.AI.AttackCooldown
 	}
 
-	// Set behavior based on AI type
-	switch cfg.AI.Type {
-	case "idle":
-		ai.Behavior = BehaviorIdle
-	case "chase", "melee":
-		ai.Behavior = BehaviorMelee
-	case "ranged":
-		ai.Behavior = BehaviorRanged
-		if ai.AttackRange == 2.0 { // Still default
-			ai.AttackRange = 12.0 // Default ranged attack range
-		}
-	case "charger":
-		ai.Behavior = BehaviorCharger
+	switch ai.Behavior {
+	case BehaviorCharger:
 		ai.ChargeDuration = 1.5
 		if cfg.AI.ChargeSpeed > 0 {
 			ai.ChargeSpeed = cfg.AI.ChargeSpeed
 		} else {
 			ai.ChargeSpeed = cfg.MoveSpeed * 3.0
 		}
-		if ai.AttackRange == 2.0 { // Still default
-			ai.AttackRange = 10.0 // Start charging from this distance
-		}
-	case "exploder":
-		ai.Behavior = BehaviorExploder
+	case BehaviorExploder:
 		if cfg.AI.ExplosionRadius > 0 {
 			ai.ExplosionRadius = cfg.AI.ExplosionRadius
 		} else {
@@ -141,21 +199,13 @@ func NewEnemyAI(cfg *config.EnemyConfig) *EnemyAI {
 		} else {
 			ai.ExplosionDamage = 50.0
 		}
-		if ai.AttackRange == 2.0 { // Still default
-			ai.AttackRange = 1.0 // Explode on contact
-		}
-	case "support":
-		ai.Behavior = BehaviorSupport
+	case BehaviorSupport:
 		if cfg.AI.SupportRange > 0 {
 			ai.SupportRange = cfg.AI.SupportRange
 		} else {
 			ai.SupportRange = 10.0
 		}
-		if ai.AttackRange == 2.0 { // Still default
-			ai.AttackRange = 15.0 // Keep distance from players
-		}
-	case "summoner":
-		ai.Behavior = BehaviorSummoner
+	case BehaviorSummoner:
 		if cfg.AI.SummonCooldown > 0 {
 			ai.SummonCooldown = cfg.AI.SummonCooldown
 		} else {
@@ -166,9 +216,6 @@ func NewEnemyAI(cfg *config.EnemyConfig) *EnemyAI {
 		} else {
 			ai.MaxSummons = 3
 		}
-		if ai.AttackRange == 2.0 { // Still default
-			ai.AttackRange = 20.0 // Keep far from players
-		}
 	}
 
 	// Apply chase speed if specified
@@ -178,6 +225,11 @@ func NewEnemyAI(cfg *config.EnemyConfig) *EnemyAI {
 		ai.ChaseSpeed = ai.MoveSpeed
 	}
 
+	// A behavior tree from enemies.json takes over from the built-in behavior
+	if cfg.AI.Behavior != "" {
+		ai.Tree = loadBehaviorTree(cfg.AI.Behavior)
+	}
+
 	return ai
 }
 
@@ -187,16 +239,37 @@ func (ai *EnemyAI) Update(enemy *Enemy, ctx *EnemyAIContext) *EnemyAttackResult
 		return nil
 	}
 
+	// Leashed enemies head home before doing anything else
+	if ai.State == AIStateReturn {
+		return ai.executeReturn(enemy, ctx)
+	}
+
 	// Update rage mode
 	ai.checkRageMode(enemy)
 
-	// Find target if we don't have one or current target is invalid
-	if ai.TargetID == "" || !ai.isTargetValid(ctx) {
-		ai.findTarget(enemy, ctx)
+	// Re-evaluate the target from the threat table every tick
+	ai.Threat.Decay(ctx.DeltaSeconds, threatSettings().DecayPerSecond)
+	ai.findTarget(enemy, ctx)
+
+	// Update state based on current situation. Trees pick their own state.
+	if ai.Tree == nil {
+		ai.updateState(enemy, ctx)
 	}
 
-	// Update state based on current situation
-	ai.updateState(enemy, ctx)
+	// Give up once pulled too far from home
+	if ai.beyondLeash(enemy) {
+		ai.startReturn(enemy)
+		return ai.executeReturn(enemy, ctx)
+	}
+
+	// Bosses run their encounter script alongside their basic attacks
+	if ai.Boss != nil {
+		ai.Boss.update(enemy, ai, ctx, ai.now())
+	}
+
+	if ai.Tree != nil {
+		return ai.runBehaviorTree(enemy, ctx)
+	}
 
 	// Execute behavior based on current state
 	return ai.executeBehavior(enemy, ctx)
@@ -216,49 +289,92 @@ func (ai *EnemyAI) checkRageMode(enemy *Enemy) {
 
 // isTargetValid checks if the current target is still valid
 func (ai *EnemyAI) isTargetValid(ctx *EnemyAIContext) bool {
-	if ai.TargetID == "" {
+	return ai.isValidTarget(ai.TargetID, ctx)
+}
+
+// isValidTarget returns true if the ID is a living player or an existing minion
+func (ai *EnemyAI) isValidTarget(id string, ctx *EnemyAIContext) bool {
+	if id == "" {
 		return false
 	}
-	player, exists := ctx.Players[ai.TargetID]
-	if !exists {
-		return false
+	if player, exists := ctx.Players[id]; exists {
+		// Check if player is alive (health > 0)
+		return player.Health > 0
 	}
-	// Check if player is alive (health > 0)
-	return player.Health > 0
+	_, exists := ctx.Minions[id]
+	return exists
 }
 
-// findTarget finds the best target for this enemy
+// targetPosition returns the current position of the target player or minion
+func (ai *EnemyAI) targetPosition(ctx *EnemyAIContext) (Vector3, bool) {
+	if player, exists := ctx.Players[ai.TargetID]; exists {
+		return player.Position, true
+	}
+	if minion, exists := ctx.Minions[ai.TargetID]; exists {
+		return minion.Position, true
+	}
+	return Vector3{}, false
+}
+
+// findTarget picks the highest-threat player or minion. Anything in aggro range
+// gets baseline threat that is higher the closer it is, so with no damage dealt
+// the nearest target wins. A rival only takes over from a valid current target
+// once its threat exceeds the current target's by ThreatSwitchRatio.
 func (ai *EnemyAI) findTarget(enemy *Enemy, ctx *EnemyAIContext) {
-	if len(ctx.Players) == 0 {
+	settings := threatSettings()
+	proximity := settings.ProximityThreat
+	if ai.AggroRange > 0 {
+		for _, player := range ctx.Players {
+			if player.Health <= 0 {
+				continue
+			}
+			if distance := Distance2D(enemy.Position, player.Position); distance < ai.AggroRange {
+				ai.raiseProximityThreat(enemy, player.ID, player.Position, proximity*(1-distance/ai.AggroRange), false, ctx)
+			}
+		}
+		for _, minion := range ctx.Minions {
+			if distance := Distance2D(enemy.Position, minion.Position); distance < ai.AggroRange {
+				amount := proximity * settings.MinionMultiplier * (1 - distance/ai.AggroRange)
+				ai.raiseProximityThreat(enemy, minion.ID, minion.Position, amount, true, ctx)
+			}
+		}
+	}
+
+	// Forget dead players and expired minions
+	for _, entry := range ai.Threat.Entries() {
+		if !ai.isValidTarget(entry.ID, ctx) {
+			ai.Threat.Remove(entry.ID)
+		}
+	}
+
+	top := ai.Threat.Top()
+	if top == nil {
 		ai.TargetID = ""
 		return
 	}
 
-	// Find closest player within aggro range
-	var closestPlayer *Player
-	minDistance := ai.AggroRange
-
-	for _, player := range ctx.Players {
-		if player.Health <= 0 {
-			continue
+	if top.ID != ai.TargetID && ai.isTargetValid(ctx) && ai.Threat.Has(ai.TargetID) {
+		if top.Threat < ai.Threat.Get(ai.TargetID)*ai.ThreatSwitchRatio {
+			return // Not enough to pull aggro
 		}
+	}
 
-		distance := Distance2D(enemy.Position, player.Position)
-		if distance < minDistance {
-			minDistance = distance
-			closestPlayer = player
-		}
+	ai.TargetID = top.ID
+	if pos, ok := ai.targetPosition(ctx); ok {
+		ai.TargetPosition = pos
+	}
+	if ai.State == AIStateIdle {
+		ai.State = AIStateChase
 	}
+}
 
-	if closestPlayer != nil {
-		ai.TargetID = closestPlayer.ID
-		ai.TargetPosition = closestPlayer.Position
-		if ai.State == AIStateIdle {
-			ai.State = AIStateChase
-		}
-	} else {
-		ai.TargetID = ""
+// raiseProximityThreat gives a nearby player or minion baseline threat if the
+// enemy can see it. The sight check is skipped when it couldn't change anything.
+func (ai *EnemyAI) raiseProximityThreat(enemy *Enemy, id string, pos Vector3, amount float64, isMinion bool, ctx *EnemyAIContext) {
+	if ai.Threat.Get(id) >= amount || !ctx.hasLineOfSight(enemy.Position, pos) {
+		return
 	}
+	ai.Threat.Raise(id, amount, isMinion)
 }
 
 // updateState updates the AI state based on current situation
@@ -275,17 +391,17 @@ func (ai *EnemyAI) updateState(enemy *Enemy, ctx *EnemyAIContext) {
 		return
 	}
 
-	player, exists := ctx.Players[ai.TargetID]
+	targetPos, exists := ai.targetPosition(ctx)
 	if !exists {
 		ai.State = AIStateIdle
 		return
 	}
 
-	distance := Distance2D(enemy.Position, player.Position)
-	ai.TargetPosition = player.Position
+	distance := Distance2D(enemy.Position, targetPos)
+	ai.TargetPosition = targetPos
 
 	// Check flee condition
-	if ai.FleeHealth > 0 && enemy.Health/enemy.MaxHealth <= ai.FleeHealth {
+	if ai.shouldFlee(enemy) {
 		ai.State = AIStateFlee
 		return
 	}
@@ -304,6 +420,9 @@ func (ai *EnemyAI) updateState(enemy *Enemy, ctx *EnemyAIContext) {
 		// For support/summoner, don't enter attack state based on distance
 		if ai.Behavior == BehaviorSupport || ai.Behavior == BehaviorSummoner {
 			ai.State = AIStateSupport
+		} else if ai.Behavior == BehaviorRanged && !ctx.hasLineOfSight(enemy.Position, targetPos) {
+			// Close in until there's a clear shot
+			ai.State = AIStateChase
 		} else {
 			ai.State = AIStateAttack
 		}
@@ -314,6 +433,12 @@ func (ai *EnemyAI) updateState(enemy *Enemy, ctx *EnemyAIContext) {
 	}
 }
 
+// shouldFlee returns true if the enemy is hurt badly enough to run, or
+// panicking after its pack broke
+func (ai *EnemyAI) shouldFlee(enemy *Enemy) bool {
+	return (ai.FleeHealth > 0 && enemy.Health/enemy.MaxHealth <= ai.FleeHealth) || ai.now().Before(ai.FleeUntil)
+}
+
 // EnemyAttackResult contains the result of an enemy attack
 type EnemyAttackResult struct {
 	TargetID        string
@@ -332,6 +457,9 @@ type EnemyAttackResult struct {
 type SpawnEnemyRequest struct {
 	Type     string
 	Position Vector3
+	Level    int // Summons fight at their summoner's level
+
+	SummonerID string
 }
 
 // EnemyBuff describes a buff to apply
@@ -373,15 +501,21 @@ func (ai *EnemyAI) executeChase(enemy *Enemy, ctx *EnemyAIContext) *EnemyAttackR
 		return nil
 	}
 
-	player, exists := ctx.Players[ai.TargetID]
+	targetPos, exists := ai.targetPosition(ctx)
 	if !exists {
 		enemy.Velocity = Vector3{X: 0, Y: 0, Z: 0}
 		return nil
 	}
 
+	// Pack members close in on their own spot around the target
+	if !ai.IsCharging {
+		targetPos.X += ai.FlankOffset.X
+		targetPos.Z += ai.FlankOffset.Z
+	}
+
 	// Calculate direction to player
-	dx := player.Position.X - enemy.Position.X
-	dz := player.Position.Z - enemy.Position.Z
+	dx := targetPos.X - enemy.Position.X
+	dz := targetPos.Z - enemy.Position.Z
 	distance := math.Sqrt(dx*dx + dz*dz)
 
 	if distance <= 0.1 {
@@ -390,7 +524,7 @@ func (ai *EnemyAI) executeChase(enemy *Enemy, ctx *EnemyAIContext) *EnemyAttackR
 	}
 
 	// Normalize and apply speed
-	speed := ai.ChaseSpeed
+	speed := ai.ChaseSpeed * ai.packSpeedMultiplier()
 	if ai.RageMode {
 		speed *= ai.RageSpeedMult
 	}
@@ -409,10 +543,20 @@ func (ai *EnemyAI) executeChase(enemy *Enemy, ctx *EnemyAIContext) *EnemyAttackR
 		return nil
 	}
 
+	// Walk around obstacles rather than into them
+	direction := Vector3{X: dx / distance, Z: dz / distance}
+	if ai.IsCharging {
+		ai.Path = nil // Charges run straight
+	} else if steer := ai.steerTarget(enemy, ctx, targetPos); steer != targetPos {
+		if steerDistance := Distance2D(enemy.Position, steer); steerDistance > 0.01 {
+			direction = Vector3{X: (steer.X - enemy.Position.X) / steerDistance, Z: (steer.Z - enemy.Position.Z) / steerDistance}
+		}
+	}
+
 	enemy.Velocity = Vector3{
-		X: (dx / distance) * speed,
+		X: direction.X * speed,
 		Y: 0,
-		Z: (dz / distance) * speed,
+		Z: direction.Z * speed,
 	}
 
 	// Apply velocity to position
@@ -455,7 +599,7 @@ func (ai *EnemyAI) executeAttack(enemy *Enemy, ctx *EnemyAIContext) *EnemyAttack
 	}
 
 	// Check attack cooldown
-	if time.Since(ai.LastAttackTime).Seconds() < ai.AttackCooldown {
+	if !ai.attackReady() {
 		// Still on cooldown, but keep chasing if melee
 		if ai.Behavior == BehaviorMelee || ai.Behavior == BehaviorCharger {
 			return ai.executeChase(enemy, ctx)
@@ -463,83 +607,110 @@ func (ai *EnemyAI) executeAttack(enemy *Enemy, ctx *EnemyAIContext) *EnemyAttack
 		return nil
 	}
 
-	player, exists := ctx.Players[ai.TargetID]
-	if !exists {
+	if _, exists := ai.targetPosition(ctx); !exists {
 		return nil
 	}
 
-	// Calculate direction to player
-	dx := player.Position.X - enemy.Position.X
-	dz := player.Position.Z - enemy.Position.Z
-	distance := math.Sqrt(dx*dx + dz*dz)
-
-	if distance <= 0.1 {
-		distance = 0.1
-	}
-
-	direction := Vector3{
-		X: dx / distance,
-		Y: 0,
-		Z: dz / distance,
-	}
-
 	// Stop moving while attacking for ranged
 	if ai.Behavior != BehaviorMelee {
 		enemy.Velocity = Vector3{X: 0, Y: 0, Z: 0}
 	}
 
-	// Get base damage from config
-	cfg, ok := config.GetEnemyConfig(enemy.Type)
-	baseDamage := 10.0
-	if ok {
-		baseDamage = cfg.Damage
+	switch ai.Behavior {
+	case BehaviorMelee, BehaviorCharger:
+		return ai.meleeAttack(enemy, ctx)
+	case BehaviorRanged:
+		return ai.rangedAttack(enemy, ctx, DamageTypeFire) // Default to fire for ranged
+	case BehaviorExploder:
+		return ai.explode(enemy)
 	}
 
-	// Apply rage multiplier
-	if ai.RageMode {
-		baseDamage *= ai.RageDamageMult
+	return nil
+}
+
+// attackReady returns true once the attack cooldown has passed
+func (ai *EnemyAI) attackReady() bool {
+	return ai.now().Sub(ai.LastAttackTime).Seconds() >= ai.AttackCooldown
+}
+
+// attackDirection returns the direction from the enemy to its target
+func (ai *EnemyAI) attackDirection(enemy *Enemy, ctx *EnemyAIContext) (Vector3, bool) {
+	targetPos, exists := ai.targetPosition(ctx)
+	if !exists {
+		return Vector3{}, false
 	}
 
-	ai.LastAttackTime = time.Now()
+	dx := targetPos.X - enemy.Position.X
+	dz := targetPos.Z - enemy.Position.Z
+	distance := math.Sqrt(dx*dx + dz*dz)
+	if distance <= 0.1 {
+		distance = 0.1
+	}
+	return Vector3{X: dx / distance, Y: 0, Z: dz / distance}, true
+}
 
-	switch ai.Behavior {
-	case BehaviorMelee, BehaviorCharger:
-		// Melee attack - direct damage
-		if ai.IsCharging {
-			ai.IsCharging = false
-			baseDamage *= 1.5 // Charge bonus damage
-		}
-		return &EnemyAttackResult{
-			TargetID:   ai.TargetID,
-			Damage:     baseDamage,
-			DamageType: DamageTypePhysical,
-			Position:   enemy.Position,
-			Direction:  direction,
-		}
+// attackDamage returns the damage of one hit after rage and pack bonuses
+func (ai *EnemyAI) attackDamage(enemy *Enemy) float64 {
+	// Base damage comes from config, scaled for elites at spawn
+	damage := enemy.Damage
+	if damage <= 0 {
+		damage = 10.0
+	}
+	if ai.RageMode {
+		damage *= ai.RageDamageMult
+	}
+	return damage * ai.packDamageMultiplier()
+}
 
-	case BehaviorRanged:
-		// Ranged attack - spawn projectile
-		return &EnemyAttackResult{
-			TargetID:     ai.TargetID,
-			Damage:       baseDamage,
-			DamageType:   DamageTypeFire, // Default to fire for ranged
-			Position:     enemy.Position,
-			Direction:    direction,
-			IsProjectile: true,
-		}
+// meleeAttack hits the target directly. A charge that connects hits harder.
+func (ai *EnemyAI) meleeAttack(enemy *Enemy, ctx *EnemyAIContext) *EnemyAttackResult {
+	direction, ok := ai.attackDirection(enemy, ctx)
+	if !ok {
+		return nil
+	}
+	damage := ai.attackDamage(enemy)
+	if ai.IsCharging {
+		ai.IsCharging = false
+		damage *= 1.5 // Charge bonus damage
+	}
+	ai.LastAttackTime = ai.now()
+	return &EnemyAttackResult{
+		TargetID:   ai.TargetID,
+		Damage:     damage,
+		DamageType: enemy.attackDamageType(DamageTypePhysical),
+		Position:   enemy.Position,
+		Direction:  direction,
+	}
+}
 
-	case BehaviorExploder:
-		// Explode on contact
-		return &EnemyAttackResult{
-			Damage:          ai.ExplosionDamage,
-			DamageType:      DamageTypeFire,
-			Position:        enemy.Position,
-			IsExplosion:     true,
-			ExplosionRadius: ai.ExplosionRadius,
-		}
+// rangedAttack stops to fire a projectile at the target
+func (ai *EnemyAI) rangedAttack(enemy *Enemy, ctx *EnemyAIContext, damageType DamageType) *EnemyAttackResult {
+	direction, ok := ai.attackDirection(enemy, ctx)
+	if !ok {
+		return nil
 	}
+	enemy.Velocity = Vector3{X: 0, Y: 0, Z: 0}
+	ai.LastAttackTime = ai.now()
+	return &EnemyAttackResult{
+		TargetID:     ai.TargetID,
+		Damage:       ai.attackDamage(enemy),
+		DamageType:   enemy.attackDamageType(damageType),
+		Position:     enemy.Position,
+		Direction:    direction,
+		IsProjectile: true,
+	}
+}
 
-	return nil
+// explode blows the enemy up, damaging everything around it
+func (ai *EnemyAI) explode(enemy *Enemy) *EnemyAttackResult {
+	ai.LastAttackTime = ai.now()
+	return &EnemyAttackResult{
+		Damage:          ai.ExplosionDamage,
+		DamageType:      DamageTypeFire,
+		Position:        enemy.Position,
+		IsExplosion:     true,
+		ExplosionRadius: ai.ExplosionRadius,
+	}
 }
 
 // executeFlee moves the enemy away from the target
@@ -549,31 +720,25 @@ func (ai *EnemyAI) executeFlee(enemy *Enemy, ctx *EnemyAIContext) *EnemyAttackRe
 		return nil
 	}
 
-	player, exists := ctx.Players[ai.TargetID]
+	targetPos, exists := ai.targetPosition(ctx)
 	if !exists {
 		enemy.Velocity = Vector3{X: 0, Y: 0, Z: 0}
 		return nil
 	}
 
-	// Calculate direction away from player
-	dx := enemy.Position.X - player.Position.X
-	dz := enemy.Position.Z - player.Position.Z
-	distance := math.Sqrt(dx*dx + dz*dz)
-
-	if distance <= 0.1 {
-		distance = 0.1
-	}
+	// Run away from the target, veering around anything in the way
+	direction := ai.fleeDirection(enemy, ctx, targetPos)
 
 	// Apply speed (flee at chase speed)
-	speed := ai.ChaseSpeed
+	speed := ai.ChaseSpeed * ai.packSpeedMultiplier()
 	if ai.RageMode {
 		speed *= ai.RageSpeedMult
 	}
 
 	enemy.Velocity = Vector3{
-		X: (dx / distance) * speed,
+		X: direction.X * speed,
 		Y: 0,
-		Z: (dz / distance) * speed,
+		Z: direction.Z * speed,
 	}
 
 	// Apply velocity to position
@@ -591,81 +756,103 @@ func (ai *EnemyAI) executeSupport(enemy *Enemy, ctx *EnemyAIContext) *EnemyAttac
 		return nil
 	}
 
-	// Keep distance from players
-	if ai.TargetID != "" {
-		player, exists := ctx.Players[ai.TargetID]
-		if exists {
-			distance := Distance2D(enemy.Position, player.Position)
-
-			// If too close, back away
-			if distance < ai.AttackRange*0.7 {
-				dx := enemy.Position.X - player.Position.X
-				dz := enemy.Position.Z - player.Position.Z
-				dist := math.Sqrt(dx*dx + dz*dz)
-				if dist > 0.1 {
-					speed := ai.MoveSpeed
-					enemy.Velocity = Vector3{
-						X: (dx / dist) * speed,
-						Y: 0,
-						Z: (dz / dist) * speed,
-					}
-					enemy.Position.X += enemy.Velocity.X * ctx.DeltaSeconds
-					enemy.Position.Z += enemy.Velocity.Z * ctx.DeltaSeconds
-				}
-			} else {
-				enemy.Velocity = Vector3{X: 0, Y: 0, Z: 0}
-			}
-		}
-	}
+	ai.keepDistance(enemy, ctx)
 
 	// Check attack/support cooldown
-	if time.Since(ai.LastAttackTime).Seconds() < ai.AttackCooldown {
+	if !ai.attackReady() {
 		return nil
 	}
 
 	switch ai.Behavior {
 	case BehaviorSupport:
-		// Buff nearby allies
-		ai.LastAttackTime = time.Now()
-		return &EnemyAttackResult{
-			Position: enemy.Position,
-			ApplyBuff: &EnemyBuff{
-				DamageMult: 1.25,
-				SpeedMult:  1.15,
-				Duration:   5.0,
-				Radius:     ai.SupportRange,
-			},
-		}
-
+		return ai.buffAllies(enemy)
 	case BehaviorSummoner:
-		// Check summon cooldown and count
-		if time.Since(ai.LastSummonTime).Seconds() < ai.SummonCooldown {
-			return nil
-		}
-		if ai.CurrentSummons >= ai.MaxSummons {
-			return nil
-		}
+		return ai.summon(enemy, defaultSummonType)
+	}
+
+	return nil
+}
+
+// defaultSummonType is what summoners raise unless their behavior tree says otherwise
+const defaultSummonType = "skeleton"
+
+// keepDistance backs away from a target that gets closer than 70% of the
+// attack range, and otherwise stands still
+func (ai *EnemyAI) keepDistance(enemy *Enemy, ctx *EnemyAIContext) {
+	if ai.TargetID == "" {
+		return
+	}
+	targetPos, exists := ai.targetPosition(ctx)
+	if !exists {
+		return
+	}
 
-		ai.LastSummonTime = time.Now()
-		ai.CurrentSummons++
+	distance := Distance2D(enemy.Position, targetPos)
+	if distance >= ai.AttackRange*0.7 {
+		enemy.Velocity = Vector3{X: 0, Y: 0, Z: 0}
+		return
+	}
 
-		// Spawn minion near the summoner
-		angle := float64(time.Now().UnixNano()%628) / 100.0 // Random angle
-		spawnPos := Vector3{
-			X: enemy.Position.X + math.Cos(angle)*2.0,
+	// Too close, back away
+	dx := enemy.Position.X - targetPos.X
+	dz := enemy.Position.Z - targetPos.Z
+	dist := math.Sqrt(dx*dx + dz*dz)
+	if dist > 0.1 {
+		speed := ai.MoveSpeed
+		enemy.Velocity = Vector3{
+			X: (dx / dist) * speed,
 			Y: 0,
-			Z: enemy.Position.Z + math.Sin(angle)*2.0,
+			Z: (dz / dist) * speed,
 		}
+		enemy.Position.X += enemy.Velocity.X * ctx.DeltaSeconds
+		enemy.Position.Z += enemy.Velocity.Z * ctx.DeltaSeconds
+	}
+}
 
-		return &EnemyAttackResult{
-			Position: enemy.Position,
-			SpawnEnemies: []SpawnEnemyRequest{
-				{Type: "skeleton", Position: spawnPos},
-			},
-		}
+// buffAllies empowers allies around the enemy
+func (ai *EnemyAI) buffAllies(enemy *Enemy) *EnemyAttackResult {
+	ai.LastAttackTime = ai.now()
+	return &EnemyAttackResult{
+		Position: enemy.Position,
+		ApplyBuff: &EnemyBuff{
+			DamageMult: 1.25,
+			SpeedMult:  1.15,
+			Duration:   5.0,
+			Radius:     ai.SupportRange,
+		},
+	}
+}
+
+// summon raises a minion next to the enemy, if the summon cooldown has
+// passed and it has room for more
+func (ai *EnemyAI) summon(enemy *Enemy, enemyType string) *EnemyAttackResult {
+	if !ai.summonReady() {
+		return nil
 	}
 
-	return nil
+	ai.LastSummonTime = ai.now()
+	ai.CurrentSummons++
+
+	// Spawn minion near the summoner
+	angle := rand.Float64() * 2 * math.Pi
+	spawnPos := Vector3{
+		X: enemy.Position.X + math.Cos(angle)*2.0,
+		Y: 0,
+		Z: enemy.Position.Z + math.Sin(angle)*2.0,
+	}
+
+	return &EnemyAttackResult{
+		Position: enemy.Position,
+		SpawnEnemies: []SpawnEnemyRequest{
+			{Type: enemyType, Position: spawnPos, Level: enemy.Level, SummonerID: enemy.ID},
+		},
+	}
+}
+
+// summonReady returns true if the summon cooldown has passed and the enemy
+// has room for another summon
+func (ai *EnemyAI) summonReady() bool {
+	return ai.now().Sub(ai.LastSummonTime).Seconds() >= ai.SummonCooldown && ai.CurrentSummons < ai.MaxSummons
 }
 
 // GetDirectionTo returns a normalized direction vector to a target position
@@ -3,6 +3,7 @@ package game
 import (
 	"encoding/json"
 	"log"
+	"math"
 	"time"
 
 	"github.com/PersonThing/cs-crawler/server/internal/config"
@@ -24,14 +25,19 @@ type Player struct {
 	Rotation  float64 // Y-axis rotation in radians
 	Health    float64
 	MaxHealth float64
+	Mana      float64
+	MaxMana   float64
 
 	// Base Stats (before item bonuses)
 	BaseMaxHealth float64
+	BaseMaxMana   float64
+	BaseManaRegen float64
 	BaseMoveSpeed float64
 	BaseDamage    float64
 	BaseArmor     float64
 
 	// Current Stats (after item bonuses)
+	ManaRegen       float64 // Mana per second
 	MoveSpeed       float64
 	Damage          float64
 	AttackSpeed     float64
@@ -45,6 +51,13 @@ type Player struct {
 	ColdResist      float64
 	LightningResist float64
 
+	// Temporary bonuses from self-buff abilities
+	Buffs []*PlayerBuff
+
+	// Active dash or leap; overrides input velocity until complete
+	Movement *ForcedMovement
+	landed   *ForcedMovement
+
 	// Dungeon visibility
 	LightRadius float64 // How far the player can see in dungeons
 
@@ -56,15 +69,35 @@ type Player struct {
 
 	// Character AI (autonomous combat)
 	CharAI     *CharacterAI
-	AutoCombat bool // When true, character AI handles combat
+	AutoCombat bool    // When true, character AI handles combat
+	AIVelocity Vector3 // Character AI footwork, used while the player isn't moving
 
 	// Tile tracking
 	CurrentTile HexCoord
 
+	// Progression
+	Experience float64
+
+	// Death state (see death.go)
+	DiedAt       time.Time      // Zero while alive
+	RespawnAt    time.Time      // Earliest time a dead player may respawn
+	recentDamage []DamageRecord // Hits taken recently, for the death recap
+
+	PotionCooldownUntil time.Time // Potions share one cooldown
+
 	// State
+	simTime    // Timers run on the world clock once the player joins one
 	LastUpdate time.Time
 }
 
+// PlayerBuff is an active temporary stat bonus on a player
+type PlayerBuff struct {
+	Source    AbilityType
+	Stat      StatType
+	Amount    float64
+	ExpiresAt time.Time
+}
+
 // NewPlayer creates a new player
 func NewPlayer(id, username string) *Player {
 	// Load player stats from config
@@ -77,7 +110,11 @@ func NewPlayer(id, username string) *Player {
 		Velocity:      Vector3{X: 0, Y: 0, Z: 0},
 		Health:        stats.Health,
 		MaxHealth:     stats.MaxHealth,
+		Mana:          stats.Mana,
+		MaxMana:       stats.MaxMana,
 		BaseMaxHealth: stats.MaxHealth,
+		BaseMaxMana:   stats.MaxMana,
+		BaseManaRegen: stats.ManaRegen,
 		BaseMoveSpeed: stats.MoveSpeed,
 		BaseDamage:    10.0, // Default base damage
 		BaseArmor:     0.0,
@@ -96,12 +133,56 @@ func NewPlayer(id, username string) *Player {
 
 // Update processes player logic
 func (p *Player) Update(delta float64) {
-	// Update position based on velocity and move speed
-	p.Position.X += p.Velocity.X * p.MoveSpeed * delta
-	p.Position.Y += p.Velocity.Y * p.MoveSpeed * delta
-	p.Position.Z += p.Velocity.Z * p.MoveSpeed * delta
+	if p.IsDead() {
+		// The dead can't move or act until they respawn
+		p.Velocity = Vector3{}
+		p.AIVelocity = Vector3{}
+		p.Movement = nil
+		p.landed = nil
+		p.LastUpdate = p.now()
+		return
+	}
 
-	p.LastUpdate = time.Now()
+	now := p.now()
+	if p.Movement != nil {
+		// Movement abilities take over positioning until they finish
+		p.Position = p.Movement.Position(now)
+		if p.Movement.Progress(now) >= 1 {
+			p.landed = p.Movement
+			p.Movement = nil
+		}
+	} else {
+		// Update position based on velocity and move speed. The player's
+		// input always wins over the character AI's footwork.
+		velocity := p.Velocity
+		if velocity == (Vector3{}) {
+			velocity = p.AIVelocity
+		}
+		p.Position.X += velocity.X * p.MoveSpeed * delta
+		p.Position.Y += velocity.Y * p.MoveSpeed * delta
+		p.Position.Z += velocity.Z * p.MoveSpeed * delta
+	}
+
+	if p.expireBuffs() {
+		p.RecalculateStats()
+	}
+
+	// Regenerate mana while alive
+	if !p.IsDead() && p.Mana < p.MaxMana {
+		p.Mana += p.ManaRegen * delta
+		if p.Mana > p.MaxMana {
+			p.Mana = p.MaxMana
+		}
+	}
+
+	p.LastUpdate = now
+}
+
+// ConsumeLanding returns the movement that finished during the last update, if any
+func (p *Player) ConsumeLanding() *ForcedMovement {
+	landed := p.landed
+	p.landed = nil
+	return landed
 }
 
 // IsDead returns true if the player has no health
@@ -109,8 +190,35 @@ func (p *Player) IsDead() bool {
 	return p.Health <= 0
 }
 
-// SetVelocity updates player velocity (normalized by client)
+// TakeDamage applies damage to the player and returns true if it died
+func (p *Player) TakeDamage(damage DamageInfo) bool {
+	if p.IsDead() {
+		return false
+	}
+
+	p.Health -= damage.Amount
+	if p.Health <= 0 {
+		p.Health = 0
+		return true
+	}
+	return false
+}
+
+// GetHealth returns current health
+func (p *Player) GetHealth() float64 {
+	return p.Health
+}
+
+// GetMaxHealth returns maximum health
+func (p *Player) GetMaxHealth() float64 {
+	return p.MaxHealth
+}
+
+// SetVelocity updates player velocity (normalized by client). Ignored while dead.
 func (p *Player) SetVelocity(v Vector3) {
+	if p.IsDead() {
+		return
+	}
 	p.Velocity = v
 }
 
@@ -118,6 +226,8 @@ func (p *Player) SetVelocity(v Vector3) {
 func (p *Player) RecalculateStats() {
 	// Start with base stats
 	p.MaxHealth = p.BaseMaxHealth
+	p.MaxMana = p.BaseMaxMana
+	p.ManaRegen = p.BaseManaRegen
 	p.MoveSpeed = p.BaseMoveSpeed
 	p.Damage = p.BaseDamage
 	p.Armor = p.BaseArmor
@@ -138,41 +248,124 @@ func (p *Player) RecalculateStats() {
 
 		// Apply item bonuses
 		for stat, value := range itemStats {
-			switch stat {
-			case StatHealth:
-				p.MaxHealth += value
-			case StatDamage:
-				p.Damage += value
-			case StatMoveSpeed:
-				p.MoveSpeed += value
-			case StatAttackSpeed:
-				p.AttackSpeed += value
-			case StatCritChance:
-				p.CritChance += value
-			case StatCritDamage:
-				p.CritDamage += value
-			case StatFireDamage:
-				p.FireDamage += value
-			case StatColdDamage:
-				p.ColdDamage += value
-			case StatLightningDamage:
-				p.LightningDamage += value
-			case StatArmor:
-				p.Armor += value
-			case StatFireResist:
-				p.FireResist += value
-			case StatColdResist:
-				p.ColdResist += value
-			case StatLightningResist:
-				p.LightningResist += value
-			}
+			p.addStat(stat, value)
 		}
 	}
 
+	// Apply active ability buffs
+	for _, buff := range p.Buffs {
+		p.addStat(buff.Stat, buff.Amount)
+	}
+
 	// Ensure health doesn't exceed max after stat changes
 	if p.Health > p.MaxHealth {
 		p.Health = p.MaxHealth
 	}
+	if p.Mana > p.MaxMana {
+		p.Mana = p.MaxMana
+	}
+}
+
+// addStat adds a bonus to the current value of a stat
+func (p *Player) addStat(stat StatType, value float64) {
+	switch stat {
+	case StatHealth:
+		p.MaxHealth += value
+	case StatDamage:
+		p.Damage += value
+	case StatMoveSpeed:
+		p.MoveSpeed += value
+	case StatAttackSpeed:
+		p.AttackSpeed += value
+	case StatCritChance:
+		p.CritChance += value
+	case StatCritDamage:
+		p.CritDamage += value
+	case StatFireDamage:
+		p.FireDamage += value
+	case StatColdDamage:
+		p.ColdDamage += value
+	case StatLightningDamage:
+		p.LightningDamage += value
+	case StatArmor:
+		p.Armor += value
+	case StatFireResist:
+		p.FireResist += value
+	case StatColdResist:
+		p.ColdResist += value
+	case StatLightningResist:
+		p.LightningResist += value
+	case StatMana:
+		p.MaxMana += value
+	case StatManaRegen:
+		p.ManaRegen += value
+	}
+}
+
+// GetStat returns the current value of a stat
+func (p *Player) GetStat(stat StatType) float64 {
+	switch stat {
+	case StatHealth:
+		return p.MaxHealth
+	case StatDamage:
+		return p.Damage
+	case StatMoveSpeed:
+		return p.MoveSpeed
+	case StatAttackSpeed:
+		return p.AttackSpeed
+	case StatCritChance:
+		return p.CritChance
+	case StatCritDamage:
+		return p.CritDamage
+	case StatFireDamage:
+		return p.FireDamage
+	case StatColdDamage:
+		return p.ColdDamage
+	case StatLightningDamage:
+		return p.LightningDamage
+	case StatArmor:
+		return p.Armor
+	case StatFireResist:
+		return p.FireResist
+	case StatColdResist:
+		return p.ColdResist
+	case StatLightningResist:
+		return p.LightningResist
+	case StatMana:
+		return p.MaxMana
+	case StatManaRegen:
+		return p.ManaRegen
+	}
+	return 0
+}
+
+// ApplyBuff adds a temporary stat bonus and recalculates stats
+func (p *Player) ApplyBuff(buff *AbilityBuff, source AbilityType) {
+	p.Buffs = append(p.Buffs, &PlayerBuff{
+		Source:    source,
+		Stat:      buff.Stat,
+		Amount:    buff.Amount,
+		ExpiresAt: p.now().Add(time.Duration(buff.Duration * float64(time.Second))),
+	})
+	p.RecalculateStats()
+}
+
+// expireBuffs removes finished buffs, returning true if any were removed
+func (p *Player) expireBuffs() bool {
+	if len(p.Buffs) == 0 {
+		return false
+	}
+
+	now := p.now()
+	active := p.Buffs[:0]
+	for _, buff := range p.Buffs {
+		if now.Before(buff.ExpiresAt) {
+			active = append(active, buff)
+		}
+	}
+	expired := len(active) != len(p.Buffs)
+	p.Buffs = active
+	return expired
 }
 
 // EquipItem equips an item and recalculates stats
@@ -214,14 +407,19 @@ func (p *Player) UnequipSlot(slot EquipmentSlot) (*Item, error) {
 // Serialize converts player to JSON-friendly format
 func (p *Player) Serialize() map[string]interface{} {
 	result := map[string]interface{}{
-		"id":        p.ID,
-		"username":  p.Username,
-		"position":  p.Position,
-		"velocity":  p.Velocity,
-		"rotation":  p.Rotation,
-		"health":    p.Health,
-		"maxHealth": p.MaxHealth,
+		"id":         p.ID,
+		"username":   p.Username,
+		"position":   p.Position,
+		"velocity":   p.Velocity,
+		"rotation":   p.Rotation,
+		"health":     p.Health,
+		"maxHealth":  p.MaxHealth,
+		"mana":       p.Mana,
+		"maxMana":    p.MaxMana,
+		"experience": p.Experience,
+		"dead":       p.IsDead(),
 		"stats": map[string]interface{}{
+			"manaRegen":       p.ManaRegen,
 			"moveSpeed":       p.MoveSpeed,
 			"damage":          p.Damage,
 			"attackSpeed":     p.AttackSpeed,
@@ -238,6 +436,23 @@ func (p *Player) Serialize() map[string]interface{} {
 		},
 	}
 
+	if p.IsDead() {
+		result["respawnIn"] = math.Max(p.RespawnAt.Sub(p.now()).Seconds(), 0)
+	}
+
+	if len(p.Buffs) > 0 {
+		buffs := make([]map[string]interface{}, 0, len(p.Buffs))
+		for _, buff := range p.Buffs {
+			buffs = append(buffs, map[string]interface{}{
+				"source":    string(buff.Source),
+				"stat":      string(buff.Stat),
+				"amount":    buff.Amount,
+				"remaining": buff.ExpiresAt.Sub(p.now()).Seconds(),
+			})
+		}
+		result["buffs"] = buffs
+	}
+
 	if p.Inventory != nil {
 		result["inventory"] = p.Inventory.Serialize()
 	}
@@ -246,6 +461,9 @@ func (p *Player) Serialize() map[string]interface{} {
 		result["characterAI"] = p.CharAI.Serialize()
 		result["autoCombat"] = p.AutoCombat
 	}
+	if p.AIVelocity != (Vector3{}) {
+		result["aiVelocity"] = p.AIVelocity
+	}
 
 	return result
 }
@@ -274,14 +492,16 @@ func (p *Player) ToSaveData() (equippedJSON, bagsJSON json.RawMessage, err error
 }
 
 // RestoreFromSave restores player state from database data
-func (p *Player) RestoreFromSave(posX, posY, posZ, rotation, health float64, equippedJSON, bagsJSON json.RawMessage) {
+func (p *Player) RestoreFromSave(posX, posY, posZ, rotation, health, mana float64, equippedJSON, bagsJSON json.RawMessage) {
 	p.Position = Vector3{X: posX, Y: posY, Z: posZ}
 	p.Rotation = rotation
-	// Reset health to max if player was dead (respawn on reconnect)
+	// Reset health and mana to max if player was dead (respawn on reconnect)
 	if health <= 0 {
 		p.Health = p.MaxHealth
+		p.Mana = p.MaxMana
 	} else {
 		p.Health = health
+		p.Mana = mana
 	}
 
 	// Deserialize inventory
@@ -300,10 +520,27 @@ func (p *Player) RestoreFromSave(posX, posY, posZ, rotation, health float64, equ
 	p.Inventory = DeserializeInventory(equippedData, bagsData)
 	p.RecalculateStats()
 
-	// Clamp health to max after recalculating stats
+	// Clamp health and mana to max after recalculating stats
 	if p.Health > p.MaxHealth {
 		p.Health = p.MaxHealth
 	}
+	if p.Mana > p.MaxMana {
+		p.Mana = p.MaxMana
+	}
+}
+
+// LoadCharacterAI restores the character's AI from its save, or rolls a
+// personality for a character that doesn't have one saved yet
+func (p *Player) LoadCharacterAI(saved json.RawMessage) {
+	if p.CharAI == nil {
+		p.CharAI = NewCharacterAI()
+	}
+	if p.CharAI.RestoreFromSave(saved) {
+		return
+	}
+	p.CharAI.RollPersonality()
+	log.Printf("[AI] Rolled personality for %s: aggression=%.2f preference=%s",
+		p.Username, p.CharAI.Aggression, p.CharAI.Preference)
 }
 
 // Enemy represents an enemy entity
@@ -323,6 +560,9 @@ type Enemy struct {
 	Damage      float64
 	AttackRange float64
 
+	// Monster level, which scales stats, experience and loot (see monster_level.go)
+	Level int
+
 	// Status effects
 	StatusEffects map[StatusEffectType]*StatusEffect
 
@@ -331,6 +571,22 @@ type Enemy struct {
 	SpeedBuff      float64 // Multiplier (1.0 = no buff)
 	BuffExpireTime time.Time
 
+	// Elite packs (see elite.go)
+	Rank           EliteRank
+	Affixes        []EliteAffix
+	Shield         float64 // Absorbs damage before health
+	MaxShield      float64
+	lastDamagedAt  time.Time
+	lastTeleportAt time.Time
+
+	// Tile whose population this enemy belongs to; nil for adds and summons
+	HomeTile *HexCoord
+
+	// Enemy that summoned this one (see summon.go)
+	SummonerID string
+	crumbleAt  time.Time // When a berserk summon whose summoner died falls apart
+
+	simTime    // Timers run on the world clock once the enemy is added to one
 	LastUpdate time.Time
 }
 
@@ -351,7 +607,7 @@ func NewEnemy(id, enemyType string, position Vector3) *Enemy {
 		}
 	}
 
-	return &Enemy{
+	enemy := &Enemy{
 		ID:            id,
 		Type:          enemyType,
 		Position:      position,
@@ -365,26 +621,23 @@ func NewEnemy(id, enemyType string, position Vector3) *Enemy {
 		StatusEffects: make(map[StatusEffectType]*StatusEffect),
 		DamageBuff:    1.0,
 		SpeedBuff:     1.0,
+		Level:         1,
 		LastUpdate:    time.Now(),
 	}
-}
 
-// Update processes enemy AI and movement
-func (e *Enemy) Update(delta float64) {
-	// Update status effects - remove expired ones
-	for effectType, effect := range e.StatusEffects {
-		if effect.IsExpired() {
-			delete(e.StatusEffects, effectType)
-		}
-	}
+	// Enemies pulled too far from where they spawned give up and walk back
+	enemy.AI.setHome(position, cfg.AI.LeashRadius)
 
-	// Update buffs
-	if time.Now().After(e.BuffExpireTime) {
-		e.DamageBuff = 1.0
-		e.SpeedBuff = 1.0
+	if bossCfg, ok := config.GetBossConfig(enemyType); ok {
+		enemy.AI.Boss = NewBossEncounter(enemyType, bossCfg, enemy)
 	}
 
-	e.LastUpdate = time.Now()
+	return enemy
+}
+
+// Update processes enemy AI and movement
+func (e *Enemy) Update(delta float64) {
+	e.expireEffects(e.now())
 }
 
 // UpdateAI processes enemy AI with context (called from World.Update)
@@ -394,29 +647,33 @@ func (e *Enemy) UpdateAI(ctx *EnemyAIContext) *EnemyAttackResult {
 	}
 
 	// Update status effects first
+	e.expireEffects(e.now())
+
+	// Run AI
+	return e.AI.Update(e, ctx)
+}
+
+// expireEffects removes finished status effects and ally buffs
+func (e *Enemy) expireEffects(now time.Time) {
 	for effectType, effect := range e.StatusEffects {
-		if effect.IsExpired() {
+		if effect.IsExpired(now) {
 			delete(e.StatusEffects, effectType)
 		}
 	}
 
-	// Update buffs
-	if time.Now().After(e.BuffExpireTime) {
+	if now.After(e.BuffExpireTime) {
 		e.DamageBuff = 1.0
 		e.SpeedBuff = 1.0
 	}
 
-	e.LastUpdate = time.Now()
-
-	// Run AI
-	return e.AI.Update(e, ctx)
+	e.LastUpdate = now
 }
 
 // ApplyBuff applies a buff to the enemy
 func (e *Enemy) ApplyBuff(damageMult, speedMult, duration float64) {
 	e.DamageBuff = damageMult
 	e.SpeedBuff = speedMult
-	e.BuffExpireTime = time.Now().Add(time.Duration(duration * float64(time.Second)))
+	e.BuffExpireTime = e.now().Add(time.Duration(duration * float64(time.Second)))
 }
 
 // TakeDamage applies damage to the enemy and returns true if it died
@@ -425,7 +682,7 @@ func (e *Enemy) TakeDamage(damage DamageInfo) bool {
 		return false
 	}
 
-	e.Health -= damage.Amount
+	e.Health -= e.absorbWithShield(damage.Amount)
 	if e.Health <= 0 {
 		e.Health = 0
 		e.Dead = true
@@ -452,6 +709,7 @@ func (e *Enemy) IsDead() bool {
 // ApplyStatusEffect applies a status effect to the enemy
 func (e *Enemy) ApplyStatusEffect(effect *StatusEffect) {
 	// Replace existing effect of the same type
+	effect.AppliedAt = e.now()
 	e.StatusEffects[effect.Type] = effect
 }
 
@@ -461,7 +719,7 @@ func (e *Enemy) HasStatusEffect(effectType StatusEffectType) bool {
 	if !exists {
 		return false
 	}
-	return !effect.IsExpired()
+	return !effect.IsExpired(e.now())
 }
 
 // GetStatusEffect returns a specific status effect if it exists and is not expired
@@ -475,10 +733,11 @@ func (e *Enemy) GetStatusEffect(effectType StatusEffectType) *StatusEffect {
 // Serialize converts enemy to JSON-friendly format
 func (e *Enemy) Serialize() map[string]interface{} {
 	// Serialize active status effects
+	now := e.now()
 	activeEffects := make([]map[string]interface{}, 0)
 	for _, effect := range e.StatusEffects {
-		if !effect.IsExpired() {
-			activeEffects = append(activeEffects, effect.Serialize())
+		if !effect.IsExpired(now) {
+			activeEffects = append(activeEffects, effect.Serialize(now))
 		}
 	}
 
@@ -490,15 +749,27 @@ func (e *Enemy) Serialize() map[string]interface{} {
 		"health":        e.Health,
 		"maxHealth":     e.MaxHealth,
 		"dead":          e.Dead,
+		"level":         e.Level,
 		"statusEffects": activeEffects,
 	}
 
+	if e.SummonerID != "" {
+		result["summonerID"] = e.SummonerID
+	}
+
 	// Add AI state if available
 	if e.AI != nil {
+		if e.AI.PackID != "" {
+			result["packID"] = e.AI.PackID
+			result["isPackLeader"] = e.AI.IsPackLeader
+		}
 		result["aiState"] = string(e.AI.State)
 		result["targetID"] = e.AI.TargetID
 		result["isRaging"] = e.AI.RageMode
 		result["isCharging"] = e.AI.IsCharging
+		if config.Server.Debug.SerializeThreat {
+			result["threat"] = e.AI.Threat.Serialize()
+		}
 	}
 
 	// Add buff info if active
@@ -506,6 +777,8 @@ func (e *Enemy) Serialize() map[string]interface{} {
 		result["isBuffed"] = true
 	}
 
+	e.serializeElite(result)
+
 	return result
 }
 
@@ -513,8 +786,9 @@ func (e *Enemy) Serialize() map[string]interface{} {
 type Projectile struct {
 	ID               string
 	OwnerID          string
-	Position         Vector3
+	Position         Vector3 // Y is the flight height, not the layer
 	Velocity         Vector3
+	Layer            int // Layer it was fired on
 	Damage           float64
 	DamageType       DamageType
 	Speed            float64
@@ -534,6 +808,7 @@ type Projectile struct {
 
 	// Enemy projectile support
 	IsEnemyProjectile bool     // If true, this projectile damages players instead of enemies
+	CasterID          string   // Player or minion that fired it; OwnerID is the credited player
 	HitPlayers        []string // Track which players have been hit (for piercing enemy projectiles)
 }
 
@@ -569,6 +844,7 @@ func NewEnemyProjectile(id, ownerID string, position, direction Vector3, damage
 		OwnerID:           ownerID,
 		Position:          Vector3{X: position.X, Y: 0.5, Z: position.Z}, // Spawn at consistent height
 		Velocity:          Vector3{X: direction.X * speed, Y: 0, Z: direction.Z * speed},
+		Layer:             layerFromY(position.Y),
 		Damage:            damage,
 		DamageType:        damageType,
 		Speed:             speed,
@@ -642,6 +918,11 @@ func (p *Projectile) HasHitEnemy(enemyID string) bool {
 	return false
 }
 
+// FromMinion returns true if a minion fired this projectile on its owner's behalf
+func (p *Projectile) FromMinion() bool {
+	return p.CasterID != "" && p.CasterID != p.OwnerID
+}
+
 // MarkEnemyHit marks an enemy as hit by this projectile
 func (p *Projectile) MarkEnemyHit(enemyID string) {
 	p.HitEnemies = append(p.HitEnemies, enemyID)
@@ -659,9 +940,9 @@ func (p *Projectile) CanPierce() bool {
 	return p.PierceCount < p.MaxPierces
 }
 
-// ShouldDestroy returns true if projectile should be removed
-func (p *Projectile) ShouldDestroy() bool {
-	return time.Since(p.CreatedAt).Seconds() > p.Lifetime
+// ShouldDestroy returns true if projectile should be removed by the given time
+func (p *Projectile) ShouldDestroy(now time.Time) bool {
+	return now.Sub(p.CreatedAt).Seconds() > p.Lifetime
 }
 
 // Serialize converts projectile to JSON-friendly format
@@ -32,6 +32,37 @@ func TestPlayerUpdate(t *testing.T) {
 	assert.Equal(t, 25.0, player.Position.Z)
 }
 
+func TestPlayerManaRegen(t *testing.T) {
+	player := NewPlayer("player-1", "TestUser")
+	player.Mana = 50
+
+	player.Update(2.0) // 2 seconds at 5 mana/sec
+
+	assert.Equal(t, 60.0, player.Mana)
+
+	player.Update(100.0)
+	assert.Equal(t, player.MaxMana, player.Mana, "regen should clamp to max mana")
+}
+
+func TestPlayerManaAffixes(t *testing.T) {
+	player := NewPlayer("player-1", "TestUser")
+	ring := &Item{ID: "ring-1", Type: ItemTypeRing, Affixes: []ItemAffix{
+		{Stat: StatMana, Value: 25},
+		{Stat: StatManaRegen, Value: 2},
+	}}
+
+	_, err := player.EquipItem(ring)
+	assert.NoError(t, err)
+	assert.Equal(t, 125.0, player.MaxMana)
+	assert.Equal(t, 7.0, player.ManaRegen)
+
+	player.Mana = player.MaxMana
+	_, err = player.UnequipSlot(SlotRing1)
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, player.MaxMana)
+	assert.Equal(t, 100.0, player.Mana, "mana should clamp when max mana drops")
+}
+
 func TestPlayerSerialize(t *testing.T) {
 	player := NewPlayer("player-1", "TestUser")
 	player.Position = Vector3{X: 10, Y: 2, Z: 5}
@@ -43,6 +74,8 @@ func TestPlayerSerialize(t *testing.T) {
 	assert.Equal(t, "TestUser", data["username"])
 	assert.Equal(t, 75.0, data["health"])
 	assert.Equal(t, 100.0, data["maxHealth"])
+	assert.Equal(t, 100.0, data["mana"])
+	assert.Equal(t, 100.0, data["maxMana"])
 
 	pos := data["position"].(Vector3)
 	assert.Equal(t, 10.0, pos.X)
@@ -51,16 +84,17 @@ func TestPlayerSerialize(t *testing.T) {
 }
 
 func TestProjectileShouldDestroy(t *testing.T) {
+	now := time.Now()
 	projectile := &Projectile{
 		ID:        "proj-1",
-		CreatedAt: time.Now().Add(-3 * time.Second),
+		CreatedAt: now.Add(-3 * time.Second),
 		Lifetime:  2.0, // 2 second lifetime
 	}
 
-	assert.True(t, projectile.ShouldDestroy())
+	assert.True(t, projectile.ShouldDestroy(now))
 
-	projectile.CreatedAt = time.Now()
-	assert.False(t, projectile.ShouldDestroy())
+	projectile.CreatedAt = now
+	assert.False(t, projectile.ShouldDestroy(now))
 }
 
 func TestProjectileUpdate(t *testing.T) {
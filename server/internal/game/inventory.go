@@ -170,12 +170,19 @@ func (inv *Inventory) UnequipItem(slot EquipmentSlot) (*Item, error) {
 	return item, nil
 }
 
-// AddToBag adds an item to the first available bag slot
+// AddToBag adds an item to the first available bag slot. Consumables are
+// merged into existing stacks of the same type first.
 func (inv *Inventory) AddToBag(item *Item) (int, error) {
 	if item == nil {
 		return -1, errors.New("cannot add nil item to bag")
 	}
 
+	if item.Type == ItemTypeConsumable {
+		if slot, merged := inv.stackConsumable(item); merged {
+			return slot, nil
+		}
+	}
+
 	for i := 0; i < inv.MaxBagSlots; i++ {
 		if inv.Bags[i] == nil {
 			inv.Bags[i] = item
@@ -289,7 +296,7 @@ func (inv *Inventory) GetAllStats() map[StatType]float64 {
 
 	// Sum stats from all equipped items
 	for _, item := range inv.Equipment {
-		if item == nil {
+		if item == nil || item.Unidentified {
 			continue
 		}
 
@@ -378,6 +385,49 @@ func (inv *Inventory) SwapEquipmentItems(from, to EquipmentSlot) error {
 	return nil
 }
 
+// stackConsumable merges as much of a consumable as fits into existing stacks
+// of the same type. Returns the last slot topped up and true if the whole
+// quantity fit; any remainder is left on the item.
+func (inv *Inventory) stackConsumable(item *Item) (int, bool) {
+	maxStack := consumableMaxStack(item.ConsumableType)
+	lastSlot := -1
+	for i := 0; i < inv.MaxBagSlots && item.Quantity > 0; i++ {
+		stack := inv.Bags[i]
+		if stack == nil || stack.Type != ItemTypeConsumable || stack.ConsumableType != item.ConsumableType {
+			continue
+		}
+		room := maxStack - stack.Quantity
+		if room <= 0 {
+			continue
+		}
+		moved := min(room, item.Quantity)
+		stack.Quantity += moved
+		item.Quantity -= moved
+		lastSlot = i
+	}
+	return lastSlot, item.Quantity <= 0
+}
+
+// CanAdd returns true if an item would fit in the bag, either in an empty
+// slot or by stacking
+func (inv *Inventory) CanAdd(item *Item) bool {
+	if !inv.IsFull() {
+		return true
+	}
+	if item == nil || item.Type != ItemTypeConsumable {
+		return false
+	}
+
+	room := 0
+	maxStack := consumableMaxStack(item.ConsumableType)
+	for _, stack := range inv.Bags {
+		if stack != nil && stack.Type == ItemTypeConsumable && stack.ConsumableType == item.ConsumableType {
+			room += maxStack - stack.Quantity
+		}
+	}
+	return room >= item.Quantity
+}
+
 // IsFull returns true if the bag has no empty slots
 func (inv *Inventory) IsFull() bool {
 	for i := 0; i < inv.MaxBagSlots; i++ {
@@ -2,6 +2,7 @@ package game
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"time"
 )
@@ -18,6 +19,8 @@ const (
 	ItemTypeFeet     ItemType = "feet"
 	ItemTypeAmulet   ItemType = "amulet"
 	ItemTypeRing     ItemType = "ring"
+
+	ItemTypeConsumable ItemType = "consumable" // Potions and scrolls, kept in the bag
 )
 
 // ItemRarity represents the quality/rarity of an item
@@ -46,6 +49,8 @@ const (
 	StatFireResist   StatType = "fire_resist"
 	StatColdResist   StatType = "cold_resist"
 	StatLightningResist StatType = "lightning_resist"
+	StatMana         StatType = "mana"
+	StatManaRegen    StatType = "mana_regen"
 )
 
 // ItemAffix represents a stat bonus on an item
@@ -66,10 +71,20 @@ type Item struct {
 	Affixes     []ItemAffix
 	SetName     string // Empty if not part of a set
 	Description string
+
+	Unidentified   bool   // Affixes don't apply until identified
+	ConsumableType string // Key into items.json consumables, for consumables only
+	Quantity       int    // Stack size, for consumables only
 }
 
 // NewItem creates a new item with the given parameters
 func NewItem(id string, itemType ItemType, level int) *Item {
+	return NewItemWithRarityBonus(id, itemType, level, 0)
+}
+
+// NewItemWithRarityBonus creates an item whose rarity roll is shifted upward
+// by bonus (0-1), used for loot from elite enemies
+func NewItemWithRarityBonus(id string, itemType ItemType, level int, bonus float64) *Item {
 	item := &Item{
 		ID:      id,
 		Type:    itemType,
@@ -78,7 +93,7 @@ func NewItem(id string, itemType ItemType, level int) *Item {
 	}
 
 	// Determine rarity
-	item.Rarity = rollRarity()
+	item.Rarity = rollRarity(bonus)
 
 	// Generate name based on type and rarity
 	item.Name = generateItemName(itemType, item.Rarity)
@@ -89,9 +104,11 @@ func NewItem(id string, itemType ItemType, level int) *Item {
 	return item
 }
 
-// rollRarity determines the rarity of an item
-func rollRarity() ItemRarity {
-	roll := rand.Float64()
+// rollRarity determines the rarity of an item. A bonus skips that fraction
+// of the bottom of the table, so 1.0 would always roll the best rarity.
+func rollRarity(bonus float64) ItemRarity {
+	bonus = math.Max(0, math.Min(bonus, 1))
+	roll := bonus + rand.Float64()*(1-bonus)
 
 	// 60% normal, 35% rare, 5% unique
 	if roll < 0.60 {
@@ -157,7 +174,7 @@ func (i *Item) generateAffixes() {
 
 // getAvailableStats returns the stats that can roll on this item type
 func (i *Item) getAvailableStats() []StatType {
-	stats := []StatType{StatHealth, StatMoveSpeed}
+	stats := []StatType{StatHealth, StatMoveSpeed, StatMana}
 
 	switch i.Type {
 	case ItemTypeWeapon1H, ItemTypeWeapon2H:
@@ -169,6 +186,7 @@ func (i *Item) getAvailableStats() []StatType {
 		stats = append(stats, StatDamage, StatCritChance, StatCritDamage)
 		stats = append(stats, StatFireDamage, StatColdDamage, StatLightningDamage)
 		stats = append(stats, StatFireResist, StatColdResist, StatLightningResist)
+		stats = append(stats, StatManaRegen)
 	}
 
 	return stats
@@ -191,6 +209,8 @@ func (i *Item) generateAffix(stat StatType) ItemAffix {
 		StatFireResist:      {3, 15},
 		StatColdResist:      {3, 15},
 		StatLightningResist: {3, 15},
+		StatMana:            {10, 40},
+		StatManaRegen:       {0.5, 3.0},
 	}
 
 	baseRange := ranges[stat]
@@ -247,7 +267,7 @@ func (i *Item) Serialize() map[string]interface{} {
 		})
 	}
 
-	return map[string]interface{}{
+	data := map[string]interface{}{
 		"id":          i.ID,
 		"name":        i.Name,
 		"type":        string(i.Type),
@@ -256,7 +276,13 @@ func (i *Item) Serialize() map[string]interface{} {
 		"affixes":     affixes,
 		"setName":     i.SetName,
 		"description": i.Description,
+		"unidentified": i.Unidentified,
 	}
+	if i.Type == ItemTypeConsumable {
+		data["consumableType"] = i.ConsumableType
+		data["quantity"] = i.Quantity
+	}
+	return data
 }
 
 // DeserializeItem reconstructs an Item from a JSON-friendly map
@@ -291,6 +317,17 @@ func DeserializeItem(data map[string]interface{}) *Item {
 	if v, ok := data["description"].(string); ok {
 		item.Description = v
 	}
+	if v, ok := data["unidentified"].(bool); ok {
+		item.Unidentified = v
+	}
+	if v, ok := data["consumableType"].(string); ok {
+		item.ConsumableType = v
+	}
+	if v, ok := data["quantity"].(float64); ok {
+		item.Quantity = int(v)
+	} else if v, ok := data["quantity"].(int); ok {
+		item.Quantity = v
+	}
 
 	item.Affixes = make([]ItemAffix, 0)
 	// Handle both []interface{} (JSON) and []map[string]interface{} (direct)
@@ -49,7 +49,7 @@ func TestRollRarity_ReturnsValidRarity(t *testing.T) {
 
 	iterations := 1000
 	for i := 0; i < iterations; i++ {
-		rarity := rollRarity()
+		rarity := rollRarity(0)
 		rarityCounts[rarity]++
 	}
 
@@ -0,0 +1,222 @@
+package game
+
+import (
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+)
+
+// leashSettings returns the leash tuning from enemies.json with defaults
+// filled in for anything left unset
+func leashSettings() config.LeashConfig {
+	cfg := config.Enemies.Leash
+	if cfg.Radius <= 0 {
+		cfg.Radius = 30.0
+	}
+	if cfg.ReturnSpeedMultiplier <= 0 {
+		cfg.ReturnSpeedMultiplier = 1.5
+	}
+	if cfg.RegenPerSecond <= 0 {
+		cfg.RegenPerSecond = 0.25
+	}
+	return cfg
+}
+
+// setHome records where the enemy spawned and how far it will follow a
+// target from there. A radius of 0 uses the default from enemies.json.
+func (ai *EnemyAI) setHome(pos Vector3, leashRadius float64) {
+	home := pos
+	ai.Home = &home
+	ai.LeashRadius = leashRadius
+	if ai.LeashRadius <= 0 {
+		ai.LeashRadius = leashSettings().Radius
+	}
+}
+
+// beyondLeash returns true if the enemy has been pulled too far from home
+func (ai *EnemyAI) beyondLeash(enemy *Enemy) bool {
+	return ai.Home != nil && ai.LeashRadius > 0 && Distance2D(enemy.Position, *ai.Home) > ai.LeashRadius
+}
+
+// forgetTargets drops everything the enemy knows about the fight it was in
+func (ai *EnemyAI) forgetTargets() {
+	ai.TargetID = ""
+	ai.Threat = NewThreatTable()
+	ai.Path = nil
+	ai.IsCharging = false
+	ai.FlankOffset = Vector3{}
+	ai.FleeUntil = time.Time{}
+	ai.RageMode = false
+}
+
+// startReturn gives up the fight and heads home. Bosses reset their encounter.
+func (ai *EnemyAI) startReturn(enemy *Enemy) {
+	ai.State = AIStateReturn
+	ai.forgetTargets()
+	if ai.Boss != nil && ai.Boss.Engaged {
+		ai.Boss.reset(enemy, ai)
+	}
+}
+
+// executeReturn walks the enemy back home, regenerating health on the way.
+// Returning enemies ignore targets, slows and stuns until they arrive.
+func (ai *EnemyAI) executeReturn(enemy *Enemy, ctx *EnemyAIContext) *EnemyAttackResult {
+	if ai.Home == nil {
+		ai.State = AIStateIdle
+		return nil
+	}
+	settings := leashSettings()
+	enemy.Health = math.Min(enemy.MaxHealth, enemy.Health+enemy.MaxHealth*settings.RegenPerSecond*ctx.DeltaSeconds)
+
+	home := *ai.Home
+	distance := Distance2D(enemy.Position, home)
+	if distance <= waypointReachedDistance {
+		ai.arriveHome(enemy)
+		return nil
+	}
+
+	direction := Vector3{X: (home.X - enemy.Position.X) / distance, Z: (home.Z - enemy.Position.Z) / distance}
+	if steer := ai.steerTarget(enemy, ctx, home); steer != home {
+		if steerDistance := Distance2D(enemy.Position, steer); steerDistance > 0.01 {
+			direction = Vector3{X: (steer.X - enemy.Position.X) / steerDistance, Z: (steer.Z - enemy.Position.Z) / steerDistance}
+		}
+	}
+
+	speed := ai.ChaseSpeed * settings.ReturnSpeedMultiplier
+	step := math.Min(speed*ctx.DeltaSeconds, distance) // Don't overshoot home
+	enemy.Velocity = Vector3{X: direction.X * speed, Z: direction.Z * speed}
+	enemy.Position.X += direction.X * step
+	enemy.Position.Z += direction.Z * step
+	return nil
+}
+
+// arriveHome finishes a return: the enemy is back to full health and waits
+// for a new target
+func (ai *EnemyAI) arriveHome(enemy *Enemy) {
+	enemy.Velocity = Vector3{X: 0, Y: 0, Z: 0}
+	enemy.Health = enemy.MaxHealth
+	enemy.Shield = enemy.MaxShield
+	ai.forgetTargets()
+	ai.State = AIStateIdle
+}
+
+// parkedTileEnemies returns enemies set aside while their home tile is
+// inactive, creating the map on first use. Caller must hold w.mu.
+func (w *World) parkedTileEnemies() map[HexCoord][]*Enemy {
+	if w.parkedEnemies == nil {
+		w.parkedEnemies = make(map[HexCoord][]*Enemy)
+	}
+	return w.parkedEnemies
+}
+
+// isEnemyActive returns true if the enemy should be simulated: it's standing
+// in an active tile, or the tile it belongs to is active and it needs to
+// find its way back. Caller must hold w.mu.
+func (w *World) isEnemyActive(enemy *Enemy) bool {
+	if w.isEntityInActiveTile(enemy.Position) {
+		return true
+	}
+	if enemy.HomeTile == nil {
+		return false
+	}
+	tile := w.Board.GetTile(*enemy.HomeTile)
+	return tile != nil && tile.Active
+}
+
+// deactivateTiles stops simulating tiles no player is near any more and
+// parks their enemies. Caller must hold w.mu.
+func (w *World) deactivateTiles(active map[HexCoord]bool) {
+	for coord, tile := range w.Board.Tiles {
+		if tile.Active && !active[coord] {
+			tile.Active = false
+			w.parkTileEnemies(tile)
+		}
+	}
+}
+
+// parkTileEnemies takes a deactivated tile's enemies out of the world. Living
+// enemies that spawned there are reset and kept to be restored with the same
+// IDs when the tile is next active; adds and summons standing in it have no
+// home and are despawned. Caller must hold w.mu.
+func (w *World) parkTileEnemies(tile *Tile) {
+	var parked []*Enemy
+	despawned := 0
+	for id, enemy := range w.enemies {
+		if enemy.HomeTile != nil {
+			if *enemy.HomeTile != tile.Coord {
+				continue
+			}
+			if !enemy.IsDead() {
+				w.parkEnemy(enemy)
+				parked = append(parked, enemy)
+			}
+			w.removeEnemy(id)
+			continue
+		}
+		if WorldToHex(enemy.Position, layerFromY(enemy.Position.Y)) == tile.Coord {
+			w.removeEnemy(id)
+			despawned++
+		}
+	}
+
+	if len(parked) > 0 {
+		sort.Slice(parked, func(i, j int) bool { return parked[i].ID < parked[j].ID })
+		w.parkedTileEnemies()[tile.Coord] = append(w.parkedTileEnemies()[tile.Coord], parked...)
+	}
+	if len(parked) > 0 || despawned > 0 {
+		log.Printf("[WORLD] Tile (%d,%d) deactivated: parked %d enemies, despawned %d", tile.Coord.Q, tile.Coord.R, len(parked), despawned)
+	}
+}
+
+// parkEnemy puts an enemy back at home as if it had never been fought.
+// Caller must hold w.mu.
+func (w *World) parkEnemy(enemy *Enemy) {
+	ai := enemy.AI
+	if ai != nil {
+		if ai.Boss != nil && ai.Boss.Engaged {
+			ai.Boss.reset(enemy, ai)
+			w.updateBoss(enemy) // Flush the reset so it isn't replayed on restore
+		}
+		if ai.Home != nil {
+			enemy.Position = *ai.Home
+		}
+		ai.PackBuffActive = false
+		ai.arriveHome(enemy)
+	}
+	enemy.StatusEffects = make(map[StatusEffectType]*StatusEffect)
+	w.cancelTelegraphs(enemy.ID)
+}
+
+// restoreParkedEnemies brings a reactivated tile's parked enemies back into
+// the world, regrouping their packs. Caller must hold w.mu.
+func (w *World) restoreParkedEnemies(tile *Tile) {
+	parked := w.parkedTileEnemies()[tile.Coord]
+	delete(w.parkedEnemies, tile.Coord)
+
+	packs := make(map[string][]*Enemy)
+	for _, enemy := range parked {
+		enemy.LastUpdate = w.Now()
+		w.addEnemy(enemy)
+		if enemy.AI == nil || enemy.AI.PackID == "" {
+			continue
+		}
+		if enemy.AI.IsPackLeader {
+			packs[enemy.AI.PackID] = append([]*Enemy{enemy}, packs[enemy.AI.PackID]...)
+		} else {
+			packs[enemy.AI.PackID] = append(packs[enemy.AI.PackID], enemy)
+		}
+	}
+	for packID, members := range packs {
+		if len(members) < 2 {
+			members[0].AI.PackID = "" // The rest of its pack died before it was parked
+			members[0].AI.IsPackLeader = false
+			continue
+		}
+		w.formPack(packID, members)
+	}
+
+	log.Printf("[WORLD] Restored %d parked enemies in tile (%d,%d)", len(parked), tile.Coord.Q, tile.Coord.R)
+}
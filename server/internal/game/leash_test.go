@@ -0,0 +1,135 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLeashTestWorld returns a two-tile world whose origin tile spawns a pack
+// of two zombies, with a player standing in it
+func newLeashTestWorld(t *testing.T) (*World, *Tile, *Player) {
+	t.Helper()
+	withEnemyConfig(t, "zombie", *createTestEnemyConfig("melee", 10))
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	tile := world.Board.Tiles[HexCoord{Q: 0, R: 0}]
+	tile.Spawns = []EnemySpawnPoint{{Position: Vector3{X: 3}, EnemyTypes: []string{"zombie"}, Count: 2}}
+	for _, tile := range world.Board.Tiles {
+		tile.Active = true
+	}
+
+	player := NewPlayer("p1", "One")
+	world.players[player.ID] = player
+	return world, tile, player
+}
+
+func enemyIDs(world *World) []string {
+	ids := make([]string, 0, len(world.enemies))
+	for id := range world.enemies {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestLeash_ReturnsHomeAndRegenerates(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	player := NewPlayer("p1", "One")
+	player.Position = Vector3{X: 13}
+	world.players[player.ID] = player
+
+	enemy := createTestEnemy("e1", "zombie", Vector3{X: 12}, createTestEnemyConfig("melee", 20))
+	enemy.AI.setHome(Vector3{}, 10)
+	enemy.Health = 40
+	world.addEnemy(enemy)
+	enemy.AI.Threat.Raise(player.ID, 100, false)
+
+	ctx := &EnemyAIContext{Players: world.players, Enemies: world.enemies, Minions: world.minions, DeltaSeconds: 0.05}
+	enemy.UpdateAI(ctx)
+	require.Equal(t, AIStateReturn, enemy.AI.State)
+	assert.Empty(t, enemy.AI.TargetID)
+	assert.Empty(t, enemy.AI.Threat.Entries())
+
+	enemy.AI.Threat.Raise(player.ID, 100, false) // Hit on the way home
+	enemy.UpdateAI(ctx)
+	assert.Equal(t, AIStateReturn, enemy.AI.State, "returning enemies ignore targets")
+	assert.Greater(t, enemy.Health, 40.0, "health regenerates on the way")
+	assert.Less(t, enemy.Position.X, 12.0)
+
+	for i := 0; i < 100 && enemy.AI.State == AIStateReturn; i++ {
+		enemy.UpdateAI(ctx)
+	}
+	assert.Equal(t, AIStateIdle, enemy.AI.State)
+	assert.Less(t, Distance2D(enemy.Position, Vector3{}), waypointReachedDistance+0.01)
+	assert.Equal(t, enemy.MaxHealth, enemy.Health)
+	assert.Empty(t, enemy.AI.Threat.Entries(), "threat from the way home is forgotten")
+}
+
+func TestNewEnemy_HomeAndLeashRadius(t *testing.T) {
+	cfg := *createTestEnemyConfig("melee", 10)
+	withEnemyConfig(t, "zombie", cfg)
+	cfg.AI.LeashRadius = 50
+	withEnemyConfig(t, "far_zombie", cfg)
+
+	enemy := NewEnemy("e1", "zombie", Vector3{X: 4, Z: 2})
+	require.NotNil(t, enemy.AI.Home)
+	assert.Equal(t, Vector3{X: 4, Z: 2}, *enemy.AI.Home)
+	assert.Equal(t, leashSettings().Radius, enemy.AI.LeashRadius)
+	assert.Equal(t, 50.0, NewEnemy("e2", "far_zombie", Vector3{}).AI.LeashRadius)
+}
+
+func TestDeactivateTiles_ParksAndRestoresWithSameIDs(t *testing.T) {
+	world, tile, player := newLeashTestWorld(t)
+	world.checkTileRespawns()
+	require.Len(t, world.enemies, 2)
+	ids := enemyIDs(world)
+
+	var wounded *Enemy
+	for _, enemy := range world.enemies {
+		wounded = enemy
+		break
+	}
+	wounded.Health = 10
+	wounded.Position = Vector3{X: 6, Z: 1}
+	wounded.AI.Threat.Raise(player.ID, 100, false)
+	summon := NewEnemy("summon-1", "zombie", Vector3{X: 1})
+	world.addEnemy(summon)
+
+	world.deactivateTiles(map[HexCoord]bool{{Q: 1, R: 0}: true})
+	assert.False(t, tile.Active)
+	assert.Empty(t, world.enemies, "the tile's enemies are parked and the summon despawned")
+	require.Len(t, world.parkedEnemies[tile.Coord], 2)
+
+	world.checkTileRespawns()
+	assert.Empty(t, world.enemies, "nothing comes back while the tile is inactive")
+
+	tile.Active = true
+	world.checkTileRespawns()
+	assert.ElementsMatch(t, ids, enemyIDs(world), "the same enemies return instead of new ones")
+	assert.Empty(t, world.parkedEnemies)
+	assert.Equal(t, wounded.MaxHealth, wounded.Health)
+	assert.Equal(t, *wounded.AI.Home, wounded.Position)
+	assert.Empty(t, wounded.AI.Threat.Entries())
+	assert.Len(t, world.packs, 1, "the pack is regrouped")
+}
+
+func TestCheckTileRespawns_CountsEnemiesByHomeTile(t *testing.T) {
+	world, tile, _ := newLeashTestWorld(t)
+	world.checkTileRespawns()
+	require.Len(t, world.enemies, 2)
+
+	// Chase the tile's enemies out into the neighbor
+	east := HexToWorld(HexCoord{Q: 1, R: 0})
+	for _, enemy := range world.enemies {
+		enemy.Position = east
+	}
+	world.checkTileRespawns()
+	assert.Len(t, world.enemies, 2, "the tile isn't refilled while its enemies are elsewhere")
+
+	for _, enemy := range world.enemies {
+		require.NotNil(t, enemy.HomeTile)
+		assert.Equal(t, tile.Coord, *enemy.HomeTile)
+		assert.True(t, world.isEnemyActive(enemy))
+	}
+}
@@ -3,6 +3,7 @@ package game
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 // LLMAction is the structured JSON output from the LLM character AI.
@@ -16,31 +17,184 @@ type LLMAction struct {
 	Reason    string `json:"reason"`               // Short explanation for dialogue
 }
 
-// GBNF grammar that constrains LLM output to valid LLMAction JSON.
+// ActionGBNF returns the GBNF grammar that constrains LLM output to valid
+// LLMAction JSON, using the abilities in the registry.
 // This ensures the model can only produce parseable, game-valid output.
-const ActionGBNF = `
+func ActionGBNF() string {
+	return BuildActionGBNF(RegisteredAbilities(), nil)
+}
+
+// ActionConstraint limits a provider's output to valid actions, as a GBNF
+// grammar for servers that take one and an equivalent JSON schema for
+// servers that constrain output with a response format instead.
+type ActionConstraint struct {
+	Grammar string
+	Schema  map[string]interface{}
+}
+
+// ActionConstraint returns both forms of the constraint for this snapshot.
+func (s *StateSnapshot) ActionConstraint() ActionConstraint {
+	return ActionConstraint{Grammar: s.ActionGBNF(), Schema: s.ActionJSONSchema()}
+}
+
+// ActionGBNF returns the grammar for this snapshot: only the character's
+// ready abilities and the enemies it can see, or idling if it sees none.
+func (s *StateSnapshot) ActionGBNF() string {
+	if len(s.Enemies) == 0 {
+		return idleGBNF
+	}
+	return BuildActionGBNF(s.readyAbilities(), s.enemyIDs())
+}
+
+// ActionJSONSchema returns the schema for this snapshot, matching ActionGBNF.
+func (s *StateSnapshot) ActionJSONSchema() map[string]interface{} {
+	if len(s.Enemies) == 0 {
+		return idleJSONSchema()
+	}
+	return BuildActionJSONSchema(s.readyAbilities(), s.enemyIDs())
+}
+
+// readyAbilities returns the abilities the character can cast right now
+func (s *StateSnapshot) readyAbilities() []AbilityType {
+	var ready []AbilityType
+	for _, a := range s.Abilities {
+		if a.Ready {
+			ready = append(ready, AbilityType(a.Type))
+		}
+	}
+	return ready
+}
+
+// enemyIDs returns the IDs of the enemies the character can see
+func (s *StateSnapshot) enemyIDs() []string {
+	ids := make([]string, 0, len(s.Enemies))
+	for _, e := range s.Enemies {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+// BuildActionGBNF builds the action grammar for the given abilities, with
+// targets limited to the target keywords and the given enemy IDs. Without
+// abilities the "ability" action is left out.
+func BuildActionGBNF(abilities []AbilityType, enemyIDs []string) string {
+	targetVals := []string{gbnfString("nearest"), gbnfString("lowest_hp"), gbnfString("priority")}
+	for _, id := range enemyIDs {
+		targetVals = append(targetVals, gbnfString(id))
+	}
+	targets := strings.Join(targetVals, " | ")
+
+	if len(abilities) == 0 {
+		return fmt.Sprintf(actionGBNFTemplate,
+			`"" | "," ws target-kv | "," ws target-kv "," ws direction-kv | "," ws direction-kv`,
+			"",
+			`"\"dodge\"" | "\"retreat\"" | "\"idle\""`,
+			"",
+			targets)
+	}
+
+	abilityVals := make([]string, 0, len(abilities))
+	for _, a := range abilities {
+		abilityVals = append(abilityVals, gbnfString(string(a)))
+	}
+	return fmt.Sprintf(actionGBNFTemplate,
+		`"" | "," ws ability-kv | "," ws ability-kv "," ws target-kv | "," ws ability-kv "," ws target-kv "," ws direction-kv | "," ws direction-kv`,
+		`ability-kv   ::= "\"ability\"" ws ":" ws ability-val`+"\n",
+		`"\"ability\"" | "\"dodge\"" | "\"retreat\"" | "\"idle\""`,
+		"ability-val   ::= "+strings.Join(abilityVals, " | ")+"\n",
+		targets)
+}
+
+// gbnfString returns a grammar literal matching v as a JSON string
+func gbnfString(v string) string {
+	encoded, _ := json.Marshal(v)
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(string(encoded)) + `"`
+}
+
+const actionGBNFTemplate = `
 root   ::= "{" ws action-kv "," ws mood-kv "," ws reason-kv extra-kvs "}" ws
 
 action-kv ::= "\"action\"" ws ":" ws action-val
 mood-kv   ::= "\"mood\"" ws ":" ws mood-val
 reason-kv ::= "\"reason\"" ws ":" ws string
 
-extra-kvs ::= "" | "," ws ability-kv | "," ws ability-kv "," ws target-kv | "," ws ability-kv "," ws target-kv "," ws direction-kv | "," ws direction-kv
+extra-kvs ::= %s
 
-ability-kv   ::= "\"ability\"" ws ":" ws ability-val
-target-kv    ::= "\"target\"" ws ":" ws target-val
+%starget-kv    ::= "\"target\"" ws ":" ws target-val
 direction-kv ::= "\"direction\"" ws ":" ws direction-val
 
-action-val    ::= "\"ability\"" | "\"dodge\"" | "\"retreat\"" | "\"idle\""
+action-val    ::= %s
 mood-val      ::= "\"neutral\"" | "\"confident\"" | "\"anxious\"" | "\"frustrated\"" | "\"refusing\""
-ability-val   ::= "\"fireball\"" | "\"frostbolt\"" | "\"lightning\"" | "\"basic_attack\""
-target-val    ::= "\"nearest\"" | "\"lowest_hp\"" | "\"priority\"" | string
+%starget-val    ::= %s
 direction-val ::= "\"toward_target\"" | "\"away_from_target\"" | "\"left\"" | "\"right\""
 
 string ::= "\"" ([^"\\] | "\\" .)* "\""
 ws     ::= [ \t\n]*
 `
 
+// idleGBNF only lets the character idle; used when it can't see any enemies
+const idleGBNF = `
+root   ::= "{" ws "\"action\"" ws ":" ws "\"idle\"" "," ws mood-kv "," ws reason-kv "}" ws
+mood-kv   ::= "\"mood\"" ws ":" ws mood-val
+reason-kv ::= "\"reason\"" ws ":" ws string
+
+mood-val ::= "\"neutral\"" | "\"confident\"" | "\"anxious\"" | "\"frustrated\"" | "\"refusing\""
+
+string ::= "\"" ([^"\\] | "\\" .)* "\""
+ws     ::= [ \t\n]*
+`
+
+// BuildActionJSONSchema builds the action schema for the given abilities, with
+// targets limited to the target keywords and the given enemy IDs, like
+// BuildActionGBNF. Strict schemas must list every property as required, so
+// ability, target and direction are nullable instead of optional.
+func BuildActionJSONSchema(abilities []AbilityType, enemyIDs []string) map[string]interface{} {
+	actionVals := []string{"dodge", "retreat", "idle"}
+	abilityVals := make([]string, 0, len(abilities))
+	for _, a := range abilities {
+		abilityVals = append(abilityVals, string(a))
+	}
+	if len(abilityVals) > 0 {
+		actionVals = append([]string{"ability"}, actionVals...)
+	}
+
+	targetVals := append([]string{"nearest", "lowest_hp", "priority"}, enemyIDs...)
+
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action":    map[string]interface{}{"type": "string", "enum": actionVals},
+			"mood":      map[string]interface{}{"type": "string", "enum": []string{"neutral", "confident", "anxious", "frustrated", "refusing"}},
+			"reason":    map[string]interface{}{"type": "string"},
+			"ability":   nullableEnum(abilityVals),
+			"target":    nullableEnum(targetVals),
+			"direction": nullableEnum([]string{"toward_target", "away_from_target", "left", "right"}),
+		},
+		"required":             []string{"action", "mood", "reason", "ability", "target", "direction"},
+		"additionalProperties": false,
+	}
+}
+
+// idleJSONSchema only lets the character idle, like idleGBNF
+func idleJSONSchema() map[string]interface{} {
+	schema := BuildActionJSONSchema(nil, nil)
+	properties := schema["properties"].(map[string]interface{})
+	properties["action"] = map[string]interface{}{"type": "string", "enum": []string{"idle"}}
+	properties["target"] = nullableEnum(nil)
+	properties["direction"] = nullableEnum(nil)
+	return schema
+}
+
+// nullableEnum returns a schema for a string from vals, or null
+func nullableEnum(vals []string) map[string]interface{} {
+	enum := make([]interface{}, 0, len(vals)+1)
+	for _, v := range vals {
+		enum = append(enum, v)
+	}
+	enum = append(enum, nil)
+	return map[string]interface{}{"type": []string{"string", "null"}, "enum": enum}
+}
+
 // ParseLLMAction parses raw JSON bytes into an LLMAction.
 func ParseLLMAction(data []byte) (*LLMAction, error) {
 	var action LLMAction
@@ -67,22 +221,83 @@ func ParseLLMAction(data []byte) (*LLMAction, error) {
 }
 
 // ToAIAction converts an LLMAction into the game's AIAction format,
-// resolving target references against the current enemy set.
-func (la *LLMAction) ToAIAction(player *Player, enemies map[string]*Enemy) *AIAction {
+// resolving target and direction against live world state. The LLM decided
+// from a snapshot that may be a few ticks old, so a target that has died or
+// wandered off since, or one it made up, is rejected and the caller falls
+// back to the behavior tree.
+func (la *LLMAction) ToAIAction(player *Player, enemies map[string]*Enemy) (*AIAction, error) {
 	ai := &AIAction{
 		Type:     la.Action,
 		Mood:     CharacterMood(la.Mood),
 		Dialogue: la.Reason,
 	}
 
-	if la.Action == "ability" {
+	switch la.Action {
+	case "ability":
 		ai.Ability = AbilityType(la.Ability)
+		if player.Abilities != nil {
+			if _, ok := player.Abilities.GetAbility(ai.Ability); !ok {
+				return nil, fmt.Errorf("unknown ability %q", la.Ability)
+			}
+		}
+	case "dodge":
+		// Dodge with the requested movement ability, or any ready one
+		if player.Abilities == nil {
+			break
+		}
+		if ability, ok := player.Abilities.GetAbility(AbilityType(la.Ability)); ok && ability.IsMovement() {
+			ai.Ability = ability.Type
+		} else if player.CharAI != nil {
+			ai.Ability = player.CharAI.chooseMovementAbility(player)
+		}
+	case "idle":
+		// Nothing to aim
+		return ai, nil
 	}
 
-	// Resolve target
+	target, err := la.resolveTarget(player, enemies)
+	if err != nil {
+		return nil, err
+	}
+	ai.TargetID = target.ID
+
+	dir := Vector3{
+		X: target.Position.X - player.Position.X,
+		Z: target.Position.Z - player.Position.Z,
+	}
+	dist := Distance2D(player.Position, target.Position)
+	if dist > 0.001 {
+		dir.X /= dist
+		dir.Z /= dist
+	}
+
+	// Dodging and retreating move away from the target unless told otherwise
+	direction := la.Direction
+	if direction == "" && (la.Action == "dodge" || la.Action == "retreat") {
+		direction = "away_from_target"
+	}
+	switch direction {
+	case "toward_target", "":
+		ai.Direction = dir
+	case "away_from_target":
+		ai.Direction = Vector3{X: -dir.X, Z: -dir.Z}
+	case "left":
+		ai.Direction = Vector3{X: -dir.Z, Z: dir.X}
+	case "right":
+		ai.Direction = Vector3{X: dir.Z, Z: -dir.X}
+	default:
+		return nil, fmt.Errorf("unknown direction %q", la.Direction)
+	}
+
+	return ai, nil
+}
+
+// resolveTarget maps the action's target keyword or enemy ID to a live enemy.
+// No target means the nearest one.
+func (la *LLMAction) resolveTarget(player *Player, enemies map[string]*Enemy) (*Enemy, error) {
 	var target *Enemy
 	switch la.Target {
-	case "nearest":
+	case "nearest", "":
 		target = findNearestEnemy(player.Position, enemies)
 	case "lowest_hp":
 		target = findLowestHPEnemy(enemies)
@@ -96,37 +311,16 @@ func (la *LLMAction) ToAIAction(player *Player, enemies map[string]*Enemy) *AIAc
 			target = findNearestEnemy(player.Position, enemies)
 		}
 	default:
-		// Try as enemy ID
-		if e, ok := enemies[la.Target]; ok && !e.IsDead() {
-			target = e
+		e, ok := enemies[la.Target]
+		if !ok || e.IsDead() {
+			return nil, fmt.Errorf("target %q is not a live enemy nearby", la.Target)
 		}
+		target = e
 	}
-
-	if target != nil {
-		ai.TargetID = target.ID
-		dir := Vector3{
-			X: target.Position.X - player.Position.X,
-			Z: target.Position.Z - player.Position.Z,
-		}
-		dist := Distance2D(player.Position, target.Position)
-		if dist > 0.001 {
-			dir.X /= dist
-			dir.Z /= dist
-		}
-
-		switch la.Direction {
-		case "away_from_target":
-			ai.Direction = Vector3{X: -dir.X, Z: -dir.Z}
-		case "left":
-			ai.Direction = Vector3{X: -dir.Z, Z: dir.X}
-		case "right":
-			ai.Direction = Vector3{X: dir.Z, Z: -dir.X}
-		default: // "toward_target" or unset
-			ai.Direction = dir
-		}
+	if target == nil {
+		return nil, fmt.Errorf("no live enemies for target %q", la.Target)
 	}
-
-	return ai
+	return target, nil
 }
 
 func findNearestEnemy(pos Vector3, enemies map[string]*Enemy) *Enemy {
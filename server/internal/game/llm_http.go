@@ -2,12 +2,12 @@ package game
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"sync"
 	"time"
 )
 
@@ -15,7 +15,6 @@ import (
 type HTTPLLMProvider struct {
 	baseURL string
 	client  *http.Client
-	mu      sync.Mutex
 }
 
 // HTTPLLMConfig holds configuration for HTTP LLM provider
@@ -76,11 +75,9 @@ func NewHTTPLLMProvider(cfg HTTPLLMConfig) (*HTTPLLMProvider, error) {
 	return provider, nil
 }
 
-// Generate implements LLMProvider.Generate
-func (p *HTTPLLMProvider) Generate(prompt string, grammar string) ([]byte, error) {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-
+// Generate implements LLMProvider.Generate. Calls run concurrently, one per
+// llama-server slot (start it with --parallel).
+func (p *HTTPLLMProvider) Generate(ctx context.Context, prompt string, constraint ActionConstraint) ([]byte, error) {
 	// Build request
 	req := completionRequest{
 		Prompt:      prompt,
@@ -89,7 +86,7 @@ func (p *HTTPLLMProvider) Generate(prompt string, grammar string) ([]byte, error
 		TopK:        40,
 		MaxTokens:   128,
 		Stop:        []string{"</s>", "\n\n", "```"},
-		Grammar:     grammar,
+		Grammar:     constraint.Grammar,
 		Stream:      false,
 	}
 
@@ -99,7 +96,7 @@ func (p *HTTPLLMProvider) Generate(prompt string, grammar string) ([]byte, error
 	}
 
 	// Make HTTP request
-	httpReq, err := http.NewRequest("POST", p.baseURL+"/completion", bytes.NewBuffer(reqBody))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/completion", bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -1,15 +1,20 @@
 package game
 
 import (
+	"context"
 	"log"
 	"sync"
 	"time"
 )
 
 // LLMProvider is the interface for any LLM backend (llama.cpp, HTTP API, etc).
+// Generate is called from several workers at once, so implementations must be
+// safe for concurrent use.
 type LLMProvider interface {
-	// Generate takes a prompt and GBNF grammar, returns constrained output bytes.
-	Generate(prompt string, grammar string) ([]byte, error)
+	// Generate takes a prompt and the constraint on valid actions, in whichever
+	// form the backend understands, and returns constrained output bytes.
+	// It should give up when ctx is done.
+	Generate(ctx context.Context, prompt string, constraint ActionConstraint) ([]byte, error)
 
 	// Name returns the provider name for logging.
 	Name() string
@@ -22,27 +27,51 @@ type LLMProvider interface {
 type InferenceRequest struct {
 	PlayerID string
 	Snapshot *StateSnapshot
-	Result   chan *AIAction
+	Result   chan *LLMAction
+
+	// seq orders a player's requests; only the newest one is worth answering
+	seq uint64
+	// deadline is when the answer stops being useful to the caller
+	deadline time.Time
 }
 
-// LLMManager manages the async inference pipeline with batching and fallback.
+// LLMManager runs inference on a pool of workers, one per parallel slot on the
+// LLM server, and falls back to the behavior tree whenever the LLM is down,
+// slow or overloaded.
 type LLMManager struct {
 	provider LLMProvider
 	fallback *FallbackProvider
 
 	// Request queue
-	queue    chan InferenceRequest
-	queueMu  sync.Mutex
+	queue chan InferenceRequest
+
+	// Newest request issued per player; older ones are stale. Sequence
+	// numbers are never reused, so forgetting a player can't revive one.
+	seqMu   sync.Mutex
+	nextSeq uint64
+	latest  map[string]uint64
+
+	// Cached health and circuit breaker
+	healthMu            sync.RWMutex
+	healthy             bool
+	consecutiveFailures int
+	breakerOpenUntil    time.Time
+	probing             bool // A request is testing a breaker that just cooled down
 
 	// Stats
-	totalRequests   int64
-	totalFallbacks  int64
-	avgLatencyMs    float64
+	statsMu        sync.Mutex
+	totalRequests  int64
+	totalFallbacks int64
+	totalStale     int64
+	totalTimeouts  int64
+	avgLatencyMs   float64
 
 	// Configuration
-	maxQueueSize    int
-	maxBatchSize    int
-	batchTimeoutMs  int
+	maxQueueSize     int
+	workers          int
+	healthInterval   time.Duration
+	failureThreshold int
+	breakerCooldown  time.Duration
 
 	// Lifecycle
 	running bool
@@ -51,10 +80,12 @@ type LLMManager struct {
 
 // LLMManagerConfig holds configuration for the LLM manager.
 type LLMManagerConfig struct {
-	Provider       LLMProvider
-	MaxQueueSize   int // Max pending requests before dropping
-	MaxBatchSize   int // Max requests to batch per inference
-	BatchTimeoutMs int // Max wait time to fill a batch
+	Provider               LLMProvider
+	MaxQueueSize           int // Max pending requests before dropping
+	Workers                int // Concurrent inferences; match the server's parallel slots
+	HealthCheckSeconds     int // How often to poll the provider's health
+	FailureThreshold       int // Consecutive failures that open the circuit breaker
+	BreakerCooldownSeconds int // How long the breaker stays open before trying again
 }
 
 // NewLLMManager creates a new LLM manager with the given provider.
@@ -63,63 +94,120 @@ func NewLLMManager(cfg LLMManagerConfig) *LLMManager {
 	if cfg.MaxQueueSize <= 0 {
 		cfg.MaxQueueSize = 32
 	}
-	if cfg.MaxBatchSize <= 0 {
-		cfg.MaxBatchSize = 4
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.HealthCheckSeconds <= 0 {
+		cfg.HealthCheckSeconds = 5
 	}
-	if cfg.BatchTimeoutMs <= 0 {
-		cfg.BatchTimeoutMs = 50
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 3
+	}
+	if cfg.BreakerCooldownSeconds <= 0 {
+		cfg.BreakerCooldownSeconds = 10
 	}
 
 	m := &LLMManager{
-		provider:       cfg.Provider,
-		fallback:       &FallbackProvider{},
-		queue:          make(chan InferenceRequest, cfg.MaxQueueSize),
-		maxQueueSize:   cfg.MaxQueueSize,
-		maxBatchSize:   cfg.MaxBatchSize,
-		batchTimeoutMs: cfg.BatchTimeoutMs,
-		stopCh:         make(chan struct{}),
+		provider:         cfg.Provider,
+		fallback:         &FallbackProvider{},
+		queue:            make(chan InferenceRequest, cfg.MaxQueueSize),
+		latest:           make(map[string]uint64),
+		maxQueueSize:     cfg.MaxQueueSize,
+		workers:          cfg.Workers,
+		healthInterval:   time.Duration(cfg.HealthCheckSeconds) * time.Second,
+		failureThreshold: cfg.FailureThreshold,
+		breakerCooldown:  time.Duration(cfg.BreakerCooldownSeconds) * time.Second,
+		stopCh:           make(chan struct{}),
 	}
 
 	return m
 }
 
-// Start begins the inference worker goroutine.
+// Start checks the provider's health and begins the inference workers.
 func (m *LLMManager) Start() {
 	if m.running {
 		return
 	}
 	m.running = true
 
+	m.checkHealth()
 	providerName := "fallback"
-	if m.provider != nil && m.provider.Available() {
+	if m.Ready() {
 		providerName = m.provider.Name()
 	}
-	log.Printf("[LLM] Manager started, provider=%s queue=%d batch=%d",
-		providerName, m.maxQueueSize, m.maxBatchSize)
+	log.Printf("[LLM] Manager started, provider=%s queue=%d workers=%d",
+		providerName, m.maxQueueSize, m.workers)
 
-	go m.workerLoop()
+	go m.healthLoop()
+	for i := 0; i < m.workers; i++ {
+		go m.workerLoop()
+	}
 }
 
-// Stop shuts down the inference worker.
+// Stop shuts down the inference workers.
 func (m *LLMManager) Stop() {
 	if !m.running {
 		return
 	}
 	m.running = false
 	close(m.stopCh)
-	log.Printf("[LLM] Manager stopped. requests=%d fallbacks=%d avgLatency=%.1fms",
-		m.totalRequests, m.totalFallbacks, m.avgLatencyMs)
+
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	log.Printf("[LLM] Manager stopped. requests=%d fallbacks=%d stale=%d timeouts=%d avgLatency=%.1fms",
+		m.totalRequests, m.totalFallbacks, m.totalStale, m.totalTimeouts, m.avgLatencyMs)
+}
+
+// Ready returns true if requests are worth queueing: the provider passed its
+// last health check, the circuit breaker has cooled down and no probe is
+// testing it. Callers should use the behavior tree directly when it isn't.
+func (m *LLMManager) Ready() bool {
+	if m.provider == nil {
+		return false
+	}
+	m.healthMu.RLock()
+	defer m.healthMu.RUnlock()
+	return m.healthy && !time.Now().Before(m.breakerOpenUntil) && !m.probing
+}
+
+// admit returns true if a request may run inference now. While the breaker
+// is tripped only one request at a time is let through after the cooldown,
+// as a probe; its result either closes the breaker or opens it again.
+func (m *LLMManager) admit() bool {
+	if m.provider == nil {
+		return false
+	}
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	if !m.healthy || time.Now().Before(m.breakerOpenUntil) || m.probing {
+		return false
+	}
+	if m.consecutiveFailures >= m.failureThreshold {
+		m.probing = true
+	}
+	return true
 }
 
-// RequestDecision queues an inference request. Non-blocking; drops if queue is full.
-// The result will be sent on the returned channel.
-func (m *LLMManager) RequestDecision(playerID string, snapshot *StateSnapshot) chan *AIAction {
-	result := make(chan *AIAction, 1)
+// RequestDecision queues an inference request that has until timeout to be
+// answered. The answer is the LLM's raw action; the caller resolves it against
+// the world as it is when the answer arrives. Non-blocking; answers nil straight away if the queue is full.
+// Issuing a request makes the player's earlier ones stale: they're skipped if
+// still queued and their answers are discarded if already running.
+func (m *LLMManager) RequestDecision(playerID string, snapshot *StateSnapshot, timeout time.Duration) chan *LLMAction {
+	result := make(chan *LLMAction, 1)
+
+	m.seqMu.Lock()
+	m.nextSeq++
+	seq := m.nextSeq
+	m.latest[playerID] = seq
+	m.seqMu.Unlock()
 
 	req := InferenceRequest{
 		PlayerID: playerID,
 		Snapshot: snapshot,
 		Result:   result,
+		seq:      seq,
+		deadline: time.Now().Add(timeout),
 	}
 
 	select {
@@ -127,19 +215,23 @@ func (m *LLMManager) RequestDecision(playerID string, snapshot *StateSnapshot) c
 		// queued
 	default:
 		// Queue full, use fallback immediately
-		m.totalFallbacks++
-		go func() {
-			result <- nil // signal to use behavior tree
-		}()
+		m.countFallback()
+		result <- nil // signal to use behavior tree
 	}
 
 	return result
 }
 
-// workerLoop processes inference requests in batches.
-func (m *LLMManager) workerLoop() {
-	batchTimeout := time.Duration(m.batchTimeoutMs) * time.Millisecond
+// Forget drops a player's request counter when they leave. Answers still in
+// flight for them are discarded as stale.
+func (m *LLMManager) Forget(playerID string) {
+	m.seqMu.Lock()
+	defer m.seqMu.Unlock()
+	delete(m.latest, playerID)
+}
 
+// workerLoop runs one inference at a time until the manager stops.
+func (m *LLMManager) workerLoop() {
 	for {
 		select {
 		case <-m.stopCh:
@@ -152,94 +244,171 @@ func (m *LLMManager) workerLoop() {
 					return
 				}
 			}
-		case firstReq := <-m.queue:
-			// Collect a batch
-			batch := []InferenceRequest{firstReq}
-			timer := time.NewTimer(batchTimeout)
+		case req := <-m.queue:
+			m.process(req)
+		}
+	}
+}
 
-		collectLoop:
-			for len(batch) < m.maxBatchSize {
-				select {
-				case req := <-m.queue:
-					batch = append(batch, req)
-				case <-timer.C:
-					break collectLoop
-				case <-m.stopCh:
-					timer.Stop()
-					for _, req := range batch {
-						req.Result <- nil
-					}
-					return
-				}
-			}
-			timer.Stop()
+// healthLoop refreshes the cached provider health so callers never wait on it.
+func (m *LLMManager) healthLoop() {
+	ticker := time.NewTicker(m.healthInterval)
+	defer ticker.Stop()
 
-			// Process the batch
-			m.processBatch(batch)
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.checkHealth()
 		}
 	}
 }
 
-// processBatch runs inference for a batch of requests.
-func (m *LLMManager) processBatch(batch []InferenceRequest) {
-	useProvider := m.provider != nil && m.provider.Available()
-
-	for _, req := range batch {
-		m.totalRequests++
-		start := time.Now()
-
-		var action *AIAction
-
-		if useProvider {
-			prompt := req.Snapshot.ToPrompt()
-			output, err := m.provider.Generate(prompt, ActionGBNF)
-			if err != nil {
-				log.Printf("[LLM] Inference error for player %s: %v (falling back)", req.PlayerID, err)
-				m.totalFallbacks++
-				action = nil // fallback
-			} else {
-				llmAction, err := ParseLLMAction(output)
-				if err != nil {
-					log.Printf("[LLM] Parse error for player %s: %v (falling back)", req.PlayerID, err)
-					m.totalFallbacks++
-					action = nil
-				} else {
-					// We need Player and enemies to convert, but we only have the snapshot.
-					// The caller will handle conversion. Send raw action.
-					action = &AIAction{
-						Type:     llmAction.Action,
-						Ability:  AbilityType(llmAction.Ability),
-						Mood:     CharacterMood(llmAction.Mood),
-						Dialogue: llmAction.Reason,
-					}
-				}
-			}
+// checkHealth asks the provider if it's up and caches the answer.
+func (m *LLMManager) checkHealth() {
+	healthy := m.provider != nil && m.provider.Available()
+
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	if healthy != m.healthy && m.provider != nil {
+		log.Printf("[LLM] Provider %s healthy=%v", m.provider.Name(), healthy)
+	}
+	m.healthy = healthy
+}
+
+// isStale returns true if a newer request has been issued for the same player.
+func (m *LLMManager) isStale(req InferenceRequest) bool {
+	m.seqMu.Lock()
+	defer m.seqMu.Unlock()
+	return m.latest[req.PlayerID] != req.seq
+}
+
+// process runs inference for one request and answers it, unless it went stale.
+func (m *LLMManager) process(req InferenceRequest) {
+	if m.isStale(req) {
+		m.countStale()
+		return
+	}
+	if !time.Now().Before(req.deadline) {
+		m.countTimeout()
+		req.Result <- nil
+		return
+	}
+	if !m.admit() {
+		m.countFallback()
+		req.Result <- nil
+		return
+	}
+
+	m.statsMu.Lock()
+	m.totalRequests++
+	m.statsMu.Unlock()
+
+	start := time.Now()
+	ctx, cancel := context.WithDeadline(context.Background(), req.deadline)
+	defer cancel()
+
+	var action *LLMAction
+	output, err := m.provider.Generate(ctx, req.Snapshot.ToPrompt(), req.Snapshot.ActionConstraint())
+	switch {
+	case ctx.Err() != nil:
+		// Too slow to be useful. A provider that keeps timing out is as good
+		// as down, so this counts toward the breaker too.
+		log.Printf("[LLM] Inference for player %s timed out (falling back)", req.PlayerID)
+		m.recordFailure()
+		m.countTimeout()
+	case err != nil:
+		log.Printf("[LLM] Inference error for player %s: %v (falling back)", req.PlayerID, err)
+		m.recordFailure()
+		m.countFallback()
+	default:
+		action, err = ParseLLMAction(output)
+		if err != nil {
+			log.Printf("[LLM] Parse error for player %s: %v (falling back)", req.PlayerID, err)
+			m.recordFailure()
+			m.countFallback()
 		} else {
-			m.totalFallbacks++
-			action = nil // signal to caller to use behavior tree
+			m.recordSuccess()
 		}
+	}
 
-		elapsed := time.Since(start).Seconds() * 1000
-		m.avgLatencyMs = m.avgLatencyMs*0.95 + elapsed*0.05
+	elapsed := time.Since(start).Seconds() * 1000
+	m.statsMu.Lock()
+	m.avgLatencyMs = m.avgLatencyMs*0.95 + elapsed*0.05
+	m.statsMu.Unlock()
+
+	if m.isStale(req) {
+		m.countStale()
+		return
+	}
+	req.Result <- action
+}
 
-		req.Result <- action
+// recordFailure counts a failed or timed-out inference and opens the circuit
+// breaker once too many fail in a row. After the cooldown one request is let through; if it
+// fails too the breaker opens again straight away.
+func (m *LLMManager) recordFailure() {
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	m.probing = false
+	m.consecutiveFailures++
+	if m.consecutiveFailures >= m.failureThreshold {
+		m.breakerOpenUntil = time.Now().Add(m.breakerCooldown)
+		log.Printf("[LLM] %d failures in a row, circuit open for %s", m.consecutiveFailures, m.breakerCooldown)
 	}
 }
 
+// recordSuccess closes the circuit breaker.
+func (m *LLMManager) recordSuccess() {
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	m.probing = false
+	m.consecutiveFailures = 0
+}
+
+func (m *LLMManager) countFallback() {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	m.totalFallbacks++
+}
+
+func (m *LLMManager) countStale() {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	m.totalStale++
+}
+
+func (m *LLMManager) countTimeout() {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	m.totalTimeouts++
+	m.totalFallbacks++
+}
+
 // Stats returns current performance statistics.
 func (m *LLMManager) Stats() map[string]interface{} {
 	providerName := "fallback"
-	available := false
 	if m.provider != nil {
 		providerName = m.provider.Name()
-		available = m.provider.Available()
 	}
 
+	available := m.Ready()
+	m.healthMu.RLock()
+	breakerOpen := time.Now().Before(m.breakerOpenUntil)
+	m.healthMu.RUnlock()
+
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
 	return map[string]interface{}{
 		"provider":       providerName,
 		"available":      available,
+		"breakerOpen":    breakerOpen,
+		"workers":        m.workers,
 		"totalRequests":  m.totalRequests,
 		"totalFallbacks": m.totalFallbacks,
+		"totalStale":     m.totalStale,
+		"totalTimeouts":  m.totalTimeouts,
 		"avgLatencyMs":   m.avgLatencyMs,
 		"queueLength":    len(m.queue),
 	}
@@ -249,7 +418,7 @@ func (m *LLMManager) Stats() map[string]interface{} {
 // the existing behavior tree instead.
 type FallbackProvider struct{}
 
-func (f *FallbackProvider) Generate(prompt string, grammar string) ([]byte, error) {
+func (f *FallbackProvider) Generate(ctx context.Context, prompt string, constraint ActionConstraint) ([]byte, error) {
 	return nil, nil
 }
 
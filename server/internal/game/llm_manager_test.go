@@ -0,0 +1,288 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLLMProvider answers with respond and tracks how many calls overlap
+type fakeLLMProvider struct {
+	respond func(ctx context.Context) ([]byte, error)
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (f *fakeLLMProvider) Generate(ctx context.Context, prompt string, constraint ActionConstraint) ([]byte, error) {
+	f.mu.Lock()
+	f.inFlight++
+	if f.inFlight > f.maxInFlight {
+		f.maxInFlight = f.inFlight
+	}
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+	return f.respond(ctx)
+}
+
+func (f *fakeLLMProvider) Name() string    { return "fake" }
+func (f *fakeLLMProvider) Available() bool { return true }
+
+func (f *fakeLLMProvider) peakInFlight() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.maxInFlight
+}
+
+const fakeLLMOutput = `{"action":"idle","mood":"confident","reason":"waiting"}`
+
+// blockingLLM answers once release is closed
+func blockingLLM(release chan struct{}) *fakeLLMProvider {
+	return &fakeLLMProvider{respond: func(ctx context.Context) ([]byte, error) {
+		select {
+		case <-release:
+			return []byte(fakeLLMOutput), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}}
+}
+
+func startTestLLMManager(t *testing.T, cfg LLMManagerConfig) *LLMManager {
+	t.Helper()
+	m := NewLLMManager(cfg)
+	m.Start()
+	t.Cleanup(m.Stop)
+	return m
+}
+
+func testSnapshot() *StateSnapshot {
+	enemy := createTestEnemy("e1", "zombie", Vector3{X: 5}, createTestEnemyConfig("melee", 10))
+	return BuildStateSnapshot(NewPlayer("p1", "One"), map[string]*Enemy{enemy.ID: enemy})
+}
+
+func receiveAction(t *testing.T, ch chan *LLMAction) *LLMAction {
+	t.Helper()
+	select {
+	case action := <-ch:
+		return action
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "no answer")
+		return nil
+	}
+}
+
+func TestLLMManager_RunsRequestsConcurrently(t *testing.T) {
+	release := make(chan struct{})
+	provider := blockingLLM(release)
+	m := startTestLLMManager(t, LLMManagerConfig{Provider: provider, Workers: 3})
+
+	var results []chan *LLMAction
+	for _, id := range []string{"p1", "p2", "p3"} {
+		results = append(results, m.RequestDecision(id, testSnapshot(), time.Second))
+	}
+	require.Eventually(t, func() bool { return provider.peakInFlight() == 3 }, time.Second, time.Millisecond)
+	close(release)
+
+	for _, ch := range results {
+		action := receiveAction(t, ch)
+		require.NotNil(t, action)
+		assert.Equal(t, "confident", action.Mood)
+	}
+}
+
+func TestLLMManager_DiscardsStaleAnswers(t *testing.T) {
+	release := make(chan struct{})
+	m := startTestLLMManager(t, LLMManagerConfig{Provider: blockingLLM(release), Workers: 2})
+
+	first := m.RequestDecision("p1", testSnapshot(), time.Second)
+	second := m.RequestDecision("p1", testSnapshot(), time.Second)
+	close(release)
+
+	assert.NotNil(t, receiveAction(t, second))
+	select {
+	case <-first:
+		assert.Fail(t, "a superseded request was answered")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, int64(1), m.Stats()["totalStale"])
+}
+
+func TestLLMManager_TimeoutsCountTowardBreaker(t *testing.T) {
+	m := startTestLLMManager(t, LLMManagerConfig{Provider: blockingLLM(make(chan struct{})), Workers: 1, FailureThreshold: 2})
+
+	assert.Nil(t, receiveAction(t, m.RequestDecision("p1", testSnapshot(), 10*time.Millisecond)))
+	assert.Equal(t, int64(1), m.Stats()["totalTimeouts"])
+	assert.True(t, m.Ready(), "one slow answer is forgiven")
+
+	assert.Nil(t, receiveAction(t, m.RequestDecision("p1", testSnapshot(), 10*time.Millisecond)))
+	assert.False(t, m.Ready(), "a provider that keeps timing out is treated as down")
+}
+
+func TestLLMManager_CircuitBreakerOpensAfterFailures(t *testing.T) {
+	calls := 0
+	var mu sync.Mutex
+	provider := &fakeLLMProvider{respond: func(ctx context.Context) ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return nil, errors.New("slot crashed")
+	}}
+	m := startTestLLMManager(t, LLMManagerConfig{Provider: provider, Workers: 1, FailureThreshold: 2})
+
+	assert.Nil(t, receiveAction(t, m.RequestDecision("p1", testSnapshot(), time.Second)))
+	assert.True(t, m.Ready())
+	assert.Nil(t, receiveAction(t, m.RequestDecision("p2", testSnapshot(), time.Second)))
+	assert.False(t, m.Ready())
+	assert.Equal(t, true, m.Stats()["breakerOpen"])
+
+	assert.Nil(t, receiveAction(t, m.RequestDecision("p3", testSnapshot(), time.Second)))
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, calls, "an open breaker doesn't reach the provider")
+}
+
+func TestLLMManager_CooledBreakerLetsOneProbeThrough(t *testing.T) {
+	release := make(chan struct{})
+	provider := blockingLLM(release)
+	m := startTestLLMManager(t, LLMManagerConfig{Provider: provider, Workers: 2, FailureThreshold: 1})
+	m.recordFailure() // trips the breaker
+	m.healthMu.Lock()
+	m.breakerOpenUntil = time.Now() // cooled down
+	m.healthMu.Unlock()
+
+	probe := m.RequestDecision("p1", testSnapshot(), time.Second)
+	require.Eventually(t, func() bool { return provider.peakInFlight() == 1 }, time.Second, time.Millisecond)
+	assert.False(t, m.Ready(), "the probe holds the breaker half-open")
+	assert.Nil(t, receiveAction(t, m.RequestDecision("p2", testSnapshot(), time.Second)))
+
+	close(release)
+	assert.NotNil(t, receiveAction(t, probe))
+	assert.True(t, m.Ready(), "a successful probe closes the breaker")
+}
+
+func TestLLMManager_ParseErrorCountsAsFailure(t *testing.T) {
+	provider := &fakeLLMProvider{respond: func(ctx context.Context) ([]byte, error) {
+		return []byte(`{"action":"dance"}`), nil
+	}}
+	m := startTestLLMManager(t, LLMManagerConfig{Provider: provider, FailureThreshold: 1})
+
+	assert.Nil(t, receiveAction(t, m.RequestDecision("p1", testSnapshot(), time.Second)))
+	assert.False(t, m.Ready())
+}
+
+func TestLLMManager_ForgetDropsPlayer(t *testing.T) {
+	release := make(chan struct{})
+	m := startTestLLMManager(t, LLMManagerConfig{Provider: blockingLLM(release)})
+
+	result := m.RequestDecision("p1", testSnapshot(), time.Second)
+	m.Forget("p1")
+	close(release)
+
+	select {
+	case <-result:
+		assert.Fail(t, "a departed player's request was answered")
+	case <-time.After(50 * time.Millisecond):
+	}
+	m.seqMu.Lock()
+	defer m.seqMu.Unlock()
+	assert.NotContains(t, m.latest, "p1")
+}
+
+func TestCharacterAI_UpdateWithLLM_ActsWhenAnswerArrives(t *testing.T) {
+	provider := &fakeLLMProvider{respond: func(ctx context.Context) ([]byte, error) {
+		return []byte(fakeLLMOutput), nil
+	}}
+	m := startTestLLMManager(t, LLMManagerConfig{Provider: provider})
+	player := NewPlayer("p1", "One")
+	enemy := createTestEnemy("e1", "zombie", Vector3{X: 5}, createTestEnemyConfig("melee", 10))
+	enemies := map[string]*Enemy{enemy.ID: enemy}
+
+	ai := NewCharacterAI()
+	assert.Nil(t, ai.UpdateWithLLM(1.0, player, enemies, m), "the request doesn't block the tick")
+
+	var action *AIAction
+	require.Eventually(t, func() bool {
+		action = ai.UpdateWithLLM(0, player, enemies, m)
+		return action != nil
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "idle", action.Type)
+	assert.Equal(t, MoodConfident, ai.Mood)
+}
+
+func TestCharacterAI_UpdateWithLLM_SlowProvider(t *testing.T) {
+	provider := &fakeLLMProvider{respond: func(ctx context.Context) ([]byte, error) {
+		select {
+		case <-time.After(70 * time.Millisecond):
+			return []byte(fakeLLMOutput), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}}
+	m := startTestLLMManager(t, LLMManagerConfig{Provider: provider})
+	player := NewPlayer("p1", "One")
+	enemy := createTestEnemy("e1", "zombie", Vector3{X: 5}, createTestEnemyConfig("melee", 10))
+	enemies := map[string]*Enemy{enemy.ID: enemy}
+
+	// The LLM takes longer to answer than a decision lasts
+	ai := NewCharacterAI()
+	ai.decisionRate = 0.05
+	llmAnswers, fallbacks := 0, 0
+	for i := 0; i < 60; i++ {
+		if action := ai.UpdateWithLLM(0.01, player, enemies, m); action != nil {
+			if action.Dialogue == "waiting" {
+				llmAnswers++
+			} else {
+				fallbacks++
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.Greater(t, llmAnswers, 1, "slow answers are still used")
+	assert.Greater(t, fallbacks, 1, "the behavior tree covers decisions while the LLM thinks")
+	assert.True(t, m.Ready())
+}
+
+func TestCharacterAI_UpdateWithLLM_UnreadyUsesBehaviorTree(t *testing.T) {
+	m := NewLLMManager(LLMManagerConfig{Provider: &FallbackProvider{}})
+	m.Start()
+	defer m.Stop()
+	player := NewPlayer("p1", "One")
+	enemy := createTestEnemy("e1", "zombie", Vector3{X: 5}, createTestEnemyConfig("melee", 10))
+
+	assert.False(t, m.Ready())
+	assert.NotNil(t, NewCharacterAI().UpdateWithLLM(1.0, player, map[string]*Enemy{enemy.ID: enemy}, m))
+}
+
+func TestCharacterAI_UpdateWithLLM_StaleTargetFallsBack(t *testing.T) {
+	provider := &fakeLLMProvider{respond: func(ctx context.Context) ([]byte, error) {
+		return []byte(`{"action":"ability","mood":"confident","reason":"go","ability":"fireball","target":"e1"}`), nil
+	}}
+	m := startTestLLMManager(t, LLMManagerConfig{Provider: provider})
+	player := NewPlayer("p1", "One")
+	first := createTestEnemy("e1", "zombie", Vector3{X: 5}, createTestEnemyConfig("melee", 10))
+	second := createTestEnemy("e2", "zombie", Vector3{X: -5}, createTestEnemyConfig("melee", 10))
+	enemies := map[string]*Enemy{first.ID: first, second.ID: second}
+
+	ai := NewCharacterAI()
+	require.Nil(t, ai.UpdateWithLLM(1.0, player, enemies, m))
+	first.Dead = true // dies while the LLM is thinking
+
+	var action *AIAction
+	require.Eventually(t, func() bool {
+		action = ai.UpdateWithLLM(0, player, enemies, m)
+		return action != nil
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "e2", action.TargetID, "the behavior tree picks a live target instead")
+}
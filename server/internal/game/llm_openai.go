@@ -0,0 +1,179 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAILLMProvider implements LLMProvider against an OpenAI-compatible
+// /v1/chat/completions endpoint (vLLM, Ollama, LM Studio, ...). Output is
+// constrained with a JSON schema response format instead of GBNF.
+type OpenAILLMProvider struct {
+	baseURL     string
+	model       string
+	temperature float64
+	apiKey      string
+	client      *http.Client
+}
+
+// OpenAILLMConfig holds configuration for the OpenAI-compatible provider
+type OpenAILLMConfig struct {
+	BaseURL     string  // Server root, with or without /v1 (e.g., "http://localhost:11434")
+	Model       string  // Model name the server should run
+	Temperature float64 // Sampling temperature
+	APIKey      string  // Sent as a bearer token if set
+	Timeout     int     // Request timeout in seconds (default: 30)
+}
+
+// chatMessage is one message in a chat completion request or response
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatCompletionRequest matches the /v1/chat/completions request format
+type chatCompletionRequest struct {
+	Model          string             `json:"model"`
+	Messages       []chatMessage      `json:"messages"`
+	Temperature    float64            `json:"temperature"`
+	MaxTokens      int                `json:"max_tokens"`
+	ResponseFormat chatResponseFormat `json:"response_format"`
+	Stream         bool               `json:"stream"`
+}
+
+// chatResponseFormat asks the server to constrain output to a JSON schema
+type chatResponseFormat struct {
+	Type       string         `json:"type"`
+	JSONSchema chatJSONSchema `json:"json_schema"`
+}
+
+type chatJSONSchema struct {
+	Name   string                 `json:"name"`
+	Strict bool                   `json:"strict"`
+	Schema map[string]interface{} `json:"schema"`
+}
+
+// chatCompletionResponse matches the parts of the response we use
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// NewOpenAILLMProvider creates a new OpenAI-compatible LLM provider
+func NewOpenAILLMProvider(cfg OpenAILLMConfig) (*OpenAILLMProvider, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("baseURL is required")
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+
+	timeout := 30
+	if cfg.Timeout > 0 {
+		timeout = cfg.Timeout
+	}
+
+	baseURL := strings.TrimSuffix(strings.TrimSuffix(cfg.BaseURL, "/"), "/v1")
+	log.Printf("[LLM] Using OpenAI-compatible server at %s with model %s", baseURL, cfg.Model)
+
+	return &OpenAILLMProvider{
+		baseURL:     baseURL,
+		model:       cfg.Model,
+		temperature: cfg.Temperature,
+		apiKey:      cfg.APIKey,
+		client: &http.Client{
+			Timeout: time.Duration(timeout) * time.Second,
+		},
+	}, nil
+}
+
+// Generate implements LLMProvider.Generate. The constraint's JSON schema is
+// sent as the response format; its GBNF grammar is ignored.
+func (p *OpenAILLMProvider) Generate(ctx context.Context, prompt string, constraint ActionConstraint) ([]byte, error) {
+	req := chatCompletionRequest{
+		Model:       p.model,
+		Messages:    []chatMessage{{Role: "user", Content: prompt}},
+		Temperature: p.temperature,
+		MaxTokens:   128,
+		ResponseFormat: chatResponseFormat{
+			Type: "json_schema",
+			JSONSchema: chatJSONSchema{
+				Name:   "llm_action",
+				Strict: true,
+				Schema: constraint.Schema,
+			},
+		},
+		Stream: false,
+	}
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := p.newRequest(ctx, "POST", "/v1/chat/completions", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("server returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var completionResp chatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&completionResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(completionResp.Choices) == 0 {
+		return nil, fmt.Errorf("response has no choices")
+	}
+
+	return []byte(completionResp.Choices[0].Message.Content), nil
+}
+
+// newRequest builds a request to the server, authenticated if an API key is set
+func (p *OpenAILLMProvider) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	return httpReq, nil
+}
+
+// Name implements LLMProvider.Name
+func (p *OpenAILLMProvider) Name() string {
+	return "openai"
+}
+
+// Available implements LLMProvider.Available
+func (p *OpenAILLMProvider) Available() bool {
+	httpReq, err := p.newRequest(context.Background(), "GET", "/v1/models", nil)
+	if err != nil {
+		return false
+	}
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == 200
+}
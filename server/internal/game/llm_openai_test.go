@@ -0,0 +1,121 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOpenAIStandIn serves /v1/chat/completions with a canned action and
+// records the last request it received
+func newOpenAIStandIn(t *testing.T, content string) (*httptest.Server, *chatCompletionRequest, *http.Header) {
+	t.Helper()
+	var lastReq chatCompletionRequest
+	var lastHeader http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/models":
+			w.WriteHeader(http.StatusOK)
+		case "/v1/chat/completions":
+			lastHeader = r.Header.Clone()
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&lastReq))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"choices": []map[string]interface{}{
+					{"message": map[string]interface{}{"role": "assistant", "content": content}},
+				},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &lastReq, &lastHeader
+}
+
+func TestOpenAILLMProvider_Generate(t *testing.T) {
+	server, lastReq, lastHeader := newOpenAIStandIn(t, `{"action":"idle","mood":"neutral","reason":"resting","ability":null,"target":null,"direction":null}`)
+	provider, err := NewOpenAILLMProvider(OpenAILLMConfig{
+		BaseURL:     server.URL + "/v1/",
+		Model:       "qwen2.5",
+		Temperature: 0.2,
+		APIKey:      "secret",
+	})
+	require.NoError(t, err)
+	assert.True(t, provider.Available())
+
+	snapshot := &StateSnapshot{
+		Abilities: []AbilitySnapshot{{Type: "fireball", Ready: true}, {Type: "frostbolt"}},
+		Enemies:   []EnemySnapshot{{ID: "enemy-3"}},
+	}
+	out, err := provider.Generate(context.Background(), "What now?", snapshot.ActionConstraint())
+	require.NoError(t, err)
+	action, err := ParseLLMAction(out)
+	require.NoError(t, err)
+	assert.Equal(t, "idle", action.Action)
+	assert.Empty(t, action.Ability)
+	assert.Empty(t, action.Target)
+
+	assert.Equal(t, "qwen2.5", lastReq.Model)
+	assert.Equal(t, 0.2, lastReq.Temperature)
+	assert.Equal(t, []chatMessage{{Role: "user", Content: "What now?"}}, lastReq.Messages)
+	assert.Equal(t, "json_schema", lastReq.ResponseFormat.Type)
+	assertStrictSchema(t, lastReq.ResponseFormat.JSONSchema)
+	properties := lastReq.ResponseFormat.JSONSchema.Schema["properties"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"fireball", nil}, properties["ability"].(map[string]interface{})["enum"], "only ready abilities")
+	assert.Equal(t, []interface{}{"nearest", "lowest_hp", "priority", "enemy-3", nil}, properties["target"].(map[string]interface{})["enum"])
+	assert.Equal(t, "Bearer secret", lastHeader.Get("Authorization"))
+}
+
+func TestOpenAILLMProvider_Errors(t *testing.T) {
+	_, err := NewOpenAILLMProvider(OpenAILLMConfig{BaseURL: "http://localhost"})
+	assert.EqualError(t, err, "model is required")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	provider, err := NewOpenAILLMProvider(OpenAILLMConfig{BaseURL: server.URL, Model: "m"})
+	require.NoError(t, err)
+	assert.False(t, provider.Available())
+	_, err = provider.Generate(context.Background(), "What now?", ActionConstraint{})
+	assert.EqualError(t, err, "server returned 503: model not loaded\n")
+}
+
+// assertStrictSchema checks the rules OpenAI enforces on strict schemas:
+// every property is required and no others are allowed
+func assertStrictSchema(t *testing.T, schema chatJSONSchema) {
+	t.Helper()
+	assert.True(t, schema.Strict)
+	assert.Equal(t, false, schema.Schema["additionalProperties"])
+
+	required := make(map[string]bool)
+	for _, name := range schema.Schema["required"].([]interface{}) {
+		required[name.(string)] = true
+	}
+	properties := schema.Schema["properties"].(map[string]interface{})
+	assert.Len(t, required, len(properties))
+	for name := range properties {
+		assert.True(t, required[name], "property %q is not required", name)
+	}
+}
+
+func TestBuildActionJSONSchema(t *testing.T) {
+	schema := BuildActionJSONSchema([]AbilityType{AbilityFireball}, []string{"enemy-1"})
+	properties := schema["properties"].(map[string]interface{})
+	assert.Equal(t, []string{"ability", "dodge", "retreat", "idle"}, properties["action"].(map[string]interface{})["enum"])
+	assert.Equal(t, []interface{}{"fireball", nil}, properties["ability"].(map[string]interface{})["enum"])
+	assert.Equal(t, []interface{}{"nearest", "lowest_hp", "priority", "enemy-1", nil}, properties["target"].(map[string]interface{})["enum"])
+	assert.Equal(t, []string{"string", "null"}, properties["direction"].(map[string]interface{})["type"])
+	assert.Len(t, schema["required"], len(properties))
+
+	// Without abilities the "ability" action is left out, as in the grammar
+	empty := BuildActionJSONSchema(nil, nil)["properties"].(map[string]interface{})
+	assert.Equal(t, []string{"dodge", "retreat", "idle"}, empty["action"].(map[string]interface{})["enum"])
+	assert.Equal(t, []interface{}{nil}, empty["ability"].(map[string]interface{})["enum"])
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseLLMAction_Valid(t *testing.T) {
@@ -139,10 +140,144 @@ func TestLLMActionToAIAction(t *testing.T) {
 		Reason:    "Burn it!",
 	}
 
-	ai := llmAction.ToAIAction(player, enemies)
+	ai, err := llmAction.ToAIAction(player, enemies)
+	require.NoError(t, err)
 	assert.Equal(t, "ability", ai.Type)
 	assert.Equal(t, AbilityFireball, ai.Ability)
 	assert.Equal(t, "e1", ai.TargetID)
 	assert.InDelta(t, 1.0, ai.Direction.X, 0.01) // pointing right toward enemy
 	assert.InDelta(t, 0.0, ai.Direction.Z, 0.01)
 }
+
+func TestLLMActionToAIAction_ResolvesTargets(t *testing.T) {
+	player := &Player{CharAI: NewCharacterAI()}
+	enemies := map[string]*Enemy{
+		"near": {ID: "near", Position: Vector3{X: 2}, Health: 90, MaxHealth: 100},
+		"weak": {ID: "weak", Position: Vector3{Z: 6}, Health: 10, MaxHealth: 100},
+		"dead": {ID: "dead", Position: Vector3{X: 1}, MaxHealth: 100, Dead: true},
+	}
+	player.CharAI.PriorityTargetID = "weak"
+
+	for target, want := range map[string]string{
+		"":          "near",
+		"nearest":   "near",
+		"lowest_hp": "weak",
+		"priority":  "weak",
+		"weak":      "weak",
+	} {
+		ai, err := (&LLMAction{Action: "ability", Ability: "fireball", Target: target, Mood: "neutral"}).ToAIAction(player, enemies)
+		require.NoError(t, err, target)
+		assert.Equal(t, want, ai.TargetID, target)
+	}
+
+	player.CharAI.PriorityTargetID = "gone"
+	ai, err := (&LLMAction{Action: "ability", Target: "priority"}).ToAIAction(player, enemies)
+	require.NoError(t, err)
+	assert.Equal(t, "near", ai.TargetID, "a missing priority target falls back to the nearest")
+}
+
+func TestLLMActionToAIAction_RejectsStaleTargets(t *testing.T) {
+	player := &Player{CharAI: NewCharacterAI()}
+	enemies := map[string]*Enemy{
+		"dead": {ID: "dead", Position: Vector3{X: 1}, MaxHealth: 100, Dead: true},
+	}
+
+	_, err := (&LLMAction{Action: "ability", Target: "dead"}).ToAIAction(player, enemies)
+	assert.EqualError(t, err, `target "dead" is not a live enemy nearby`)
+
+	_, err = (&LLMAction{Action: "ability", Target: "skeleton_7"}).ToAIAction(player, enemies)
+	assert.EqualError(t, err, `target "skeleton_7" is not a live enemy nearby`)
+
+	_, err = (&LLMAction{Action: "ability", Target: "nearest"}).ToAIAction(player, enemies)
+	assert.EqualError(t, err, `no live enemies for target "nearest"`)
+
+	ai, err := (&LLMAction{Action: "idle", Target: "dead", Mood: "anxious"}).ToAIAction(player, enemies)
+	require.NoError(t, err, "idling needs no target")
+	assert.Empty(t, ai.TargetID)
+}
+
+func TestLLMActionToAIAction_Directions(t *testing.T) {
+	player := &Player{CharAI: NewCharacterAI()}
+	enemies := map[string]*Enemy{
+		"e1": {ID: "e1", Position: Vector3{X: 4}, Health: 50, MaxHealth: 100},
+	}
+
+	for direction, want := range map[string]Vector3{
+		"toward_target":    {X: 1},
+		"away_from_target": {X: -1},
+		"left":             {Z: 1},
+		"right":            {Z: -1},
+	} {
+		ai, err := (&LLMAction{Action: "ability", Direction: direction}).ToAIAction(player, enemies)
+		require.NoError(t, err, direction)
+		assert.InDelta(t, want.X, ai.Direction.X, 0.001, direction)
+		assert.InDelta(t, want.Z, ai.Direction.Z, 0.001, direction)
+	}
+
+	for _, action := range []string{"dodge", "retreat"} {
+		ai, err := (&LLMAction{Action: action}).ToAIAction(player, enemies)
+		require.NoError(t, err, action)
+		assert.InDelta(t, -1, ai.Direction.X, 0.001, "%s moves away by default", action)
+	}
+
+	_, err := (&LLMAction{Action: "ability", Direction: "up"}).ToAIAction(player, enemies)
+	assert.EqualError(t, err, `unknown direction "up"`)
+}
+
+func TestLLMActionToAIAction_RejectsUnknownAbility(t *testing.T) {
+	player := NewPlayer("p1", "One")
+	enemies := map[string]*Enemy{
+		"e1": {ID: "e1", Position: Vector3{X: 4}, Health: 50, MaxHealth: 100},
+	}
+
+	_, err := (&LLMAction{Action: "ability", Ability: "meteor"}).ToAIAction(player, enemies)
+	assert.EqualError(t, err, `unknown ability "meteor"`)
+}
+
+func TestStateSnapshotActionGBNF(t *testing.T) {
+	snap := &StateSnapshot{
+		Abilities: []AbilitySnapshot{
+			{Type: "fireball", Ready: true},
+			{Type: "frostbolt", Ready: false},
+		},
+		Enemies: []EnemySnapshot{{ID: "enemy-3"}, {ID: "enemy-9"}},
+	}
+
+	grammar := snap.ActionGBNF()
+	assert.Contains(t, grammar, `ability-val   ::= "\"fireball\""`+"\n", "only ready abilities")
+	assert.Contains(t, grammar, `target-val    ::= "\"nearest\"" | "\"lowest_hp\"" | "\"priority\"" | "\"enemy-3\"" | "\"enemy-9\""`+"\n")
+
+	snap.Abilities[0].Ready = false
+	grammar = snap.ActionGBNF()
+	assert.NotContains(t, grammar, "ability-val", "nothing ready, nothing to cast")
+	assert.Contains(t, grammar, `action-val    ::= "\"dodge\"" | "\"retreat\"" | "\"idle\""`)
+
+	snap.Enemies = nil
+	assert.Equal(t, idleGBNF, snap.ActionGBNF())
+}
+
+func TestStateSnapshotActionJSONSchema(t *testing.T) {
+	snap := &StateSnapshot{
+		Abilities: []AbilitySnapshot{
+			{Type: "fireball", Ready: true},
+			{Type: "frostbolt", Ready: false},
+		},
+		Enemies: []EnemySnapshot{{ID: "enemy-3"}},
+	}
+
+	properties := snap.ActionJSONSchema()["properties"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"fireball", nil}, properties["ability"].(map[string]interface{})["enum"], "only ready abilities")
+	assert.Equal(t, []interface{}{"nearest", "lowest_hp", "priority", "enemy-3", nil}, properties["target"].(map[string]interface{})["enum"])
+
+	snap.Enemies = nil
+	idle := snap.ActionJSONSchema()
+	properties = idle["properties"].(map[string]interface{})
+	assert.Equal(t, []string{"idle"}, properties["action"].(map[string]interface{})["enum"])
+	assert.Equal(t, []interface{}{nil}, properties["target"].(map[string]interface{})["enum"])
+	assert.Len(t, idle["required"], len(properties))
+}
+
+func TestBuildActionGBNF_EscapesEnemyIDs(t *testing.T) {
+	grammar := BuildActionGBNF(nil, []string{`odd"id`})
+	assert.Contains(t, grammar, `"\"odd\\\"id\""`)
+}
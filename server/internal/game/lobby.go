@@ -34,6 +34,7 @@ type GameListing struct {
 	CurrentCount int            `json:"currentCount"`
 	Created      time.Time      `json:"created"`
 	WorldID      string         `json:"worldID"`
+	Rules        GameRules      `json:"rules"`
 }
 
 // JoinRequest represents a request to join a private game
@@ -66,8 +67,8 @@ func NewLobbyService() *LobbyService {
 	}
 }
 
-// CreateGame creates a new game listing
-func (ls *LobbyService) CreateGame(hostID, hostName, name string, visibility GameVisibility, maxPlayers int) (*GameListing, error) {
+// CreateGame creates a new game listing with the given combat ruleset
+func (ls *LobbyService) CreateGame(hostID, hostName, name string, visibility GameVisibility, maxPlayers int, rules GameRules) (*GameListing, error) {
 	ls.mu.Lock()
 	defer ls.mu.Unlock()
 
@@ -96,6 +97,7 @@ func (ls *LobbyService) CreateGame(hostID, hostName, name string, visibility Gam
 		CurrentCount: 0,
 		Created:      time.Now(),
 		WorldID:      worldID,
+		Rules:        rules,
 	}
 
 	ls.games[game.ID] = game
@@ -299,6 +301,7 @@ func (gl *GameListing) Serialize() map[string]interface{} {
 		"currentCount": gl.CurrentCount,
 		"created":      gl.Created.Unix(),
 		"worldID":      gl.WorldID,
+		"rules":        gl.Rules.Serialize(),
 	}
 }
 
@@ -20,7 +20,7 @@ func TestNewLobbyService(t *testing.T) {
 func TestCreateGame(t *testing.T) {
 	ls := NewLobbyService()
 
-	game, err := ls.CreateGame("player1", "TestPlayer", "Test Game", GameVisibilityPublic, 4)
+	game, err := ls.CreateGame("player1", "TestPlayer", "Test Game", GameVisibilityPublic, 4, DefaultGameRules())
 
 	require.NoError(t, err)
 	require.NotNil(t, game)
@@ -37,7 +37,7 @@ func TestCreateGame(t *testing.T) {
 func TestCreateGameDefaultName(t *testing.T) {
 	ls := NewLobbyService()
 
-	game, err := ls.CreateGame("player1", "TestPlayer", "", GameVisibilityPublic, 4)
+	game, err := ls.CreateGame("player1", "TestPlayer", "", GameVisibilityPublic, 4, DefaultGameRules())
 
 	require.NoError(t, err)
 	assert.Equal(t, "TestPlayer's Game", game.Name)
@@ -47,13 +47,13 @@ func TestCreateGameMaxPlayersLimit(t *testing.T) {
 	ls := NewLobbyService()
 
 	// Test max players capped at 8
-	game, err := ls.CreateGame("player1", "TestPlayer", "Big Game", GameVisibilityPublic, 100)
+	game, err := ls.CreateGame("player1", "TestPlayer", "Big Game", GameVisibilityPublic, 100, DefaultGameRules())
 
 	require.NoError(t, err)
 	assert.Equal(t, 8, game.MaxPlayers)
 
 	// Test min players at 1
-	game2, err := ls.CreateGame("player2", "TestPlayer2", "Small Game", GameVisibilityPublic, 0)
+	game2, err := ls.CreateGame("player2", "TestPlayer2", "Small Game", GameVisibilityPublic, 0, DefaultGameRules())
 
 	require.NoError(t, err)
 	assert.Equal(t, 4, game2.MaxPlayers) // Default to 4 when 0 or negative
@@ -63,7 +63,7 @@ func TestListGames(t *testing.T) {
 	ls := NewLobbyService()
 
 	// Create public game
-	publicGame, err := ls.CreateGame("player1", "Player1", "Public Game", GameVisibilityPublic, 4)
+	publicGame, err := ls.CreateGame("player1", "Player1", "Public Game", GameVisibilityPublic, 4, DefaultGameRules())
 	require.NoError(t, err)
 	require.NotNil(t, publicGame)
 
@@ -71,7 +71,7 @@ func TestListGames(t *testing.T) {
 	time.Sleep(time.Millisecond)
 
 	// Create private game
-	privateGame, err := ls.CreateGame("player2", "Player2", "Private Game", GameVisibilityPrivate, 4)
+	privateGame, err := ls.CreateGame("player2", "Player2", "Private Game", GameVisibilityPrivate, 4, DefaultGameRules())
 	require.NoError(t, err)
 	require.NotNil(t, privateGame)
 
@@ -96,7 +96,7 @@ func TestListGames(t *testing.T) {
 func TestGetGame(t *testing.T) {
 	ls := NewLobbyService()
 
-	game, _ := ls.CreateGame("player1", "TestPlayer", "Test Game", GameVisibilityPublic, 4)
+	game, _ := ls.CreateGame("player1", "TestPlayer", "Test Game", GameVisibilityPublic, 4, DefaultGameRules())
 
 	retrieved, ok := ls.GetGame(game.ID)
 
@@ -111,7 +111,7 @@ func TestGetGame(t *testing.T) {
 func TestUpdatePlayerCount(t *testing.T) {
 	ls := NewLobbyService()
 
-	game, _ := ls.CreateGame("player1", "TestPlayer", "Test Game", GameVisibilityPublic, 4)
+	game, _ := ls.CreateGame("player1", "TestPlayer", "Test Game", GameVisibilityPublic, 4, DefaultGameRules())
 	assert.Equal(t, 0, game.CurrentCount)
 
 	ls.UpdatePlayerCount(game.ID, 3)
@@ -123,7 +123,7 @@ func TestUpdatePlayerCount(t *testing.T) {
 func TestRemoveGame(t *testing.T) {
 	ls := NewLobbyService()
 
-	game, _ := ls.CreateGame("player1", "TestPlayer", "Test Game", GameVisibilityPublic, 4)
+	game, _ := ls.CreateGame("player1", "TestPlayer", "Test Game", GameVisibilityPublic, 4, DefaultGameRules())
 
 	ls.RemoveGame(game.ID)
 
@@ -135,7 +135,7 @@ func TestRequestJoin(t *testing.T) {
 	ls := NewLobbyService()
 
 	// Create private game
-	game, _ := ls.CreateGame("host", "HostPlayer", "Private Game", GameVisibilityPrivate, 4)
+	game, _ := ls.CreateGame("host", "HostPlayer", "Private Game", GameVisibilityPrivate, 4, DefaultGameRules())
 
 	// Request to join
 	request, err := ls.RequestJoin(game.ID, "joiner", "JoinerPlayer")
@@ -152,7 +152,7 @@ func TestRequestJoinPublicGame(t *testing.T) {
 	ls := NewLobbyService()
 
 	// Create public game
-	game, _ := ls.CreateGame("host", "HostPlayer", "Public Game", GameVisibilityPublic, 4)
+	game, _ := ls.CreateGame("host", "HostPlayer", "Public Game", GameVisibilityPublic, 4, DefaultGameRules())
 
 	// Request to join public game should fail
 	_, err := ls.RequestJoin(game.ID, "joiner", "JoinerPlayer")
@@ -164,7 +164,7 @@ func TestRequestJoinPublicGame(t *testing.T) {
 func TestRequestJoinDuplicate(t *testing.T) {
 	ls := NewLobbyService()
 
-	game, _ := ls.CreateGame("host", "HostPlayer", "Private Game", GameVisibilityPrivate, 4)
+	game, _ := ls.CreateGame("host", "HostPlayer", "Private Game", GameVisibilityPrivate, 4, DefaultGameRules())
 
 	// First request
 	ls.RequestJoin(game.ID, "joiner", "JoinerPlayer")
@@ -179,7 +179,7 @@ func TestRequestJoinDuplicate(t *testing.T) {
 func TestRequestJoinFullGame(t *testing.T) {
 	ls := NewLobbyService()
 
-	game, _ := ls.CreateGame("host", "HostPlayer", "Private Game", GameVisibilityPrivate, 2)
+	game, _ := ls.CreateGame("host", "HostPlayer", "Private Game", GameVisibilityPrivate, 2, DefaultGameRules())
 	ls.UpdatePlayerCount(game.ID, 2) // Game is now full
 
 	_, err := ls.RequestJoin(game.ID, "joiner", "JoinerPlayer")
@@ -191,7 +191,7 @@ func TestRequestJoinFullGame(t *testing.T) {
 func TestRespondToRequest(t *testing.T) {
 	ls := NewLobbyService()
 
-	game, _ := ls.CreateGame("host", "HostPlayer", "Private Game", GameVisibilityPrivate, 4)
+	game, _ := ls.CreateGame("host", "HostPlayer", "Private Game", GameVisibilityPrivate, 4, DefaultGameRules())
 	request, _ := ls.RequestJoin(game.ID, "joiner", "JoinerPlayer")
 
 	// Approve request
@@ -205,7 +205,7 @@ func TestRespondToRequest(t *testing.T) {
 func TestRespondToRequestDeny(t *testing.T) {
 	ls := NewLobbyService()
 
-	game, _ := ls.CreateGame("host", "HostPlayer", "Private Game", GameVisibilityPrivate, 4)
+	game, _ := ls.CreateGame("host", "HostPlayer", "Private Game", GameVisibilityPrivate, 4, DefaultGameRules())
 	request, _ := ls.RequestJoin(game.ID, "joiner", "JoinerPlayer")
 
 	// Deny request
@@ -218,7 +218,7 @@ func TestRespondToRequestDeny(t *testing.T) {
 func TestRespondToRequestAlreadyProcessed(t *testing.T) {
 	ls := NewLobbyService()
 
-	game, _ := ls.CreateGame("host", "HostPlayer", "Private Game", GameVisibilityPrivate, 4)
+	game, _ := ls.CreateGame("host", "HostPlayer", "Private Game", GameVisibilityPrivate, 4, DefaultGameRules())
 	request, _ := ls.RequestJoin(game.ID, "joiner", "JoinerPlayer")
 
 	// Process first time
@@ -234,7 +234,7 @@ func TestRespondToRequestAlreadyProcessed(t *testing.T) {
 func TestGetPendingRequests(t *testing.T) {
 	ls := NewLobbyService()
 
-	game, _ := ls.CreateGame("host", "HostPlayer", "Private Game", GameVisibilityPrivate, 4)
+	game, _ := ls.CreateGame("host", "HostPlayer", "Private Game", GameVisibilityPrivate, 4, DefaultGameRules())
 
 	// Add multiple requests
 	ls.RequestJoin(game.ID, "joiner1", "Joiner1")
@@ -253,13 +253,13 @@ func TestCanJoinGame(t *testing.T) {
 	ls := NewLobbyService()
 
 	// Test public game
-	publicGame, _ := ls.CreateGame("host", "HostPlayer", "Public Game", GameVisibilityPublic, 4)
+	publicGame, _ := ls.CreateGame("host", "HostPlayer", "Public Game", GameVisibilityPublic, 4, DefaultGameRules())
 
 	canJoin, _ := ls.CanJoinGame(publicGame.ID, "anyone")
 	assert.True(t, canJoin)
 
 	// Test private game without request
-	privateGame, _ := ls.CreateGame("host2", "HostPlayer2", "Private Game", GameVisibilityPrivate, 4)
+	privateGame, _ := ls.CreateGame("host2", "HostPlayer2", "Private Game", GameVisibilityPrivate, 4, DefaultGameRules())
 
 	canJoin, reason := ls.CanJoinGame(privateGame.ID, "anyone")
 	assert.False(t, canJoin)
@@ -280,7 +280,7 @@ func TestCanJoinGame(t *testing.T) {
 func TestCanJoinFullGame(t *testing.T) {
 	ls := NewLobbyService()
 
-	game, _ := ls.CreateGame("host", "HostPlayer", "Full Game", GameVisibilityPublic, 2)
+	game, _ := ls.CreateGame("host", "HostPlayer", "Full Game", GameVisibilityPublic, 2, DefaultGameRules())
 	ls.UpdatePlayerCount(game.ID, 2)
 
 	canJoin, reason := ls.CanJoinGame(game.ID, "anyone")
@@ -291,7 +291,7 @@ func TestCanJoinFullGame(t *testing.T) {
 func TestGameListingSerialize(t *testing.T) {
 	ls := NewLobbyService()
 
-	game, _ := ls.CreateGame("player1", "TestPlayer", "Test Game", GameVisibilityPublic, 4)
+	game, _ := ls.CreateGame("player1", "TestPlayer", "Test Game", GameVisibilityPublic, 4, DefaultGameRules())
 	game.CurrentCount = 2
 
 	data := game.Serialize()
@@ -305,10 +305,22 @@ func TestGameListingSerialize(t *testing.T) {
 	assert.Equal(t, 2, data["currentCount"])
 }
 
+func TestCreateGameWithRules(t *testing.T) {
+	ls := NewLobbyService()
+
+	rules := GameRules{PvP: PvPZones, FriendlyFire: true, DifficultyTier: 2}
+	game, err := ls.CreateGame("host", "HostPlayer", "Duel", GameVisibilityPublic, 2, rules)
+	require.NoError(t, err)
+	assert.Equal(t, rules, game.Rules)
+
+	data := game.Serialize()
+	assert.Equal(t, map[string]interface{}{"pvp": "zones", "friendlyFire": true, "difficultyTier": 2}, data["rules"])
+}
+
 func TestJoinRequestSerialize(t *testing.T) {
 	ls := NewLobbyService()
 
-	game, _ := ls.CreateGame("host", "HostPlayer", "Private Game", GameVisibilityPrivate, 4)
+	game, _ := ls.CreateGame("host", "HostPlayer", "Private Game", GameVisibilityPrivate, 4, DefaultGameRules())
 	request, _ := ls.RequestJoin(game.ID, "joiner", "JoinerPlayer")
 
 	data := request.Serialize()
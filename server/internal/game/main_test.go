@@ -14,6 +14,9 @@ func TestMain(m *testing.M) {
 			Health:    100,
 			MaxHealth: 100,
 			MoveSpeed: 5,
+			Mana:      100,
+			MaxMana:   100,
+			ManaRegen: 5,
 		},
 	}
 
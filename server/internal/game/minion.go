@@ -12,6 +12,13 @@ const (
 	MinionTypeTurret MinionType = "turret"
 )
 
+// Minion health. Enemies can target minions, so they soak a few hits for
+// their owner but don't hold aggro forever.
+const (
+	petMaxHealth    = 60.0
+	turretMaxHealth = 40.0
+)
+
 // Minion represents a summoned entity (Pet or Turret)
 type Minion struct {
 	ID           string
@@ -25,6 +32,8 @@ type Minion struct {
 	Lifetime     float64 // Duration in seconds
 	CastInterval float64 // Time between casts
 	LastCast     time.Time
+	Health       float64
+	MaxHealth    float64
 
 	// Pet specific
 	FollowSpeed  float64 // Movement speed for pets
@@ -45,6 +54,8 @@ func NewPet(id, ownerID string, position Vector3, ability *Ability, abilityType
 		Lifetime:     modifier.MinionDuration,
 		CastInterval: modifier.CastInterval,
 		LastCast:     time.Now(),
+		Health:       petMaxHealth,
+		MaxHealth:    petMaxHealth,
 		FollowSpeed:  3.0, // Slightly slower than player
 		FollowRange:  3.0, // Stay within 3 units of owner
 	}
@@ -64,6 +75,8 @@ func NewTurret(id, ownerID string, position Vector3, ability *Ability, abilityTy
 		Lifetime:     modifier.MinionDuration,
 		CastInterval: modifier.CastInterval,
 		LastCast:     time.Now(),
+		Health:       turretMaxHealth,
+		MaxHealth:    turretMaxHealth,
 		FollowSpeed:  0, // Turrets don't move
 		FollowRange:  0,
 	}
@@ -97,39 +110,48 @@ func (m *Minion) Update(delta float64, ownerPosition Vector3) {
 	// Turrets don't move, so no update needed
 }
 
-// CanCast checks if the minion can cast its ability
-func (m *Minion) CanCast() bool {
-	return time.Since(m.LastCast).Seconds() >= m.CastInterval
+// CanCast checks if the minion can cast its ability at the given time
+func (m *Minion) CanCast(now time.Time) bool {
+	return now.Sub(m.LastCast).Seconds() >= m.CastInterval
 }
 
-// MarkCasted marks that the minion has just casted
-func (m *Minion) MarkCasted() {
-	m.LastCast = time.Now()
+// MarkCasted marks that the minion cast at the given time
+func (m *Minion) MarkCasted(now time.Time) {
+	m.LastCast = now
 }
 
-// ShouldDestroy returns true if the minion should be removed
-func (m *Minion) ShouldDestroy() bool {
-	return time.Since(m.CreatedAt).Seconds() > m.Lifetime
+// TakeDamage applies damage and returns true if it destroyed the minion
+func (m *Minion) TakeDamage(damage DamageInfo) bool {
+	if m.IsDead() {
+		return false
+	}
+
+	m.Health -= damage.Amount
+	if m.Health <= 0 {
+		m.Health = 0
+		return true
+	}
+	return false
 }
 
-// FindNearestEnemy finds the nearest enemy to the minion within range
-func (m *Minion) FindNearestEnemy(enemies map[string]*Enemy, maxRange float64) *Enemy {
-	var nearest *Enemy
-	minDistance := maxRange
+// GetHealth returns current health
+func (m *Minion) GetHealth() float64 {
+	return m.Health
+}
 
-	for _, enemy := range enemies {
-		if enemy.IsDead() {
-			continue
-		}
+// GetMaxHealth returns maximum health
+func (m *Minion) GetMaxHealth() float64 {
+	return m.MaxHealth
+}
 
-		distance := Distance2D(m.Position, enemy.Position)
-		if distance < minDistance {
-			minDistance = distance
-			nearest = enemy
-		}
-	}
+// IsDead returns true if the minion has been destroyed
+func (m *Minion) IsDead() bool {
+	return m.Health <= 0
+}
 
-	return nearest
+// ShouldDestroy returns true if the minion should be removed by the given time
+func (m *Minion) ShouldDestroy(now time.Time) bool {
+	return now.Sub(m.CreatedAt).Seconds() > m.Lifetime
 }
 
 // GetDirectionTo returns a normalized direction vector to a target position
@@ -151,5 +173,7 @@ func (m *Minion) Serialize() map[string]interface{} {
 		"position":    m.Position,
 		"velocity":    m.Velocity,
 		"abilityType": string(m.AbilityType),
+		"health":      m.Health,
+		"maxHealth":   m.MaxHealth,
 	}
 }
@@ -0,0 +1,72 @@
+package game
+
+import (
+	"math"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+)
+
+// monsterLevelFor returns the level of an enemy spawned in a tile of the
+// given difficulty at a world difficulty tier
+func monsterLevelFor(difficulty, tier int) int {
+	cfg := config.Enemies.Levels
+	level := 1 + int(math.Floor(float64(max(difficulty, 0))*cfg.LevelsPerDifficulty)) + max(tier, 0)*cfg.LevelsPerTier
+	if cfg.MaxLevel > 0 && level > cfg.MaxLevel {
+		level = cfg.MaxLevel
+	}
+	return level
+}
+
+// monsterLevel returns the level of enemies spawned in a tile of the given
+// difficulty in this world
+func (w *World) monsterLevel(difficulty int) int {
+	return monsterLevelFor(difficulty, w.Rules.DifficultyTier)
+}
+
+// levelCurveValue evaluates a level curve, which is 1 at level 1. Unset
+// curves stay at 1.
+func levelCurveValue(curve config.LevelCurveConfig, level int) float64 {
+	steps := float64(max(level, 1) - 1)
+	if curve.Curve == "exponential" {
+		return math.Pow(1+curve.PerLevel, steps)
+	}
+	return 1 + curve.PerLevel*steps
+}
+
+// applyLevel sets an enemy's monster level and scales its config stats to
+// match. Call it once, right after NewEnemy and before elite modifiers.
+func (e *Enemy) applyLevel(level int) {
+	if level < 1 {
+		level = 1
+	}
+	e.Level = level
+	cfg := config.Enemies.Levels
+	healthMult := levelCurveValue(cfg.Health, level)
+	damageMult := levelCurveValue(cfg.Damage, level)
+
+	e.MaxHealth *= healthMult
+	e.Health = e.MaxHealth
+	e.Damage *= damageMult
+	if e.AI != nil {
+		e.AI.ExplosionDamage *= damageMult
+		if e.AI.Boss != nil {
+			e.AI.Boss.baseDamage *= damageMult
+			e.AI.Boss.levelDamage = damageMult
+		}
+	}
+}
+
+// xpMultiplier returns how much the enemy's level scales its experience reward
+func (e *Enemy) xpMultiplier() float64 {
+	return levelCurveValue(config.Enemies.Levels.XP, e.Level)
+}
+
+// lootItemLevel returns the level of items the enemy drops. Without an item
+// level curve, items drop at the enemy's own level.
+func (e *Enemy) lootItemLevel() int {
+	curve := config.Enemies.Levels.ItemLevel
+	if curve.Curve == "" && curve.PerLevel == 0 {
+		return max(e.Level, 1)
+	}
+	return max(int(math.Round(levelCurveValue(curve, e.Level))), 1)
+}
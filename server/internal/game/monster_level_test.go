@@ -0,0 +1,87 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withLevelConfig installs a known monster level section for the duration of a test
+func withLevelConfig(t *testing.T) {
+	t.Helper()
+	previous := config.Enemies.Levels
+	config.Enemies.Levels = config.MonsterLevelConfig{
+		LevelsPerDifficulty: 2,
+		LevelsPerTier:       10,
+		MaxTier:             3,
+		MaxLevel:            25,
+		Health:              config.LevelCurveConfig{Curve: "exponential", PerLevel: 0.1},
+		Damage:              config.LevelCurveConfig{Curve: "linear", PerLevel: 0.5},
+		XP:                  config.LevelCurveConfig{Curve: "linear", PerLevel: 1},
+	}
+	t.Cleanup(func() { config.Enemies.Levels = previous })
+}
+
+func TestMonsterLevelFor(t *testing.T) {
+	withLevelConfig(t)
+
+	assert.Equal(t, 1, monsterLevelFor(0, 0))
+	assert.Equal(t, 7, monsterLevelFor(3, 0))
+	assert.Equal(t, 17, monsterLevelFor(3, 1))
+	assert.Equal(t, 25, monsterLevelFor(3, 3), "capped at maxLevel")
+}
+
+func TestLevelCurveValue(t *testing.T) {
+	linear := config.LevelCurveConfig{Curve: "linear", PerLevel: 0.5}
+	assert.Equal(t, 1.0, levelCurveValue(linear, 1))
+	assert.Equal(t, 3.0, levelCurveValue(linear, 5))
+
+	exponential := config.LevelCurveConfig{Curve: "exponential", PerLevel: 1}
+	assert.Equal(t, 8.0, levelCurveValue(exponential, 4))
+
+	assert.Equal(t, 1.0, levelCurveValue(config.LevelCurveConfig{}, 30), "unset curves don't scale")
+}
+
+func TestApplyLevel_ScalesStatsAndRewards(t *testing.T) {
+	withLevelConfig(t)
+	withEnemyConfig(t, "test_zombie", config.EnemyConfig{Name: "Zombie", XPReward: 10})
+	world := newTestWorldWithoutEnemies()
+	player := NewPlayer("p1", "One")
+	world.players[player.ID] = player
+
+	enemy := createTestEnemy("e1", "test_zombie", Vector3{}, createTestEnemyConfig("melee", 10))
+	enemy.Health = 20
+	enemy.applyLevel(3)
+	assert.Equal(t, 3, enemy.Level)
+	assert.InDelta(t, 121.0, enemy.MaxHealth, 0.001)
+	assert.Equal(t, enemy.MaxHealth, enemy.Health, "spawns at full scaled health")
+	assert.Equal(t, 20.0, enemy.Damage)
+	assert.Equal(t, 3, enemy.lootItemLevel(), "items drop at the monster's level without a curve")
+
+	world.awardExperience(player.ID, enemy)
+	assert.Equal(t, 30.0, player.Experience)
+}
+
+func TestSpawnTileEnemies_UsesTileDifficultyAndTier(t *testing.T) {
+	withLevelConfig(t)
+	world, tile, _ := newLeashTestWorld(t)
+	tile.Difficulty = 2
+	world.Rules.DifficultyTier = 1
+
+	world.checkTileRespawns()
+	require.NotEmpty(t, world.enemies)
+	for _, enemy := range world.enemies {
+		assert.Equal(t, 15, enemy.Level)
+		assert.Equal(t, 15, enemy.Serialize()["level"])
+	}
+}
+
+func TestParseDifficultyTier(t *testing.T) {
+	withLevelConfig(t)
+
+	assert.Equal(t, 0, ParseDifficultyTier(-1))
+	assert.Equal(t, 2, ParseDifficultyTier(2))
+	assert.Equal(t, 3, ParseDifficultyTier(9))
+}
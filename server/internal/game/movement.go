@@ -0,0 +1,96 @@
+package game
+
+import (
+	"math"
+	"time"
+)
+
+// movementStep is the distance between samples when validating a movement path
+const movementStep = 0.25
+
+// ForcedMovement is a server-driven displacement from a movement ability.
+// While active it overrides the player's input velocity.
+type ForcedMovement struct {
+	Ability   *Ability
+	From      Vector3
+	To        Vector3
+	StartedAt time.Time
+	Duration  float64 // Seconds
+}
+
+// Progress returns how far through the movement we are at the given time, from 0 to 1
+func (m *ForcedMovement) Progress(now time.Time) float64 {
+	if m.Duration <= 0 {
+		return 1
+	}
+	return math.Min(now.Sub(m.StartedAt).Seconds()/m.Duration, 1)
+}
+
+// Position returns the interpolated position along the movement at the given time
+func (m *ForcedMovement) Position(now time.Time) Vector3 {
+	t := m.Progress(now)
+	return Vector3{
+		X: m.From.X + (m.To.X-m.From.X)*t,
+		Y: m.From.Y + (m.To.Y-m.From.Y)*t,
+		Z: m.From.Z + (m.To.Z-m.From.Z)*t,
+	}
+}
+
+// ClampMovement walks from one position toward another and returns the
+// furthest valid point. Movement stops at closed tile edges and the edge of
+// the board. If checkTerrain is true the path stops at blocking features;
+// otherwise only the final position must be clear (for blinks and leaps).
+func (b *Board) ClampMovement(from, to Vector3, layer int, checkTerrain bool) Vector3 {
+	dist := Distance2D(from, to)
+	if dist < 0.001 {
+		return from
+	}
+
+	steps := int(math.Ceil(dist / movementStep))
+	lastValid := from
+	lastCoord := WorldToHex(from, layer)
+
+	// Don't trap a player who is already overlapping a feature
+	inStartFeature := b.isBlockedByTerrain(from, layer)
+
+	path := make([]Vector3, 0, steps)
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		point := Vector3{
+			X: from.X + (to.X-from.X)*t,
+			Y: from.Y,
+			Z: from.Z + (to.Z-from.Z)*t,
+		}
+
+		coord := WorldToHex(point, layer)
+		if !b.canCrossEdge(lastCoord, coord) {
+			break
+		}
+		lastCoord = coord
+
+		if checkTerrain {
+			blocked := b.isBlockedByTerrain(point, layer)
+			if blocked && !inStartFeature {
+				break
+			}
+			if !blocked {
+				inStartFeature = false
+				lastValid = point
+			}
+		} else {
+			path = append(path, point)
+		}
+	}
+
+	if checkTerrain {
+		return lastValid
+	}
+
+	// Land at the furthest reachable point that isn't inside terrain
+	for i := len(path) - 1; i >= 0; i-- {
+		if !b.isBlockedByTerrain(path[i], layer) {
+			return path[i]
+		}
+	}
+	return from
+}
@@ -0,0 +1,153 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBoard creates a board with the origin tile and its east neighbor.
+// The edge between them is open only if connected is true.
+func newTestBoard(connected bool) *Board {
+	origin := NewTile(HexCoord{Q: 0, R: 0}, "grassland", TileTypeOverworld, 1)
+	east := NewTile(HexCoord{Q: 1, R: 0}, "grassland", TileTypeOverworld, 1)
+	origin.Generated = true
+	east.Generated = true
+	origin.EdgePaths[0] = connected
+	east.EdgePaths[3] = connected
+
+	return &Board{Tiles: map[HexCoord]*Tile{
+		origin.Coord: origin,
+		east.Coord:   east,
+	}}
+}
+
+func TestClampMovement_ClosedEdgeStopsMovement(t *testing.T) {
+	board := newTestBoard(false)
+	from := HexToWorld(HexCoord{Q: 0, R: 0})
+	to := HexToWorld(HexCoord{Q: 1, R: 0})
+
+	end := board.ClampMovement(from, to, 0, true)
+
+	assert.Equal(t, HexCoord{Q: 0, R: 0}, WorldToHex(end, 0), "should stop inside the starting tile")
+	assert.Greater(t, Distance2D(from, end), 5.0, "should still travel up to the edge")
+}
+
+func TestClampMovement_OpenEdgeAllowsCrossing(t *testing.T) {
+	board := newTestBoard(true)
+	from := HexToWorld(HexCoord{Q: 0, R: 0})
+	to := HexToWorld(HexCoord{Q: 1, R: 0})
+
+	end := board.ClampMovement(from, to, 0, true)
+
+	assert.InDelta(t, to.X, end.X, 0.001)
+	assert.InDelta(t, to.Z, end.Z, 0.001)
+}
+
+func TestClampMovement_BoardEdge(t *testing.T) {
+	board := newTestBoard(true)
+	from := HexToWorld(HexCoord{Q: 0, R: 0})
+	to := Vector3{X: from.X - 50, Z: from.Z}
+
+	end := board.ClampMovement(from, to, 0, true)
+
+	assert.Equal(t, HexCoord{Q: 0, R: 0}, WorldToHex(end, 0), "should not leave the board")
+}
+
+func TestClampMovement_TerrainBlocksDash(t *testing.T) {
+	board := newTestBoard(false)
+	tile := board.GetTile(HexCoord{Q: 0, R: 0})
+	tile.Features = append(tile.Features, TerrainFeature{
+		Type:     FeatureRockLarge,
+		Position: Vector3{X: 5, Z: 0},
+		Scale:    1.0,
+	})
+
+	end := board.ClampMovement(Vector3{}, Vector3{X: 8}, 0, true)
+	assert.Less(t, end.X, 5.0-1.2+0.001, "dash should stop in front of the rock")
+
+	// Blinks pass over terrain as long as they land somewhere clear
+	end = board.ClampMovement(Vector3{}, Vector3{X: 8}, 0, false)
+	assert.InDelta(t, 8.0, end.X, 0.001)
+
+	// Landing inside the rock backs off to the nearest clear point
+	end = board.ClampMovement(Vector3{}, Vector3{X: 5}, 0, false)
+	assert.False(t, board.isBlockedByTerrain(end, 0))
+	assert.Less(t, end.X, 5.0)
+}
+
+func TestCastPlayerAbility_BlinkClampedToRange(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	player := NewPlayer("player-1", "TestUser")
+	world.players[player.ID] = player
+
+	blink := &Ability{Type: "blink", Shape: AbilityShapeBlink, Range: 6}
+	target := Vector3{X: 20}
+	result := world.CastPlayerAbility(player, blink, Vector3{X: 1}, CastOptions{TargetPosition: &target})
+
+	require.NotNil(t, result.EndPosition)
+	assert.InDelta(t, 6.0, player.Position.X, 0.001)
+	assert.Nil(t, player.Movement, "blink should be instant")
+}
+
+func TestCastPlayerAbility_LeapLandsWithAoE(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	player := NewPlayer("player-1", "TestUser")
+	world.players[player.ID] = player
+
+	enemy := NewEnemy("enemy-1", "basic", Vector3{X: 6, Z: 1})
+	world.enemies[enemy.ID] = enemy
+
+	leap := &Ability{
+		Type:         "leap",
+		Shape:        AbilityShapeLeap,
+		Damage:       20,
+		DamageType:   DamageTypePhysical,
+		Range:        10,
+		AreaRadius:   2,
+		MoveDuration: 0.5,
+	}
+	target := Vector3{X: 6}
+	world.CastPlayerAbility(player, leap, Vector3{X: 1}, CastOptions{TargetPosition: &target})
+
+	require.NotNil(t, player.Movement)
+	assert.Equal(t, enemy.MaxHealth, enemy.Health, "no damage until landing")
+
+	// Input velocity is ignored mid-leap
+	player.Velocity = Vector3{Z: 1}
+	player.Movement.StartedAt = time.Now().Add(-time.Second)
+	player.Update(0.1)
+
+	assert.Nil(t, player.Movement)
+	assert.InDelta(t, 6.0, player.Position.X, 0.001)
+	assert.InDelta(t, 0.0, player.Position.Z, 0.001)
+
+	landed := player.ConsumeLanding()
+	require.NotNil(t, landed)
+	hits := world.resolveLanding(player, landed)
+	assert.Equal(t, []string{"enemy-1"}, hits)
+	assert.Less(t, enemy.Health, enemy.MaxHealth)
+}
+
+func TestCharacterAI_DodgeUsesMovementAbility(t *testing.T) {
+	player := NewPlayer("player-1", "TestUser")
+	player.Abilities.RegisterAbility(&Ability{
+		Type:     "test_blink",
+		Category: AbilityCategoryMovement,
+		Shape:    AbilityShapeBlink,
+		Range:    8,
+	})
+
+	// Registry abilities don't include the test blink, so nothing is chosen
+	assert.Equal(t, AbilityType(""), player.CharAI.chooseMovementAbility(player))
+
+	action, err := (&LLMAction{Action: "dodge", Ability: "test_blink", Mood: "anxious"}).ToAIAction(player, map[string]*Enemy{
+		"e1": {ID: "e1", Position: Vector3{X: 2}, Health: 10, MaxHealth: 10},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, AbilityType("test_blink"), action.Ability)
+	assert.InDelta(t, -1.0, action.Direction.X, 0.001, "dodge defaults to moving away from the target")
+}
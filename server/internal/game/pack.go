@@ -0,0 +1,207 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+)
+
+// EnemyPack is a group of enemies spawned together that fight as one. The
+// first member is the leader.
+type EnemyPack struct {
+	ID       string
+	LeaderID string
+	Members  []string // Enemy IDs, including the leader
+}
+
+// packSettings returns the pack tuning from enemies.json with defaults
+// filled in for anything left unset
+func packSettings() config.PacksConfig {
+	cfg := config.Enemies.Packs
+	if cfg.AlertRadius <= 0 {
+		cfg.AlertRadius = 15.0
+	}
+	if cfg.AlertThreat <= 0 {
+		cfg.AlertThreat = 5.0
+	}
+	if cfg.FlankRangeFraction <= 0 {
+		cfg.FlankRangeFraction = 0.8
+	}
+	if cfg.LeaderBuffRadius <= 0 {
+		cfg.LeaderBuffRadius = 12.0
+	}
+	if cfg.LeaderDamageMultiplier <= 0 {
+		cfg.LeaderDamageMultiplier = 1.0
+	}
+	if cfg.LeaderSpeedMultiplier <= 0 {
+		cfg.LeaderSpeedMultiplier = 1.0
+	}
+	if cfg.LeaderDeathFleeSeconds <= 0 {
+		cfg.LeaderDeathFleeSeconds = 4.0
+	}
+	return cfg
+}
+
+// packDamageMultiplier returns the damage bonus from a living pack leader nearby
+func (ai *EnemyAI) packDamageMultiplier() float64 {
+	if !ai.PackBuffActive {
+		return 1.0
+	}
+	return packSettings().LeaderDamageMultiplier
+}
+
+// packSpeedMultiplier returns the speed bonus from a living pack leader nearby
+func (ai *EnemyAI) packSpeedMultiplier() float64 {
+	if !ai.PackBuffActive {
+		return 1.0
+	}
+	return packSettings().LeaderSpeedMultiplier
+}
+
+// enemyPacks returns the world's packs, creating the map on first use.
+// Caller must hold w.mu.
+func (w *World) enemyPacks() map[string]*EnemyPack {
+	if w.packs == nil {
+		w.packs = make(map[string]*EnemyPack)
+	}
+	return w.packs
+}
+
+// formPack makes enemies spawned together a pack led by the first of them.
+// A lone enemy isn't a pack. Caller must hold w.mu.
+func (w *World) formPack(packID string, enemies []*Enemy) {
+	if len(enemies) < 2 {
+		return
+	}
+
+	pack := &EnemyPack{ID: packID, LeaderID: enemies[0].ID}
+	for i, enemy := range enemies {
+		if enemy.AI == nil {
+			continue
+		}
+		enemy.AI.PackID = packID
+		enemy.AI.IsPackLeader = i == 0
+		pack.Members = append(pack.Members, enemy.ID)
+	}
+	w.enemyPacks()[packID] = pack
+}
+
+// livingPackMembers returns the pack's members that are still alive, in
+// member order, and forgets the rest. Caller must hold w.mu.
+func (w *World) livingPackMembers(pack *EnemyPack) []*Enemy {
+	living := make([]*Enemy, 0, len(pack.Members))
+	ids := pack.Members[:0]
+	for _, id := range pack.Members {
+		if enemy, ok := w.enemies[id]; ok && !enemy.Dead && enemy.AI != nil {
+			living = append(living, enemy)
+			ids = append(ids, id)
+		}
+	}
+	pack.Members = ids
+	return living
+}
+
+// updatePacks runs pack behavior after enemies have picked targets: members
+// near one that found a target join in, the leader buffs those around it, and
+// melee members spread out around their shared target. Caller must hold w.mu.
+func (w *World) updatePacks() {
+	settings := packSettings()
+
+	for id, pack := range w.enemyPacks() {
+		members := w.livingPackMembers(pack)
+		if len(members) == 0 {
+			delete(w.packs, id)
+			continue
+		}
+
+		leader, leaderAlive := w.enemies[pack.LeaderID]
+		leaderAlive = leaderAlive && !leader.Dead
+
+		// Aggro together
+		for _, alerted := range members {
+			if alerted.AI.TargetID == "" {
+				continue
+			}
+			_, isMinion := w.minions[alerted.AI.TargetID]
+			for _, member := range members {
+				if member.AI.TargetID == "" && Distance2D(member.Position, alerted.Position) <= settings.AlertRadius {
+					member.AI.Threat.Raise(alerted.AI.TargetID, settings.AlertThreat, isMinion)
+				}
+			}
+			break
+		}
+
+		for _, member := range members {
+			member.AI.PackBuffActive = leaderAlive && member.ID != pack.LeaderID &&
+				Distance2D(member.Position, leader.Position) <= settings.LeaderBuffRadius
+		}
+
+		w.assignFlankSlots(members, settings.FlankRangeFraction)
+	}
+}
+
+// assignFlankSlots spreads melee members evenly around each target they
+// share, starting from the side the pack approaches from. Caller must hold w.mu.
+func (w *World) assignFlankSlots(members []*Enemy, rangeFraction float64) {
+	byTarget := make(map[string][]*Enemy)
+	for _, member := range members {
+		member.AI.FlankOffset = Vector3{}
+		if member.AI.TargetID == "" || (member.AI.Behavior != BehaviorMelee && member.AI.Behavior != BehaviorCharger) {
+			continue
+		}
+		byTarget[member.AI.TargetID] = append(byTarget[member.AI.TargetID], member)
+	}
+
+	ctx := &EnemyAIContext{Players: w.players, Minions: w.minions}
+	for _, group := range byTarget {
+		if len(group) < 2 {
+			continue
+		}
+		targetPos, ok := group[0].AI.targetPosition(ctx)
+		if !ok {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool { return group[i].ID < group[j].ID })
+
+		var centroid Vector3
+		for _, member := range group {
+			centroid.X += member.Position.X / float64(len(group))
+			centroid.Z += member.Position.Z / float64(len(group))
+		}
+		base := math.Atan2(centroid.Z-targetPos.Z, centroid.X-targetPos.X)
+
+		for i, member := range group {
+			angle := base + 2*math.Pi*float64(i)/float64(len(group))
+			radius := member.AI.AttackRange * rangeFraction
+			member.AI.FlankOffset = Vector3{X: math.Cos(angle) * radius, Z: math.Sin(angle) * radius}
+		}
+	}
+}
+
+// packMemberDied breaks up a pack whose leader has fallen. Some members flee
+// in panic for a while; the rest scatter and fight on alone.
+// Caller must hold w.mu.
+func (w *World) packMemberDied(enemy *Enemy) {
+	if enemy.AI == nil || enemy.AI.PackID == "" {
+		return
+	}
+	pack, ok := w.enemyPacks()[enemy.AI.PackID]
+	if !ok || pack.LeaderID != enemy.ID {
+		return
+	}
+
+	settings := packSettings()
+	fleeUntil := w.Now().Add(time.Duration(settings.LeaderDeathFleeSeconds * float64(time.Second)))
+	for _, member := range w.livingPackMembers(pack) {
+		member.AI.PackID = ""
+		member.AI.PackBuffActive = false
+		member.AI.FlankOffset = Vector3{}
+		if rand.Float64() < settings.LeaderDeathFleeChance {
+			member.AI.FleeUntil = fleeUntil
+		}
+	}
+	delete(w.packs, pack.ID)
+}
@@ -0,0 +1,123 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withPackConfig installs pack tuning for the duration of a test
+func withPackConfig(t *testing.T, cfg config.PacksConfig) {
+	t.Helper()
+	previous := config.Enemies.Packs
+	config.Enemies.Packs = cfg
+	t.Cleanup(func() { config.Enemies.Packs = previous })
+}
+
+// newPackTestWorld returns a world with a player and a three-enemy pack led by e1
+func newPackTestWorld(t *testing.T) (*World, []*Enemy, *Player) {
+	t.Helper()
+	withPackConfig(t, config.PacksConfig{
+		AlertRadius: 15, AlertThreat: 5, FlankRangeFraction: 0.8,
+		LeaderBuffRadius: 12, LeaderDamageMultiplier: 1.5, LeaderSpeedMultiplier: 1.2,
+		LeaderDeathFleeChance: 1, LeaderDeathFleeSeconds: 4,
+	})
+	world := newTestWorldWithoutEnemies()
+	player := NewPlayer("p1", "One")
+	player.Position = Vector3{X: 10}
+	world.players[player.ID] = player
+
+	cfg := createTestEnemyConfig("melee", 20)
+	group := []*Enemy{
+		createTestEnemy("e1", "zombie", Vector3{}, cfg),
+		createTestEnemy("e2", "zombie", Vector3{Z: 2}, cfg),
+		createTestEnemy("e3", "zombie", Vector3{Z: -2}, cfg),
+	}
+	world.formPack("pack-e1", group)
+	for _, enemy := range group {
+		world.addEnemy(enemy)
+	}
+	return world, group, player
+}
+
+func TestFormPack_FirstEnemyLeads(t *testing.T) {
+	world, group, _ := newPackTestWorld(t)
+
+	pack := world.packs["pack-e1"]
+	require.NotNil(t, pack)
+	assert.Equal(t, "e1", pack.LeaderID)
+	assert.Equal(t, []string{"e1", "e2", "e3"}, pack.Members)
+	assert.True(t, group[0].AI.IsPackLeader)
+	assert.False(t, group[1].AI.IsPackLeader)
+	assert.Equal(t, "pack-e1", group[2].AI.PackID)
+	assert.Equal(t, "pack-e1", group[1].Serialize()["packID"])
+
+	world.formPack("pack-e4", []*Enemy{createTestEnemy("e4", "zombie", Vector3{}, createTestEnemyConfig("melee", 20))})
+	assert.NotContains(t, world.packs, "pack-e4", "a lone enemy isn't a pack")
+}
+
+func TestUpdatePacks_AggroTogether(t *testing.T) {
+	world, group, player := newPackTestWorld(t)
+	group[0].AI.Threat.Raise(player.ID, 100, false)
+	group[0].AI.TargetID = player.ID
+
+	world.updatePacks()
+	ctx := &EnemyAIContext{Players: world.players, Enemies: world.enemies, Minions: world.minions, DeltaSeconds: 0.05, World: world}
+	for _, enemy := range group {
+		enemy.UpdateAI(ctx)
+		assert.Equal(t, player.ID, enemy.AI.TargetID, "%s joins the fight", enemy.ID)
+	}
+}
+
+func TestUpdatePacks_LeaderBuffsNearbyMembers(t *testing.T) {
+	world, group, _ := newPackTestWorld(t)
+	group[2].Position = Vector3{X: -30}
+
+	world.updatePacks()
+	assert.False(t, group[0].AI.PackBuffActive, "the leader doesn't buff itself")
+	assert.True(t, group[1].AI.PackBuffActive)
+	assert.Equal(t, 1.5, group[1].AI.packDamageMultiplier())
+	assert.Equal(t, 1.2, group[1].AI.packSpeedMultiplier())
+	assert.False(t, group[2].AI.PackBuffActive, "too far from the leader")
+	assert.Equal(t, 1.0, group[2].AI.packDamageMultiplier())
+}
+
+func TestUpdatePacks_FlankersSpreadAroundTarget(t *testing.T) {
+	world, group, player := newPackTestWorld(t)
+	for _, enemy := range group {
+		enemy.AI.TargetID = player.ID
+	}
+
+	world.updatePacks()
+	seen := make(map[Vector3]bool)
+	for _, enemy := range group {
+		offset := enemy.AI.FlankOffset
+		assert.InDelta(t, enemy.AI.AttackRange*0.8, Distance2D(Vector3{}, offset), 0.001)
+		assert.False(t, seen[offset], "%s shares a flank slot", enemy.ID)
+		seen[offset] = true
+	}
+}
+
+func TestPackMemberDied_LeaderDeathBreaksPack(t *testing.T) {
+	world, group, _ := newPackTestWorld(t)
+	world.updatePacks()
+
+	world.packMemberDied(group[1])
+	assert.Contains(t, world.packs, "pack-e1", "losing a follower keeps the pack together")
+
+	group[0].Dead = true
+	world.packMemberDied(group[0])
+	assert.NotContains(t, world.packs, "pack-e1")
+	for _, enemy := range group[1:] {
+		assert.Empty(t, enemy.AI.PackID)
+		assert.False(t, enemy.AI.PackBuffActive)
+		assert.True(t, enemy.AI.FleeUntil.After(time.Now()), "%s panics", enemy.ID)
+	}
+
+	ctx := &EnemyAIContext{Players: world.players, Enemies: world.enemies, Minions: world.minions, DeltaSeconds: 0.05, World: world}
+	group[2].UpdateAI(ctx)
+	assert.Equal(t, AIStateFlee, group[2].AI.State)
+}
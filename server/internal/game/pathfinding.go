@@ -0,0 +1,373 @@
+package game
+
+import (
+	"container/heap"
+	"math"
+	"time"
+)
+
+// navCellSize is the spacing of the walkability grid used for paths within tiles
+const navCellSize = 1.0
+
+// navProbeStep is the distance between samples when checking a straight walk.
+// It is smaller than the narrowest blocking feature padded by an entity's radius.
+const navProbeStep = 0.5
+
+// maxCorridorTiles limits how many tiles of a long route are planned on the
+// grid at once. The rest is planned when the enemy gets there.
+const maxCorridorTiles = 3
+
+// maxPathNodes bounds the work a single grid search may do
+const maxPathNodes = 6000
+
+const (
+	repathDistance          = 3.0 // Replan once the target is this far from where the path was aimed
+	repathInterval          = 0.5 // Minimum seconds between replans of a cached path
+	waypointReachedDistance = 0.5
+)
+
+// EnemyPath is a route an enemy is following toward its target
+type EnemyPath struct {
+	Waypoints []Vector3
+	Next      int     // Index of the waypoint being walked to
+	Goal      Vector3 // Where the target was when the path was planned
+	PlannedAt time.Time
+}
+
+// Done returns true once every waypoint has been reached (or there were none)
+func (p *EnemyPath) Done() bool {
+	return p.Next >= len(p.Waypoints)
+}
+
+// pathItem is an entry in the open set of an A* search
+type pathItem[K comparable] struct {
+	key   K
+	score float64 // Cost so far plus the heuristic
+}
+
+// pathQueue is a min-heap of pathItems ordered by score
+type pathQueue[K comparable] []pathItem[K]
+
+func (q pathQueue[K]) Len() int            { return len(q) }
+func (q pathQueue[K]) Less(i, j int) bool  { return q[i].score < q[j].score }
+func (q pathQueue[K]) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue[K]) Push(x interface{}) { *q = append(*q, x.(pathItem[K])) }
+func (q *pathQueue[K]) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// FindTileRoute returns the tiles to walk through to get from one tile to
+// another, both included, following open edges. Returns nil if there is no route.
+func (b *Board) FindTileRoute(from, to HexCoord) []HexCoord {
+	if b.GetTile(from) == nil || b.GetTile(to) == nil || from.Layer != to.Layer {
+		return nil
+	}
+	if from == to {
+		return []HexCoord{from}
+	}
+
+	cameFrom := map[HexCoord]HexCoord{}
+	cost := map[HexCoord]int{from: 0}
+	open := &pathQueue[HexCoord]{{key: from, score: float64(HexDistance(from, to))}}
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(pathItem[HexCoord]).key
+		if current == to {
+			route := []HexCoord{to}
+			for current != from {
+				current = cameFrom[current]
+				route = append(route, current)
+			}
+			reverseCoords(route)
+			return route
+		}
+
+		for _, neighbor := range HexNeighbors(current) {
+			if !b.canCrossEdge(current, neighbor) {
+				continue
+			}
+			newCost := cost[current] + 1
+			if old, seen := cost[neighbor]; seen && old <= newCost {
+				continue
+			}
+			cost[neighbor] = newCost
+			cameFrom[neighbor] = current
+			heap.Push(open, pathItem[HexCoord]{key: neighbor, score: float64(newCost + HexDistance(neighbor, to))})
+		}
+	}
+	return nil
+}
+
+func reverseCoords(coords []HexCoord) {
+	for i, j := 0, len(coords)-1; i < j; i, j = i+1, j-1 {
+		coords[i], coords[j] = coords[j], coords[i]
+	}
+}
+
+// navCell is a square of the walkability grid
+type navCell struct {
+	X, Z int
+}
+
+func navCellAt(pos Vector3) navCell {
+	return navCell{X: int(math.Floor(pos.X / navCellSize)), Z: int(math.Floor(pos.Z / navCellSize))}
+}
+
+func (c navCell) center(y float64) Vector3 {
+	return Vector3{X: (float64(c.X) + 0.5) * navCellSize, Y: y, Z: (float64(c.Z) + 0.5) * navCellSize}
+}
+
+// navGrid answers walkability questions for a grid search limited to a
+// corridor of tiles, caching the answers for the search
+type navGrid struct {
+	board    *Board
+	layer    int
+	y        float64
+	radius   float64
+	corridor map[HexCoord]bool
+	walkable map[navCell]bool
+}
+
+func (g *navGrid) hex(c navCell) HexCoord {
+	return WorldToHex(c.center(g.y), g.layer)
+}
+
+func (g *navGrid) isWalkable(c navCell) bool {
+	if walkable, ok := g.walkable[c]; ok {
+		return walkable
+	}
+	center := c.center(g.y)
+	walkable := g.corridor[WorldToHex(center, g.layer)] && !g.board.collidesWithTerrain(center, g.layer, g.radius, false)
+	g.walkable[c] = walkable
+	return walkable
+}
+
+// search runs A* from start to goal over 8-connected cells. The start and
+// goal cells are always allowed, since entities can overlap terrain slightly.
+func (g *navGrid) search(start, goal navCell) []navCell {
+	cameFrom := map[navCell]navCell{}
+	cost := map[navCell]float64{start: 0}
+	open := &pathQueue[navCell]{{key: start, score: octileDistance(start, goal)}}
+	closed := map[navCell]bool{}
+
+	for open.Len() > 0 && len(closed) < maxPathNodes {
+		current := heap.Pop(open).(pathItem[navCell]).key
+		if current == goal {
+			cells := []navCell{goal}
+			for current != start {
+				current = cameFrom[current]
+				cells = append(cells, current)
+			}
+			for i, j := 0, len(cells)-1; i < j; i, j = i+1, j-1 {
+				cells[i], cells[j] = cells[j], cells[i]
+			}
+			return cells
+		}
+		if closed[current] {
+			continue
+		}
+		closed[current] = true
+
+		for dx := -1; dx <= 1; dx++ {
+			for dz := -1; dz <= 1; dz++ {
+				if dx == 0 && dz == 0 {
+					continue
+				}
+				next := navCell{X: current.X + dx, Z: current.Z + dz}
+				if closed[next] || !g.canStep(current, next, dx, dz, goal) {
+					continue
+				}
+				step := 1.0
+				if dx != 0 && dz != 0 {
+					step = math.Sqrt2
+				}
+				newCost := cost[current] + step
+				if old, seen := cost[next]; seen && old <= newCost {
+					continue
+				}
+				cost[next] = newCost
+				cameFrom[next] = current
+				heap.Push(open, pathItem[navCell]{key: next, score: newCost + octileDistance(next, goal)})
+			}
+		}
+	}
+	return nil
+}
+
+// canStep returns true if an entity can move between two adjacent cells.
+// Diagonal steps need both side cells free so paths don't cut corners.
+func (g *navGrid) canStep(from, to navCell, dx, dz int, goal navCell) bool {
+	if to != goal && !g.isWalkable(to) {
+		return false
+	}
+	if dx != 0 && dz != 0 {
+		if !g.isWalkable(navCell{X: from.X + dx, Z: from.Z}) || !g.isWalkable(navCell{X: from.X, Z: from.Z + dz}) {
+			return false
+		}
+	}
+	return g.board.canCrossEdge(g.hex(from), g.hex(to))
+}
+
+func octileDistance(a, b navCell) float64 {
+	dx := math.Abs(float64(a.X - b.X))
+	dz := math.Abs(float64(a.Z - b.Z))
+	return math.Max(dx, dz) + (math.Sqrt2-1)*math.Min(dx, dz)
+}
+
+// FindPath returns waypoints leading from one position toward another for an
+// entity of the given radius, ending at to. Long routes are first planned
+// tile by tile; only the first few tiles are planned in detail, ending at the
+// edge into the next tile. Returns nil if there is no way through.
+func (b *Board) FindPath(from, to Vector3, layer int, radius float64) []Vector3 {
+	route := b.FindTileRoute(WorldToHex(from, layer), WorldToHex(to, layer))
+	if route == nil {
+		return nil
+	}
+
+	goal := to
+	corridor := make(map[HexCoord]bool, maxCorridorTiles+1)
+	for i, coord := range route {
+		if i > maxCorridorTiles {
+			break
+		}
+		corridor[coord] = true
+	}
+	if len(route) > maxCorridorTiles {
+		// Aim for the middle of the edge into the first tile left out
+		exit := HexToWorld(route[maxCorridorTiles-1])
+		entry := HexToWorld(route[maxCorridorTiles])
+		goal = Vector3{X: (exit.X + entry.X) / 2, Y: from.Y, Z: (exit.Z + entry.Z) / 2}
+	}
+
+	grid := &navGrid{
+		board:    b,
+		layer:    layer,
+		y:        from.Y,
+		radius:   radius,
+		corridor: corridor,
+		walkable: make(map[navCell]bool),
+	}
+	cells := grid.search(navCellAt(from), navCellAt(goal))
+	if cells == nil {
+		return nil
+	}
+
+	waypoints := make([]Vector3, 0, len(cells))
+	for _, cell := range cells[1:] {
+		waypoints = append(waypoints, cell.center(from.Y))
+	}
+	if len(waypoints) == 0 {
+		waypoints = append(waypoints, goal)
+	} else {
+		waypoints[len(waypoints)-1] = goal
+	}
+	return b.smoothPath(from, waypoints, layer, radius)
+}
+
+// smoothPath drops waypoints that can be skipped by walking straight to a
+// later one
+func (b *Board) smoothPath(from Vector3, waypoints []Vector3, layer int, radius float64) []Vector3 {
+	smoothed := make([]Vector3, 0, len(waypoints))
+	anchor := from
+	for i := 0; i < len(waypoints); {
+		furthest := i
+		for j := len(waypoints) - 1; j > i; j-- {
+			if b.canWalkStraight(anchor, waypoints[j], layer, radius) {
+				furthest = j
+				break
+			}
+		}
+		smoothed = append(smoothed, waypoints[furthest])
+		anchor = waypoints[furthest]
+		i = furthest + 1
+	}
+	return smoothed
+}
+
+// canWalkStraight returns true if an entity of the given radius could walk
+// directly between two positions without touching terrain or a closed edge
+func (b *Board) canWalkStraight(from, to Vector3, layer int, radius float64) bool {
+	return b.walkSegment(from, to, layer, navProbeStep, func(point Vector3) bool {
+		return !b.collidesWithTerrain(point, layer, radius, false)
+	})
+}
+
+// steerTarget returns the point an enemy should walk toward to reach
+// targetPos: the target itself when the way is clear, otherwise the next
+// waypoint of a cached path around whatever is in the way
+func (ai *EnemyAI) steerTarget(enemy *Enemy, ctx *EnemyAIContext, targetPos Vector3) Vector3 {
+	if ctx.World == nil || ctx.World.Board == nil {
+		return targetPos
+	}
+	board := ctx.World.Board
+	layer := layerFromY(enemy.Position.Y)
+	if layerFromY(targetPos.Y) != layer {
+		return targetPos
+	}
+
+	if board.canWalkStraight(enemy.Position, targetPos, layer, entityCollisionRadius) {
+		ai.Path = nil
+		return targetPos
+	}
+
+	now := ai.now()
+	if ai.needsRepath(targetPos, now) {
+		ai.Path = &EnemyPath{
+			Waypoints: board.FindPath(enemy.Position, targetPos, layer, entityCollisionRadius),
+			Goal:      targetPos,
+			PlannedAt: now,
+		}
+	}
+
+	path := ai.Path
+	for !path.Done() && Distance2D(enemy.Position, path.Waypoints[path.Next]) <= waypointReachedDistance {
+		path.Next++
+	}
+	if path.Done() {
+		return targetPos // No way around; push straight on until the next replan
+	}
+	return path.Waypoints[path.Next]
+}
+
+// needsRepath returns true if there's no cached path, or it's old enough to
+// replace and has run out or the target has moved well away from its goal
+func (ai *EnemyAI) needsRepath(targetPos Vector3, now time.Time) bool {
+	if ai.Path == nil {
+		return true
+	}
+	if now.Sub(ai.Path.PlannedAt).Seconds() < repathInterval {
+		return false
+	}
+	return ai.Path.Done() || Distance2D(ai.Path.Goal, targetPos) > repathDistance
+}
+
+// fleeDirection returns a unit direction away from threatPos that isn't
+// straight into terrain, trying wider angles when directly away is blocked
+func (ai *EnemyAI) fleeDirection(enemy *Enemy, ctx *EnemyAIContext, threatPos Vector3) Vector3 {
+	dx := enemy.Position.X - threatPos.X
+	dz := enemy.Position.Z - threatPos.Z
+	distance := math.Max(math.Sqrt(dx*dx+dz*dz), 0.1)
+	away := Vector3{X: dx / distance, Z: dz / distance}
+
+	if ctx.World == nil || ctx.World.Board == nil {
+		return away
+	}
+	board := ctx.World.Board
+	layer := layerFromY(enemy.Position.Y)
+
+	const probeDistance = 3.0
+	for _, angle := range []float64{0, math.Pi / 4, -math.Pi / 4, math.Pi / 2, -math.Pi / 2} {
+		dir := Vector3{
+			X: away.X*math.Cos(angle) - away.Z*math.Sin(angle),
+			Z: away.X*math.Sin(angle) + away.Z*math.Cos(angle),
+		}
+		probe := Vector3{X: enemy.Position.X + dir.X*probeDistance, Y: enemy.Position.Y, Z: enemy.Position.Z + dir.Z*probeDistance}
+		if board.canWalkStraight(enemy.Position, probe, layer, entityCollisionRadius) {
+			return dir
+		}
+	}
+	return away // Cornered
+}
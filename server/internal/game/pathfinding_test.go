@@ -0,0 +1,117 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestChainBoard returns tiles (0,0)-(1,0)-(2,0) in a row. The first edge
+// is always open; the second only if connected.
+func newTestChainBoard(connected bool) *Board {
+	board := newTestBoard(true)
+	far := NewTile(HexCoord{Q: 2, R: 0}, "grassland", TileTypeOverworld, 1)
+	far.Generated = true
+	far.EdgePaths[3] = connected
+	board.Tiles[far.Coord] = far
+	board.Tiles[HexCoord{Q: 1, R: 0}].EdgePaths[0] = connected
+	return board
+}
+
+// addTestWall lines up large rocks along Z at the given X
+func addTestWall(board *Board, x, fromZ, toZ float64) {
+	for z := fromZ; z <= toZ; z += 1.5 {
+		addTestFeature(board, FeatureRockLarge, Vector3{X: x, Z: z}, 0)
+	}
+}
+
+func assertWalkable(t *testing.T, board *Board, from Vector3, path []Vector3) {
+	t.Helper()
+	for _, waypoint := range path {
+		assert.True(t, board.canWalkStraight(from, waypoint, 0, entityCollisionRadius), "blocked leg %v -> %v", from, waypoint)
+		from = waypoint
+	}
+}
+
+func TestFindTileRoute(t *testing.T) {
+	board := newTestChainBoard(true)
+	route := board.FindTileRoute(HexCoord{Q: 0, R: 0}, HexCoord{Q: 2, R: 0})
+	assert.Equal(t, []HexCoord{{Q: 0, R: 0}, {Q: 1, R: 0}, {Q: 2, R: 0}}, route)
+
+	closed := newTestChainBoard(false)
+	assert.Nil(t, closed.FindTileRoute(HexCoord{Q: 0, R: 0}, HexCoord{Q: 2, R: 0}))
+	assert.Nil(t, closed.FindTileRoute(HexCoord{Q: 0, R: 0}, HexCoord{Q: 5, R: 0}), "off the board")
+}
+
+func TestFindPath_AroundWall(t *testing.T) {
+	board := newTestBoard(true)
+	addTestWall(board, 2, -4.5, 4.5)
+	from, to := Vector3{X: -2}, Vector3{X: 6}
+	require.False(t, board.canWalkStraight(from, to, 0, entityCollisionRadius))
+
+	path := board.FindPath(from, to, 0, entityCollisionRadius)
+	require.NotEmpty(t, path)
+	assert.Equal(t, to, path[len(path)-1])
+	assertWalkable(t, board, from, path)
+}
+
+func TestFindPath_AcrossTiles(t *testing.T) {
+	from := HexToWorld(HexCoord{Q: 0, R: 0})
+	to := HexToWorld(HexCoord{Q: 2, R: 0})
+
+	board := newTestChainBoard(true)
+	path := board.FindPath(from, to, 0, entityCollisionRadius)
+	require.NotEmpty(t, path)
+	assert.Equal(t, to, path[len(path)-1])
+	assertWalkable(t, board, from, path)
+
+	assert.Nil(t, newTestChainBoard(false).FindPath(from, to, 0, entityCollisionRadius))
+}
+
+func TestSteerTarget_CachesPath(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	addTestWall(world.Board, 2, -4.5, 4.5)
+	enemy := createTestEnemy("e1", "zombie", Vector3{X: -2}, createTestEnemyConfig("melee", 20))
+	ctx := &EnemyAIContext{World: world}
+
+	assert.Equal(t, Vector3{X: -2, Z: 6}, enemy.AI.steerTarget(enemy, ctx, Vector3{X: -2, Z: 6}), "clear ground needs no path")
+	assert.Nil(t, enemy.AI.Path)
+
+	target := Vector3{X: 6}
+	first := enemy.AI.steerTarget(enemy, ctx, target)
+	require.NotNil(t, enemy.AI.Path)
+	assert.NotEqual(t, target, first)
+	plannedAt := enemy.AI.Path.PlannedAt
+
+	enemy.AI.steerTarget(enemy, ctx, Vector3{X: 6, Z: 1})
+	assert.Equal(t, plannedAt, enemy.AI.Path.PlannedAt, "small target moves reuse the path")
+
+	enemy.AI.Path.PlannedAt = time.Now().Add(-time.Second)
+	enemy.AI.steerTarget(enemy, ctx, Vector3{X: 6, Z: 6})
+	assert.True(t, enemy.AI.Path.PlannedAt.After(plannedAt), "the path is replanned once the target moves far")
+}
+
+func TestChase_WalksAroundWall(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	addTestWall(world.Board, 2, -4.5, 4.5)
+
+	player := NewPlayer("p1", "One")
+	player.Position = Vector3{X: 6}
+	world.players[player.ID] = player
+	enemy := createTestEnemy("e1", "zombie", Vector3{X: -2}, createTestEnemyConfig("melee", 20))
+	world.addEnemy(enemy)
+	enemy.AI.Threat.Raise(player.ID, 100, false) // The wall blocks sight, so it was hit from behind it
+
+	ctx := &EnemyAIContext{Players: world.players, Enemies: world.enemies, Minions: world.minions, DeltaSeconds: 0.05, World: world}
+	for i := 0; i < 200; i++ {
+		prev := enemy.Position
+		enemy.UpdateAI(ctx)
+		enemy.Position = world.resolveEntityMovement(prev, enemy.Position)
+	}
+
+	assert.Less(t, Distance2D(enemy.Position, player.Position), enemy.AI.AttackRange+0.5, "enemy reached the player instead of sticking to the wall")
+}
@@ -0,0 +1,121 @@
+package game
+
+import "github.com/PersonThing/cs-crawler/server/internal/config"
+
+// PvPMode controls where players can damage each other
+type PvPMode string
+
+const (
+	PvPOff        PvPMode = "off"
+	PvPZones      PvPMode = "zones" // Only between players standing in PvP zone tiles
+	PvPEverywhere PvPMode = "everywhere"
+)
+
+// GameRules is the per-game combat ruleset chosen when a game is created
+type GameRules struct {
+	PvP PvPMode `json:"pvp"`
+
+	// FriendlyFire lets minion attacks and area damage (ground AoE, leap
+	// landings) hit other players wherever PvP allows it. Without it only
+	// direct hits do.
+	FriendlyFire bool `json:"friendlyFire"`
+
+	// DifficultyTier raises every monster's level (see monster_level.go)
+	DifficultyTier int `json:"difficultyTier"`
+}
+
+// DefaultGameRules returns the co-op ruleset: no PvP, no friendly fire
+func DefaultGameRules() GameRules {
+	return GameRules{PvP: PvPOff}
+}
+
+// ParsePvPMode converts a client-supplied string to a PvPMode, defaulting to off
+func ParsePvPMode(s string) PvPMode {
+	switch PvPMode(s) {
+	case PvPZones, PvPEverywhere:
+		return PvPMode(s)
+	default:
+		return PvPOff
+	}
+}
+
+// ParseDifficultyTier clamps a client-supplied difficulty tier to the tiers
+// enemies.json allows
+func ParseDifficultyTier(tier int) int {
+	if tier < 0 {
+		return 0
+	}
+	if maxTier := config.Enemies.Levels.MaxTier; tier > maxTier {
+		return maxTier
+	}
+	return tier
+}
+
+// Serialize converts the ruleset to a map for JSON
+func (r GameRules) Serialize() map[string]interface{} {
+	return map[string]interface{}{
+		"pvp":            string(r.PvP),
+		"friendlyFire":   r.FriendlyFire,
+		"difficultyTier": r.DifficultyTier,
+	}
+}
+
+// EnablePvPZones turns on PvP zones for the board. Tiles already generated
+// are zoned now; the rest are zoned as they generate.
+func (b *Board) EnablePvPZones() {
+	b.PvPZones = true
+	for _, tile := range b.Tiles {
+		if tile.Generated {
+			b.assignPvPZone(tile)
+		}
+	}
+}
+
+// assignPvPZone marks the tile as a PvP zone if zones are enabled and it lies
+// on the outermost overworld ring
+func (b *Board) assignPvPZone(tile *Tile) {
+	tile.PvPZone = b.PvPZones && tile.Coord.Layer == 0 &&
+		tile.TileType != TileTypeTown && tile.Difficulty >= b.Rings
+}
+
+// SetRules applies a game ruleset to the world
+func (w *World) SetRules(rules GameRules) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.Rules = rules
+	if rules.PvP == PvPZones && w.Board != nil {
+		w.Board.EnablePvPZones()
+	}
+}
+
+// isInPvPZone returns true if the position is on a tile designated for PvP.
+// Caller must hold w.mu.
+func (w *World) isInPvPZone(pos Vector3) bool {
+	if w.Board == nil {
+		return false
+	}
+	tile := w.Board.GetTile(WorldToHex(pos, layerFromY(pos.Y)))
+	return tile != nil && tile.PvPZone
+}
+
+// canHarmPlayer returns true if the attacker's damage can hit the victim under
+// the world's ruleset. Indirect damage comes from minions or area effects and
+// additionally requires friendly fire. Caller must hold w.mu.
+func (w *World) canHarmPlayer(attacker, victim *Player, indirect bool) bool {
+	if attacker == nil || victim == nil || attacker.ID == victim.ID || victim.IsDead() {
+		return false
+	}
+
+	switch w.Rules.PvP {
+	case PvPEverywhere:
+	case PvPZones:
+		if !w.isInPvPZone(attacker.Position) || !w.isInPvPZone(victim.Position) {
+			return false
+		}
+	default:
+		return false
+	}
+
+	return !indirect || w.Rules.FriendlyFire
+}
@@ -0,0 +1,148 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPvPTestWorld(rules GameRules) (*World, *Player, *Player) {
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	world.Rules = rules
+
+	attacker := NewPlayer("attacker", "Attacker")
+	victim := NewPlayer("victim", "Victim")
+	victim.Position = Vector3{X: 5}
+	world.players[attacker.ID] = attacker
+	world.players[victim.ID] = victim
+	return world, attacker, victim
+}
+
+func TestParsePvPMode(t *testing.T) {
+	assert.Equal(t, PvPEverywhere, ParsePvPMode("everywhere"))
+	assert.Equal(t, PvPZones, ParsePvPMode("zones"))
+	assert.Equal(t, PvPOff, ParsePvPMode(""))
+	assert.Equal(t, PvPOff, ParsePvPMode("bogus"))
+}
+
+func TestCanHarmPlayer(t *testing.T) {
+	world, attacker, victim := newPvPTestWorld(DefaultGameRules())
+
+	assert.False(t, world.canHarmPlayer(attacker, victim, false), "co-op games have no PvP")
+	assert.False(t, world.canHarmPlayer(attacker, attacker, false), "players never hit themselves")
+
+	world.Rules = GameRules{PvP: PvPEverywhere}
+	assert.True(t, world.canHarmPlayer(attacker, victim, false))
+	assert.False(t, world.canHarmPlayer(attacker, victim, true), "area damage needs friendly fire")
+
+	world.Rules = GameRules{PvP: PvPOff, FriendlyFire: true}
+	assert.False(t, world.canHarmPlayer(attacker, victim, false))
+	assert.False(t, world.canHarmPlayer(attacker, victim, true), "friendly fire doesn't override PvP off")
+
+	world.Rules = GameRules{PvP: PvPEverywhere, FriendlyFire: true}
+	assert.True(t, world.canHarmPlayer(attacker, victim, true))
+
+	victim.Health = 0
+	assert.False(t, world.canHarmPlayer(attacker, victim, true), "dead players can't be hit")
+}
+
+func TestCanHarmPlayer_Zones(t *testing.T) {
+	world, attacker, victim := newPvPTestWorld(GameRules{PvP: PvPZones})
+	assert.False(t, world.canHarmPlayer(attacker, victim, false))
+
+	world.Board.GetTile(HexCoord{Q: 0, R: 0}).PvPZone = true
+	assert.True(t, world.canHarmPlayer(attacker, victim, false))
+
+	// Both players must be inside a zone
+	victim.Position = HexToWorld(HexCoord{Q: 1, R: 0})
+	assert.False(t, world.canHarmPlayer(attacker, victim, false))
+
+	world.Rules.FriendlyFire = true
+	assert.False(t, world.canHarmPlayer(attacker, victim, true), "area damage stays inside zones too")
+}
+
+func TestEnablePvPZones(t *testing.T) {
+	board := NewBoard(42, 2)
+	outer := HexRing(HexCoord{}, 2)[0]
+	board.EnsureTileGenerated(outer)
+	assert.False(t, board.GetTile(outer).PvPZone, "zones are off by default")
+
+	board.EnablePvPZones()
+	assert.True(t, board.GetTile(outer).PvPZone)
+
+	// Tiles generated later are zoned as they generate
+	for coord := range board.Tiles {
+		board.EnsureTileGenerated(coord)
+	}
+	for coord, tile := range board.Tiles {
+		expected := coord.Layer == 0 && HexDistance(HexCoord{}, coord) == 2
+		assert.Equal(t, expected, tile.PvPZone, "tile %v", coord)
+	}
+}
+
+func TestCastPlayerAbility_PvPLineHitsPlayer(t *testing.T) {
+	world, attacker, victim := newPvPTestWorld(GameRules{PvP: PvPEverywhere})
+	victim.Health = 10
+
+	lightning := &Ability{Type: "lightning", Shape: AbilityShapeLine, Damage: 25, DamageType: DamageTypeLightning, Range: 10, Radius: 0.5}
+	result := world.CastPlayerAbility(attacker, lightning, Vector3{X: 1}, CastOptions{})
+
+	assert.Equal(t, []string{victim.ID}, result.HitTargets)
+	assert.True(t, victim.IsDead())
+	assert.Equal(t, attacker.MaxHealth, attacker.Health)
+	require.Len(t, world.deathEvents, 1)
+	assert.Equal(t, DeathEvent{EntityID: victim.ID, EntityType: "player", KillerID: attacker.ID, KillerType: "player"}, world.deathEvents[0])
+}
+
+func TestCastPlayerAbility_NoPvPByDefault(t *testing.T) {
+	world, attacker, victim := newPvPTestWorld(DefaultGameRules())
+
+	lightning := &Ability{Type: "lightning", Shape: AbilityShapeLine, Damage: 25, Range: 10, Radius: 0.5}
+	result := world.CastPlayerAbility(attacker, lightning, Vector3{X: 1}, CastOptions{})
+
+	assert.Empty(t, result.HitTargets)
+	assert.Equal(t, victim.MaxHealth, victim.Health)
+}
+
+func TestCastPlayerAbility_AoEFriendlyFire(t *testing.T) {
+	aoe := &Ability{Type: "meteor", Shape: AbilityShapeGroundAoE, Damage: 30, Range: 15, AreaRadius: 3}
+	target := Vector3{X: 5}
+
+	world, attacker, victim := newPvPTestWorld(GameRules{PvP: PvPEverywhere})
+	world.CastPlayerAbility(attacker, aoe, Vector3{X: 1}, CastOptions{TargetPosition: &target})
+	assert.Equal(t, victim.MaxHealth, victim.Health, "AoE ignores players without friendly fire")
+
+	world.Rules.FriendlyFire = true
+	result := world.CastPlayerAbility(attacker, aoe, Vector3{X: 1}, CastOptions{TargetPosition: &target})
+	assert.Equal(t, []string{victim.ID}, result.HitTargets)
+	assert.Less(t, victim.Health, victim.MaxHealth)
+}
+
+func TestProjectileHitsPlayerWithPvP(t *testing.T) {
+	world, attacker, victim := newPvPTestWorld(GameRules{PvP: PvPEverywhere})
+	victim.Position = Vector3{X: 1}
+
+	fireball := &Ability{Type: "fireball", Shape: AbilityShapeProjectile, Damage: 20, DamageType: DamageTypeFire, Speed: 10}
+	result := world.CastPlayerAbility(attacker, fireball, Vector3{X: 1}, CastOptions{})
+	require.NotEmpty(t, result.ProjectileID)
+
+	world.Update(50 * time.Millisecond)
+
+	assert.Equal(t, victim.MaxHealth-20, victim.Health)
+	assert.Equal(t, attacker.MaxHealth, attacker.Health, "projectiles don't hit their owner")
+	assert.NotContains(t, world.projectiles, result.ProjectileID)
+}
+
+func TestMinionProjectileNeedsFriendlyFire(t *testing.T) {
+	world, attacker, victim := newPvPTestWorld(GameRules{PvP: PvPEverywhere})
+	victim.Position = Vector3{X: 1}
+
+	fireball := &Ability{Type: "fireball", Shape: AbilityShapeProjectile, Damage: 20, Speed: 10}
+	world.castAbility("turret-1", attacker, attacker.Position, fireball, Vector3{X: 1}, CastOptions{})
+
+	world.Update(50 * time.Millisecond)
+	assert.Equal(t, victim.MaxHealth, victim.Health)
+}
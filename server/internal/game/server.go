@@ -1,6 +1,7 @@
 package game
 
 import (
+	"encoding/json"
 	"log"
 	"sync"
 	"time"
@@ -27,21 +28,35 @@ type Server struct {
 	// Database
 	db *database.DB
 
-	// LLM Provider for AI combat
-	llmProvider LLMProvider
+	// LLM inference workers for AI combat, shared by all worlds; nil when disabled
+	llm *LLMManager
+
+	// Combat event log shared by all worlds, nil when disabled
+	combatLog *CombatLog
 }
 
 // NewServer creates a new game server
-func NewServer(tickRate int, db *database.DB, llmProvider LLMProvider) *Server {
+func NewServer(tickRate int, db *database.DB, llm *LLMManager) *Server {
+	var combatLog *CombatLog
+	if path := config.Server.CombatLogPath; path != "" {
+		var err error
+		if combatLog, err = OpenCombatLog(path); err != nil {
+			log.Printf("[COMBATLOG] Failed to open %s, combat logging disabled: %v", path, err)
+		} else {
+			log.Printf("[COMBATLOG] Writing combat events to %s", path)
+		}
+	}
+
 	return &Server{
-		tickRate:    tickRate,
-		tickPeriod:  time.Second / time.Duration(tickRate),
-		stopChan:    make(chan struct{}),
-		worlds:      make(map[string]*World),
-		Lobby:       NewLobbyService(),
-		Chat:        NewChatService(),
-		db:          db,
-		llmProvider: llmProvider,
+		tickRate:   tickRate,
+		tickPeriod: time.Second / time.Duration(tickRate),
+		stopChan:   make(chan struct{}),
+		worlds:     make(map[string]*World),
+		Lobby:      NewLobbyService(),
+		Chat:       NewChatService(),
+		db:         db,
+		llm:        llm,
+		combatLog:  combatLog,
 	}
 }
 
@@ -56,6 +71,10 @@ func (s *Server) Start() {
 	s.running = true
 	s.mu.Unlock()
 
+	if s.llm != nil {
+		s.llm.Start()
+	}
+
 	ticker := time.NewTicker(s.tickPeriod)
 	defer ticker.Stop()
 
@@ -87,6 +106,12 @@ func (s *Server) Stop() {
 		s.running = false
 		close(s.stopChan)
 	}
+	if s.llm != nil {
+		s.llm.Stop()
+	}
+	if err := s.combatLog.Close(); err != nil {
+		log.Printf("[COMBATLOG] Failed to close combat log: %v", err)
+	}
 }
 
 // tick processes one game update
@@ -98,6 +123,7 @@ func (s *Server) tick() {
 	for _, world := range s.worlds {
 		world.Update(s.tickPeriod)
 	}
+	s.combatLog.Flush()
 }
 
 // SaveAllPlayers saves all players in all worlds to the database
@@ -132,6 +158,12 @@ func (s *Server) savePlayer(player *Player) error {
 	if err != nil {
 		return err
 	}
+	characterAIJSON := json.RawMessage("{}")
+	if player.CharAI != nil {
+		if characterAIJSON, err = player.CharAI.ToSaveData(); err != nil {
+			return err
+		}
+	}
 
 	return s.db.SavePlayer(&database.PlayerData{
 		Username:      player.Username,
@@ -140,8 +172,11 @@ func (s *Server) savePlayer(player *Player) error {
 		PositionZ:     player.Position.Z,
 		Rotation:      player.Rotation,
 		Health:        player.Health,
+		Mana:          player.Mana,
+		Experience:    player.Experience,
 		EquippedItems: equippedJSON,
 		BagItems:      bagsJSON,
+		CharacterAI:   characterAIJSON,
 	})
 }
 
@@ -162,7 +197,8 @@ func (s *Server) CreateWorld(worldID string) *World {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	world := NewWorld(worldID, s.llmProvider)
+	world := NewWorld(worldID, s.llm)
+	world.CombatLog = s.combatLog
 	s.worlds[worldID] = world
 
 	log.Printf("Created world: %s", worldID)
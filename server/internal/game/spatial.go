@@ -0,0 +1,251 @@
+package game
+
+import "math"
+
+// spatialCellSize is the width of a spatial grid cell in world units. It is a
+// few times the typical hit radius so most queries touch only a handful of cells.
+const spatialCellSize = 4.0
+
+// activeAreaRadius bounds a tile and its six neighbors, measured from the
+// center of the middle tile
+var activeAreaRadius = HexSize * (math.Sqrt(3) + 1)
+
+// spatialCell identifies one square cell of a spatial grid
+type spatialCell struct {
+	Layer int
+	X     int
+	Z     int
+}
+
+// spatialEntry is an entity stored in a spatial grid
+type spatialEntry struct {
+	ID       string
+	Position Vector3
+}
+
+// SpatialGrid buckets entity positions into a uniform grid on the X/Z plane,
+// separately per dungeon layer, for fast radius and nearest-neighbor queries
+type SpatialGrid struct {
+	cellSize float64
+	cells    map[spatialCell][]spatialEntry
+	count    int
+}
+
+// NewSpatialGrid creates an empty grid with the given cell size
+func NewSpatialGrid(cellSize float64) *SpatialGrid {
+	if cellSize <= 0 {
+		cellSize = spatialCellSize
+	}
+	return &SpatialGrid{
+		cellSize: cellSize,
+		cells:    make(map[spatialCell][]spatialEntry),
+	}
+}
+
+// cellFor returns the cell containing a position on a layer
+func (g *SpatialGrid) cellFor(pos Vector3, layer int) spatialCell {
+	return spatialCell{
+		Layer: layer,
+		X:     int(math.Floor(pos.X / g.cellSize)),
+		Z:     int(math.Floor(pos.Z / g.cellSize)),
+	}
+}
+
+// Clear removes all entries, keeping cell storage for reuse
+func (g *SpatialGrid) Clear() {
+	for cell, entries := range g.cells {
+		if len(entries) == 0 {
+			delete(g.cells, cell)
+			continue
+		}
+		g.cells[cell] = entries[:0]
+	}
+	g.count = 0
+}
+
+// Insert adds an entity at a position
+func (g *SpatialGrid) Insert(id string, pos Vector3) {
+	cell := g.cellFor(pos, layerFromY(pos.Y))
+	g.cells[cell] = append(g.cells[cell], spatialEntry{ID: id, Position: pos})
+	g.count++
+}
+
+// Len returns the number of entries in the grid
+func (g *SpatialGrid) Len() int {
+	return g.count
+}
+
+// QueryRadius calls fn for every entry on layer within radius of center.
+// The layer is explicit because center may be at a height, like a projectile's.
+func (g *SpatialGrid) QueryRadius(center Vector3, layer int, radius float64, fn func(id string, pos Vector3)) {
+	if radius < 0 {
+		return
+	}
+	min := g.cellFor(Vector3{X: center.X - radius, Z: center.Z - radius}, layer)
+	max := g.cellFor(Vector3{X: center.X + radius, Z: center.Z + radius}, layer)
+
+	for x := min.X; x <= max.X; x++ {
+		for z := min.Z; z <= max.Z; z++ {
+			for _, entry := range g.cells[spatialCell{Layer: min.Layer, X: x, Z: z}] {
+				if Distance2D(center, entry.Position) <= radius {
+					fn(entry.ID, entry.Position)
+				}
+			}
+		}
+	}
+}
+
+// Nearest returns the closest accepted entry on layer within maxRange of
+// center. Cells are searched in rings outward from the center, so nearby hits
+// return without visiting the rest of the range.
+func (g *SpatialGrid) Nearest(center Vector3, layer int, maxRange float64, accept func(id string) bool) (string, bool) {
+	origin := g.cellFor(center, layer)
+	maxRing := int(math.Ceil(maxRange/g.cellSize)) + 1
+
+	bestID := ""
+	bestDistance := maxRange
+	for ring := 0; ring <= maxRing; ring++ {
+		// Everything in this ring or beyond is at least (ring-1) cells away
+		if bestID != "" && float64(ring-1)*g.cellSize > bestDistance {
+			break
+		}
+
+		for x := origin.X - ring; x <= origin.X+ring; x++ {
+			for z := origin.Z - ring; z <= origin.Z+ring; z++ {
+				// Only visit the outer edge of the ring
+				if x != origin.X-ring && x != origin.X+ring && z != origin.Z-ring && z != origin.Z+ring {
+					continue
+				}
+				for _, entry := range g.cells[spatialCell{Layer: origin.Layer, X: x, Z: z}] {
+					distance := Distance2D(center, entry.Position)
+					closer := distance < bestDistance || (bestID == "" && distance <= bestDistance)
+					if closer && (accept == nil || accept(entry.ID)) {
+						bestDistance = distance
+						bestID = entry.ID
+					}
+				}
+			}
+		}
+	}
+	return bestID, bestID != ""
+}
+
+// markEnemiesMoved flags the enemy index as stale. Call after enemies are added,
+// removed or moved. Caller must hold w.mu.
+func (w *World) markEnemiesMoved() {
+	w.enemyGridDirty = true
+}
+
+// addEnemy adds an enemy to the world. Caller must hold w.mu.
+func (w *World) addEnemy(enemy *Enemy) {
+	w.attachEnemy(enemy)
+	w.enemies[enemy.ID] = enemy
+	w.markEnemiesMoved()
+}
+
+// removeEnemy removes an enemy from the world. Caller must hold w.mu.
+func (w *World) removeEnemy(id string) {
+	delete(w.enemies, id)
+	w.markEnemiesMoved()
+}
+
+// enemyIndex returns the spatial index of all enemies, dead or alive,
+// rebuilding it if anything has changed since the last query. Caller must hold
+// w.mu for writing.
+func (w *World) enemyIndex() *SpatialGrid {
+	if w.enemyGrid == nil {
+		w.enemyGrid = NewSpatialGrid(spatialCellSize)
+		w.enemyGridDirty = true
+	}
+	if w.enemyGridDirty {
+		w.enemyGrid.Clear()
+		for id, enemy := range w.enemies {
+			w.enemyGrid.Insert(id, enemy.Position)
+		}
+		w.enemyGridDirty = false
+	}
+	return w.enemyGrid
+}
+
+// enemiesInRadius returns living enemies on layer within radius of center.
+// Caller must hold w.mu.
+func (w *World) enemiesInRadius(center Vector3, layer int, radius float64) []*Enemy {
+	var result []*Enemy
+	w.enemyIndex().QueryRadius(center, layer, radius, func(id string, _ Vector3) {
+		if enemy := w.enemies[id]; enemy != nil && !enemy.IsDead() {
+			result = append(result, enemy)
+		}
+	})
+	return result
+}
+
+// enemiesInCone returns living enemies inside a cone from origin.
+// Caller must hold w.mu.
+func (w *World) enemiesInCone(origin, direction Vector3, maxRange, angle float64) []*Enemy {
+	var result []*Enemy
+	for _, enemy := range w.enemiesInRadius(origin, layerFromY(origin.Y), maxRange) {
+		if coneContains(origin, direction, maxRange, angle, enemy.Position) {
+			result = append(result, enemy)
+		}
+	}
+	return result
+}
+
+// enemiesOnLine returns living enemies within lineWidth of a line from origin.
+// Caller must hold w.mu.
+func (w *World) enemiesOnLine(origin, direction Vector3, maxRange, lineWidth float64) []*Enemy {
+	dir := Normalize2D(direction)
+	mid := Vector3{
+		X: origin.X + dir.X*maxRange/2,
+		Y: origin.Y,
+		Z: origin.Z + dir.Z*maxRange/2,
+	}
+
+	var result []*Enemy
+	for _, enemy := range w.enemiesInRadius(mid, layerFromY(origin.Y), maxRange/2+lineWidth) {
+		if lineContains(origin, direction, maxRange, lineWidth, enemy.Position) {
+			result = append(result, enemy)
+		}
+	}
+	return result
+}
+
+// nearestEnemy returns the closest living enemy on layer within maxRange that
+// passes the optional filter. Caller must hold w.mu.
+func (w *World) nearestEnemy(pos Vector3, layer int, maxRange float64, filter func(enemy *Enemy) bool) *Enemy {
+	id, ok := w.enemyIndex().Nearest(pos, layer, maxRange, func(id string) bool {
+		enemy := w.enemies[id]
+		return enemy != nil && !enemy.IsDead() && (filter == nil || filter(enemy))
+	})
+	if !ok {
+		return nil
+	}
+	return w.enemies[id]
+}
+
+// enemiesInTiles returns all enemies, including recently killed ones, standing
+// on any of the given tiles, which must be a tile and its neighbors around pos.
+// This is safe under a read lock: if the index is stale it falls back to a scan.
+func (w *World) enemiesInTiles(pos Vector3, layer int, tiles map[HexCoord]bool) []*Enemy {
+	var result []*Enemy
+	visit := func(enemy *Enemy) {
+		if layerFromY(enemy.Position.Y) == layer && tiles[WorldToHex(enemy.Position, layer)] {
+			result = append(result, enemy)
+		}
+	}
+
+	if w.enemyGrid == nil || w.enemyGridDirty {
+		for _, enemy := range w.enemies {
+			visit(enemy)
+		}
+		return result
+	}
+
+	center := HexToWorld(WorldToHex(pos, layer))
+	w.enemyGrid.QueryRadius(center, layer, activeAreaRadius, func(id string, _ Vector3) {
+		if enemy := w.enemies[id]; enemy != nil {
+			visit(enemy)
+		}
+	})
+	return result
+}
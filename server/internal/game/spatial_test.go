@@ -0,0 +1,236 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpatialGrid_QueryRadius(t *testing.T) {
+	grid := NewSpatialGrid(4.0)
+	grid.Insert("near", Vector3{X: 1, Z: 1})
+	grid.Insert("edge", Vector3{X: 5})
+	grid.Insert("far", Vector3{X: 20})
+	grid.Insert("below", Vector3{X: 1, Y: -20})
+	assert.Equal(t, 4, grid.Len())
+
+	var found []string
+	grid.QueryRadius(Vector3{}, 0, 5, func(id string, _ Vector3) {
+		found = append(found, id)
+	})
+	assert.ElementsMatch(t, []string{"near", "edge"}, found, "other layers are never returned")
+
+	found = nil
+	grid.QueryRadius(Vector3{Y: 0.9}, -1, 5, func(id string, _ Vector3) {
+		found = append(found, id)
+	})
+	assert.Equal(t, []string{"below"}, found, "the layer is given, not taken from the center's height")
+
+	grid.Clear()
+	assert.Equal(t, 0, grid.Len())
+	grid.QueryRadius(Vector3{}, 0, 50, func(id string, _ Vector3) {
+		t.Errorf("unexpected entry %s after clear", id)
+	})
+}
+
+func TestSpatialGrid_NearestMatchesScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	grid := NewSpatialGrid(4.0)
+	positions := make(map[string]Vector3)
+	for i := 0; i < 500; i++ {
+		id := fmt.Sprintf("e%d", i)
+		pos := Vector3{X: rng.Float64()*200 - 100, Z: rng.Float64()*200 - 100}
+		positions[id] = pos
+		grid.Insert(id, pos)
+	}
+
+	for i := 0; i < 200; i++ {
+		center := Vector3{X: rng.Float64()*200 - 100, Z: rng.Float64()*200 - 100}
+		maxRange := rng.Float64() * 30
+
+		expected := ""
+		best := maxRange
+		for id, pos := range positions {
+			if d := Distance2D(center, pos); d <= best {
+				best = d
+				expected = id
+			}
+		}
+
+		id, ok := grid.Nearest(center, 0, maxRange, nil)
+		assert.Equal(t, expected != "", ok)
+		if ok {
+			assert.InDelta(t, best, Distance2D(center, positions[id]), 1e-9, "query %d", i)
+		}
+	}
+}
+
+func TestSpatialGrid_NearestRespectsFilter(t *testing.T) {
+	grid := NewSpatialGrid(4.0)
+	grid.Insert("skip", Vector3{X: 1})
+	grid.Insert("take", Vector3{X: 9})
+
+	id, ok := grid.Nearest(Vector3{}, 0, 10, func(id string) bool { return id != "skip" })
+	require.True(t, ok)
+	assert.Equal(t, "take", id)
+}
+
+func TestWorldEnemyIndex_TracksChanges(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	cfg := createTestEnemyConfig("melee", 10.0)
+	enemy := createTestEnemy("e1", "zombie", Vector3{X: 3}, cfg)
+	world.addEnemy(enemy)
+
+	assert.Equal(t, enemy, world.nearestEnemy(Vector3{}, 0, 5, nil))
+
+	enemy.Position = Vector3{X: 30}
+	world.markEnemiesMoved()
+	assert.Nil(t, world.nearestEnemy(Vector3{}, 0, 5, nil))
+	assert.Len(t, world.enemiesInRadius(Vector3{X: 28}, 0, 5), 1)
+
+	enemy.Health = 0
+	enemy.Dead = true
+	assert.Empty(t, world.enemiesInRadius(Vector3{X: 28}, 0, 5), "dead enemies aren't targetable")
+
+	world.removeEnemy(enemy.ID)
+	assert.Equal(t, 0, world.enemyIndex().Len())
+}
+
+func TestDungeonProjectileHitsEnemiesOnItsLayer(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	below := addTestDungeonTile(world.Board)
+	player := NewPlayer("p1", "One")
+	player.Position = HexToWorld(below.Coord)
+	world.players[player.ID] = player
+	enemy := createTestEnemy("e1", "zombie", Vector3{X: 3, Y: -20}, createTestEnemyConfig("melee", 10))
+	world.addEnemy(enemy)
+
+	fireball := &Ability{Type: "fireball", Shape: AbilityShapeProjectile, Damage: 20, Speed: 10}
+	result := world.CastPlayerAbility(player, fireball, Vector3{X: 1, Z: 0.3}, CastOptions{ProjectileHeight: 0.9, Homing: true})
+	require.NotEmpty(t, result.ProjectileID)
+
+	for i := 0; i < 10; i++ {
+		world.Update(50 * time.Millisecond)
+	}
+
+	assert.Less(t, enemy.Health, enemy.MaxHealth, "the shot flies at 0.9 but belongs to the dungeon layer")
+}
+
+func TestWorldEnemyIndex_ShapeQueries(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	cfg := createTestEnemyConfig("melee", 10.0)
+	ahead := createTestEnemy("ahead", "zombie", Vector3{X: 8}, cfg)
+	side := createTestEnemy("side", "zombie", Vector3{X: 4, Z: 3}, cfg)
+	behind := createTestEnemy("behind", "zombie", Vector3{X: -4}, cfg)
+	world.addEnemy(ahead)
+	world.addEnemy(side)
+	world.addEnemy(behind)
+
+	line := world.enemiesOnLine(Vector3{}, Vector3{X: 1}, 10, 1)
+	assert.ElementsMatch(t, []*Enemy{ahead}, line)
+
+	cone := world.enemiesInCone(Vector3{}, Vector3{X: 1}, 10, 90)
+	assert.ElementsMatch(t, []*Enemy{ahead, side}, cone)
+}
+
+func TestWorldEnemyIndex_EnemiesInTiles(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.Board = NewBoard(3, 3)
+	rng := rand.New(rand.NewSource(3))
+	cfg := createTestEnemyConfig("melee", 10.0)
+	for i := 0; i < 300; i++ {
+		pos := Vector3{X: rng.Float64()*140 - 70, Z: rng.Float64()*140 - 70}
+		world.addEnemy(createTestEnemy(fmt.Sprintf("e%d", i), "zombie", pos, cfg))
+	}
+
+	playerPos := HexToWorld(HexCoord{Q: 1, R: -1})
+	tiles := make(map[HexCoord]bool)
+	for _, coord := range world.Board.GetActiveTilesForPlayer(playerPos, 0) {
+		tiles[coord] = true
+	}
+
+	var expected []*Enemy
+	for _, enemy := range world.enemies {
+		if tiles[WorldToHex(enemy.Position, 0)] {
+			expected = append(expected, enemy)
+		}
+	}
+	require.NotEmpty(t, expected)
+
+	world.enemyIndex()
+	assert.ElementsMatch(t, expected, world.enemiesInTiles(playerPos, 0, tiles))
+
+	// A stale index falls back to scanning
+	world.markEnemiesMoved()
+	assert.ElementsMatch(t, expected, world.enemiesInTiles(playerPos, 0, tiles))
+}
+
+// newBenchmarkWorld builds a fully active board with a few players, each with a
+// turret, and enemyCount enemies scattered across the overworld
+func newBenchmarkWorld(b *testing.B, enemyCount int) *World {
+	b.Helper()
+	world := newTestWorldWithoutEnemies()
+	world.Board = NewBoard(1, 3)
+	for coord, tile := range world.Board.Tiles {
+		if coord.Layer == 0 {
+			world.Board.EnsureTileGenerated(coord)
+			tile.Active = true
+		}
+	}
+
+	bolt := &Ability{Type: "bolt", Shape: AbilityShapeLine, Damage: 1, Range: 12, Radius: 0.5}
+	for i := 0; i < 4; i++ {
+		player := NewPlayer(fmt.Sprintf("p%d", i), "Bench")
+		player.Position = Vector3{X: float64(i * 10), Z: float64(i * 5)}
+		player.MaxHealth = 1e9
+		player.Health = 1e9
+		world.players[player.ID] = player
+
+		turret := NewTurret(fmt.Sprintf("turret-%d", i), player.ID, player.Position, bolt, "bolt", &Modifier{MinionDuration: 1e6})
+		world.minions[turret.ID] = turret
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	cfg := createTestEnemyConfig("melee", 20.0)
+	cfg.Health = 1e9
+	cfg.MaxHealth = 1e9
+	for i := 0; i < enemyCount; i++ {
+		pos := Vector3{X: rng.Float64()*120 - 60, Z: rng.Float64()*120 - 60}
+		world.addEnemy(createTestEnemy(fmt.Sprintf("enemy-%d", i), "zombie", pos, cfg))
+	}
+	return world
+}
+
+func benchmarkWorldUpdate(b *testing.B, enemyCount int) {
+	world := newBenchmarkWorld(b, enemyCount)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		world.Update(50 * time.Millisecond)
+	}
+}
+
+func BenchmarkWorldUpdate_1000Enemies(b *testing.B) { benchmarkWorldUpdate(b, 1000) }
+func BenchmarkWorldUpdate_2000Enemies(b *testing.B) { benchmarkWorldUpdate(b, 2000) }
+
+func BenchmarkGetWorldStateForPlayer_1000Enemies(b *testing.B) {
+	world := newBenchmarkWorld(b, 1000)
+	world.Update(50 * time.Millisecond)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		world.GetWorldStateForPlayer("p0")
+	}
+}
+
+func BenchmarkNearestEnemy(b *testing.B) {
+	world := newBenchmarkWorld(b, 1000)
+	world.enemyIndex()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		world.nearestEnemy(Vector3{X: float64(i%100) - 50}, 0, 15, nil)
+	}
+}
@@ -12,7 +12,7 @@ import (
 type StateSnapshot struct {
 	// Character state
 	HealthPct     float64
-	ManaPct       float64 // future use
+	ManaPct       float64
 	Position      Vector3
 	LightRadius   float64
 	InDungeon     bool
@@ -37,6 +37,7 @@ type AbilitySnapshot struct {
 	Type     string
 	Category string
 	Ready    bool
+	ManaCost float64
 	Damage   float64
 	Range    float64
 }
@@ -45,6 +46,7 @@ type AbilitySnapshot struct {
 type EnemySnapshot struct {
 	ID        string
 	Type      string
+	Level     int
 	Distance  float64
 	HealthPct float64
 	Angle     float64 // degrees from player facing direction
@@ -63,6 +65,10 @@ func BuildStateSnapshot(player *Player, enemies map[string]*Enemy) *StateSnapsho
 		Aggression:  0.5,
 	}
 
+	if player.MaxMana > 0 {
+		snap.ManaPct = player.Mana / player.MaxMana
+	}
+
 	if player.CharAI != nil {
 		snap.Trust = player.CharAI.Trust
 		snap.Mood = player.CharAI.Mood
@@ -76,7 +82,8 @@ func BuildStateSnapshot(player *Player, enemies map[string]*Enemy) *StateSnapsho
 			snap.Abilities = append(snap.Abilities, AbilitySnapshot{
 				Type:     string(aType),
 				Category: string(ability.Category),
-				Ready:    player.Abilities.CanUseAbility(aType),
+				Ready:    player.Abilities.CanUseAbility(aType) && player.Abilities.CanAfford(aType, player.Mana),
+				ManaCost: ability.ManaCost,
 				Damage:   ability.Damage,
 				Range:    ability.Range,
 			})
@@ -112,6 +119,7 @@ func BuildStateSnapshot(player *Player, enemies map[string]*Enemy) *StateSnapsho
 		snap.Enemies = append(snap.Enemies, EnemySnapshot{
 			ID:        enemy.ID,
 			Type:      enemy.Type,
+			Level:     enemy.Level,
 			Distance:  math.Round(dist*10) / 10, // 1 decimal
 			HealthPct: math.Round(enemy.Health/enemy.MaxHealth*100) / 100,
 			Angle:     math.Round(angle),
@@ -137,7 +145,7 @@ func (s *StateSnapshot) ToPrompt() string {
 	b.WriteString("You are a combat AI for an ARPG character. Respond with a single JSON action.\n")
 
 	// Character state
-	fmt.Fprintf(&b, "HP:%.0f%% ", s.HealthPct*100)
+	fmt.Fprintf(&b, "HP:%.0f%% MP:%.0f%% ", s.HealthPct*100, s.ManaPct*100)
 	if s.InDungeon {
 		fmt.Fprintf(&b, "DUNGEON light:%.0f ", s.LightRadius)
 	}
@@ -153,7 +161,11 @@ func (s *StateSnapshot) ToPrompt() string {
 		if !a.Ready {
 			ready = "CD"
 		}
-		fmt.Fprintf(&b, "%s(%s dmg:%.0f rng:%.0f %s)", a.Type, a.Category, a.Damage, a.Range, ready)
+		if a.ManaCost > 0 {
+			fmt.Fprintf(&b, "%s(%s dmg:%.0f rng:%.0f mp:%.0f %s)", a.Type, a.Category, a.Damage, a.Range, a.ManaCost, ready)
+		} else {
+			fmt.Fprintf(&b, "%s(%s dmg:%.0f rng:%.0f %s)", a.Type, a.Category, a.Damage, a.Range, ready)
+		}
 	}
 	b.WriteString("\n")
 
@@ -167,15 +179,15 @@ func (s *StateSnapshot) ToPrompt() string {
 			if e.ID == s.PriorityTargetID {
 				marker = " [PRIORITY]"
 			}
-			fmt.Fprintf(&b, "- %s dist:%.1f hp:%.0f%% ang:%.0f%s\n",
-				e.Type, e.Distance, e.HealthPct*100, e.Angle, marker)
+			fmt.Fprintf(&b, "- %s id:%s lv:%d dist:%.1f hp:%.0f%% ang:%.0f%s\n",
+				e.Type, e.ID, e.Level, e.Distance, e.HealthPct*100, e.Angle, marker)
 		}
 	}
 
 	// Instructions
 	b.WriteString("Choose action JSON: {action, mood, reason")
 	if len(s.Enemies) > 0 {
-		b.WriteString(", ability?, target?, direction?")
+		b.WriteString(", ability?, target?(nearest|lowest_hp|priority|id), direction?")
 	}
 	b.WriteString("}\n")
 
@@ -17,7 +17,7 @@ type StatusEffect struct {
 	Type       StatusEffectType
 	Duration   float64   // Total duration in seconds
 	Magnitude  float64   // Effect strength (0.0-1.0)
-	AppliedAt  time.Time
+	AppliedAt  time.Time // Set by the entity it's applied to, on its world clock
 	SourceID   string    // ID of entity that applied the effect
 }
 
@@ -27,19 +27,18 @@ func NewStatusEffect(effectType StatusEffectType, duration, magnitude float64, s
 		Type:      effectType,
 		Duration:  duration,
 		Magnitude: magnitude,
-		AppliedAt: time.Now(),
 		SourceID:  sourceID,
 	}
 }
 
-// IsExpired checks if the status effect has expired
-func (se *StatusEffect) IsExpired() bool {
-	return time.Since(se.AppliedAt).Seconds() >= se.Duration
+// IsExpired checks if the status effect has expired by the given time
+func (se *StatusEffect) IsExpired(now time.Time) bool {
+	return now.Sub(se.AppliedAt).Seconds() >= se.Duration
 }
 
-// GetRemainingDuration returns the remaining duration in seconds
-func (se *StatusEffect) GetRemainingDuration() float64 {
-	elapsed := time.Since(se.AppliedAt).Seconds()
+// GetRemainingDuration returns the remaining duration in seconds at the given time
+func (se *StatusEffect) GetRemainingDuration(now time.Time) float64 {
+	elapsed := now.Sub(se.AppliedAt).Seconds()
 	remaining := se.Duration - elapsed
 	if remaining < 0 {
 		return 0
@@ -48,12 +47,12 @@ func (se *StatusEffect) GetRemainingDuration() float64 {
 }
 
 // Serialize converts status effect to JSON-friendly format
-func (se *StatusEffect) Serialize() map[string]interface{} {
+func (se *StatusEffect) Serialize(now time.Time) map[string]interface{} {
 	return map[string]interface{}{
 		"type":      string(se.Type),
 		"duration":  se.Duration,
 		"magnitude": se.Magnitude,
-		"remaining": se.GetRemainingDuration(),
+		"remaining": se.GetRemainingDuration(now),
 	}
 }
 
@@ -0,0 +1,129 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+)
+
+// What happens to summons when their summoner dies
+const (
+	SummonerDeathDespawn = "despawn"
+	SummonerDeathBerserk = "berserk"
+)
+
+// baseLootDropChance is the chance a regular enemy drops anything
+const baseLootDropChance = 0.7
+
+// summonSettings returns the summon tuning from enemies.json with defaults
+// filled in for anything left unset
+func summonSettings() config.SummonsConfig {
+	cfg := config.Enemies.Summons
+	if cfg.OnSummonerDeath != SummonerDeathDespawn {
+		cfg.OnSummonerDeath = SummonerDeathBerserk
+	}
+	if cfg.BerserkDamageMultiplier <= 0 {
+		cfg.BerserkDamageMultiplier = 1.5
+	}
+	if cfg.BerserkSpeedMultiplier <= 0 {
+		cfg.BerserkSpeedMultiplier = 1.3
+	}
+	if cfg.BerserkSeconds <= 0 {
+		cfg.BerserkSeconds = 8.0
+	}
+	if cfg.LootChanceMultiplier <= 0 {
+		cfg.LootChanceMultiplier = 0.2
+	}
+	if cfg.XPMultiplier <= 0 {
+		cfg.XPMultiplier = 0.25
+	}
+	return cfg
+}
+
+// lootDropChance returns the chance a non-elite enemy drops loot. Summons
+// drop less so summoners can't be farmed.
+func lootDropChance(enemy *Enemy) float64 {
+	if enemy.SummonerID != "" {
+		return baseLootDropChance * summonSettings().LootChanceMultiplier
+	}
+	return baseLootDropChance
+}
+
+// spawnSummon brings a summoner's minion into the world.
+// Caller must hold w.mu.
+func (w *World) spawnSummon(spawn SpawnEnemyRequest) *Enemy {
+	w.nextSummonID++
+	enemyID := fmt.Sprintf("enemy-summon-%d", w.nextSummonID)
+	if spawn.SummonerID != "" {
+		enemyID = fmt.Sprintf("enemy-summon-%s-%d", spawn.SummonerID, w.nextSummonID)
+	}
+	summon := NewEnemy(enemyID, spawn.Type, spawn.Position)
+	summon.applyLevel(spawn.Level)
+	summon.SummonerID = spawn.SummonerID
+	w.addEnemy(summon)
+	return summon
+}
+
+// updateSummons recounts each summoner's living summons so the cap only
+// counts what's still around, releases summons whose summoner is dead or
+// gone, and crumbles berserk summons whose time is up. Caller must hold w.mu.
+func (w *World) updateSummons() {
+	now := w.Now()
+	living := make(map[string]int)
+	for _, enemy := range w.enemies {
+		if enemy.SummonerID == "" || enemy.IsDead() {
+			continue
+		}
+		if !enemy.crumbleAt.IsZero() {
+			if now.After(enemy.crumbleAt) {
+				w.crumbleSummon(enemy)
+			}
+			continue
+		}
+		summoner, ok := w.enemies[enemy.SummonerID]
+		if !ok || summoner.IsDead() {
+			w.releaseSummon(enemy, now)
+			continue
+		}
+		living[enemy.SummonerID]++
+	}
+
+	for id, enemy := range w.enemies {
+		if enemy.AI != nil {
+			enemy.AI.CurrentSummons = living[id]
+		}
+	}
+}
+
+// releaseSummon deals with a summon that outlived its summoner: it either
+// falls apart at once or goes berserk for a while first. Caller must hold w.mu.
+func (w *World) releaseSummon(summon *Enemy, now time.Time) {
+	settings := summonSettings()
+	if settings.OnSummonerDeath == SummonerDeathDespawn {
+		w.crumbleSummon(summon)
+		return
+	}
+
+	summon.ApplyBuff(settings.BerserkDamageMultiplier, settings.BerserkSpeedMultiplier, settings.BerserkSeconds)
+	summon.crumbleAt = now.Add(time.Duration(settings.BerserkSeconds * float64(time.Second)))
+	if summon.AI != nil {
+		summon.AI.RageMode = true
+	}
+	log.Printf("[ENEMY] %s goes berserk after losing its summoner %s", summon.ID, summon.SummonerID)
+}
+
+// crumbleSummon kills a summon without loot or experience.
+// Caller must hold w.mu.
+func (w *World) crumbleSummon(summon *Enemy) {
+	summon.Dead = true
+	summon.Health = 0
+	summon.LastUpdate = w.Now()
+	w.emitDeath(DeathEvent{
+		EntityID:   summon.ID,
+		EntityType: "enemy",
+		KillerID:   summon.SummonerID,
+		KillerType: "enemy",
+	})
+}
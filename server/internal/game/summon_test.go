@@ -0,0 +1,113 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withSummonConfig installs a summons section for the duration of a test
+func withSummonConfig(t *testing.T, onSummonerDeath string) {
+	t.Helper()
+	previous := config.Enemies.Summons
+	config.Enemies.Summons = config.SummonsConfig{
+		OnSummonerDeath:         onSummonerDeath,
+		BerserkDamageMultiplier: 2,
+		BerserkSpeedMultiplier:  1.5,
+		BerserkSeconds:          5,
+		LootChanceMultiplier:    0.5,
+		XPMultiplier:            0.5,
+	}
+	t.Cleanup(func() { config.Enemies.Summons = previous })
+}
+
+// newSummonTestWorld returns a world with a summoner that already has two
+// summons out
+func newSummonTestWorld(t *testing.T) (*World, *Enemy, []*Enemy) {
+	t.Helper()
+	withEnemyConfig(t, "zombie", *createTestEnemyConfig("melee", 10))
+	world := newTestWorldWithoutEnemies()
+	summoner := createTestEnemy("necro", "necromancer", Vector3{}, createTestEnemyConfig("summoner", 15))
+	world.addEnemy(summoner)
+
+	var summons []*Enemy
+	for i := 0; i < 2; i++ {
+		summons = append(summons, world.spawnSummon(SpawnEnemyRequest{Type: "zombie", Position: Vector3{X: 2}, SummonerID: summoner.ID}))
+	}
+	return world, summoner, summons
+}
+
+func TestSpawnSummon_UniqueIDsAndOwner(t *testing.T) {
+	world, summoner, summons := newSummonTestWorld(t)
+
+	assert.NotEqual(t, summons[0].ID, summons[1].ID, "summons raised in the same tick don't collide")
+	assert.Len(t, world.enemies, 3)
+	for _, summon := range summons {
+		assert.Equal(t, summoner.ID, summon.SummonerID)
+		assert.Equal(t, summoner.ID, summon.Serialize()["summonerID"])
+	}
+}
+
+func TestUpdateSummons_CapCountsLivingSummons(t *testing.T) {
+	world, summoner, summons := newSummonTestWorld(t)
+	summoner.AI.MaxSummons = 2
+	summoner.AI.CurrentSummons = 0
+
+	world.updateSummons()
+	assert.Equal(t, 2, summoner.AI.CurrentSummons)
+	assert.False(t, summoner.AI.summonReady(), "at the cap")
+
+	summons[0].Dead = true
+	world.updateSummons()
+	assert.Equal(t, 1, summoner.AI.CurrentSummons, "a dead summon frees a slot")
+	assert.True(t, summoner.AI.summonReady())
+}
+
+func TestUpdateSummons_DespawnWhenSummonerDies(t *testing.T) {
+	withSummonConfig(t, SummonerDeathDespawn)
+	world, summoner, summons := newSummonTestWorld(t)
+
+	summoner.Dead = true
+	world.updateSummons()
+	for _, summon := range summons {
+		assert.True(t, summon.IsDead())
+	}
+	require.Len(t, world.deathEvents, 2)
+	assert.Equal(t, summoner.ID, world.deathEvents[0].KillerID)
+}
+
+func TestUpdateSummons_BerserkThenCrumble(t *testing.T) {
+	withSummonConfig(t, SummonerDeathBerserk)
+	world, summoner, summons := newSummonTestWorld(t)
+
+	world.removeEnemy(summoner.ID) // Parked or despawned summoners count as gone too
+	world.updateSummons()
+	summon := summons[0]
+	assert.False(t, summon.IsDead())
+	assert.Equal(t, 2.0, summon.DamageBuff)
+	assert.Equal(t, 1.5, summon.SpeedBuff)
+	assert.True(t, summon.AI.RageMode)
+
+	summon.crumbleAt = time.Now().Add(-time.Second)
+	world.updateSummons()
+	assert.True(t, summon.IsDead())
+	assert.Empty(t, world.groundItems, "crumbling summons drop nothing")
+}
+
+func TestSummons_ReducedRewards(t *testing.T) {
+	withSummonConfig(t, SummonerDeathBerserk)
+	withEnemyConfig(t, "test_zombie", config.EnemyConfig{Name: "Zombie", XPReward: 20})
+	world := newTestWorldWithoutEnemies()
+	player := NewPlayer("p1", "One")
+	world.players[player.ID] = player
+
+	summon := createTestEnemy("s1", "test_zombie", Vector3{}, createTestEnemyConfig("melee", 10))
+	summon.SummonerID = "necro"
+	assert.Equal(t, baseLootDropChance*0.5, lootDropChance(summon))
+
+	world.awardExperience(player.ID, summon)
+	assert.Equal(t, 10.0, player.Experience)
+}
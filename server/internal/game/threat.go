@@ -0,0 +1,196 @@
+package game
+
+import (
+	"math"
+	"sort"
+
+	"github.com/PersonThing/cs-crawler/server/internal/config"
+)
+
+// minThreat is the level below which a decayed entry is dropped from the table
+const minThreat = 0.01
+
+// ThreatEntry is one player's or minion's standing on an enemy's threat table
+type ThreatEntry struct {
+	ID       string
+	Threat   float64
+	IsMinion bool
+}
+
+// ThreatTable tracks how much each player or minion has angered an enemy
+type ThreatTable struct {
+	entries map[string]*ThreatEntry
+}
+
+// NewThreatTable creates an empty threat table
+func NewThreatTable() *ThreatTable {
+	return &ThreatTable{entries: make(map[string]*ThreatEntry)}
+}
+
+// Add increases an entity's threat, creating its entry if needed
+func (t *ThreatTable) Add(id string, amount float64, isMinion bool) {
+	if amount <= 0 {
+		return
+	}
+	if entry, ok := t.entries[id]; ok {
+		entry.Threat += amount
+		return
+	}
+	t.entries[id] = &ThreatEntry{ID: id, Threat: amount, IsMinion: isMinion}
+}
+
+// Raise sets an entity's threat to at least the given amount
+func (t *ThreatTable) Raise(id string, amount float64, isMinion bool) {
+	if entry, ok := t.entries[id]; ok {
+		entry.Threat = math.Max(entry.Threat, amount)
+		return
+	}
+	t.Add(id, amount, isMinion)
+}
+
+// Get returns an entity's threat, or 0 if it isn't on the table
+func (t *ThreatTable) Get(id string) float64 {
+	if entry, ok := t.entries[id]; ok {
+		return entry.Threat
+	}
+	return 0
+}
+
+// Has returns true if the entity is on the table
+func (t *ThreatTable) Has(id string) bool {
+	_, ok := t.entries[id]
+	return ok
+}
+
+// Remove drops an entity from the table
+func (t *ThreatTable) Remove(id string) {
+	delete(t.entries, id)
+}
+
+// Decay reduces all threat by a fraction per second and drops negligible entries
+func (t *ThreatTable) Decay(delta, ratePerSecond float64) {
+	if ratePerSecond <= 0 {
+		return
+	}
+	factor := math.Exp(-ratePerSecond * delta)
+	for id, entry := range t.entries {
+		entry.Threat *= factor
+		if entry.Threat < minThreat {
+			delete(t.entries, id)
+		}
+	}
+}
+
+// Top returns the entry with the highest threat, or nil if the table is empty.
+// Ties go to the lowest ID so selection is deterministic.
+func (t *ThreatTable) Top() *ThreatEntry {
+	var top *ThreatEntry
+	for _, entry := range t.entries {
+		if top == nil || entry.Threat > top.Threat || (entry.Threat == top.Threat && entry.ID < top.ID) {
+			top = entry
+		}
+	}
+	return top
+}
+
+// Entries returns the table sorted by threat, highest first
+func (t *ThreatTable) Entries() []ThreatEntry {
+	entries := make([]ThreatEntry, 0, len(t.entries))
+	for _, entry := range t.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Threat != entries[j].Threat {
+			return entries[i].Threat > entries[j].Threat
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries
+}
+
+// Serialize converts the threat table to a JSON-friendly list, highest first
+func (t *ThreatTable) Serialize() []map[string]interface{} {
+	entries := t.Entries()
+	result := make([]map[string]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, map[string]interface{}{
+			"id":       entry.ID,
+			"threat":   math.Round(entry.Threat*10) / 10,
+			"isMinion": entry.IsMinion,
+		})
+	}
+	return result
+}
+
+// threatSettings returns the threat tuning from combat.json with defaults
+// filled in for anything left unset
+func threatSettings() config.ThreatConfig {
+	cfg := config.Combat.Threat
+	if cfg.DamageMultiplier <= 0 {
+		cfg.DamageMultiplier = 1.0
+	}
+	if cfg.HealingMultiplier <= 0 {
+		cfg.HealingMultiplier = 0.5
+	}
+	if cfg.MinionMultiplier <= 0 {
+		cfg.MinionMultiplier = 0.75
+	}
+	if cfg.ProximityThreat <= 0 {
+		cfg.ProximityThreat = 5.0
+	}
+	if cfg.TauntBonus <= 0 {
+		cfg.TauntBonus = 50.0
+	}
+	if cfg.DecayPerSecond < 0 {
+		cfg.DecayPerSecond = 0
+	}
+	if cfg.SwitchRatio < 1.0 {
+		cfg.SwitchRatio = 1.1
+	}
+	return cfg
+}
+
+// AddThreat records threat from a player or minion against this enemy
+func (ai *EnemyAI) AddThreat(sourceID string, amount float64, isMinion bool) {
+	if isMinion {
+		amount *= threatSettings().MinionMultiplier
+	}
+	ai.Threat.Add(sourceID, amount, isMinion)
+}
+
+// Taunt forces the enemy onto the taunter by putting it above everyone else
+// on the threat table, bypassing switch hysteresis
+func (ai *EnemyAI) Taunt(sourceID string, isMinion bool) {
+	topThreat := 0.0
+	if top := ai.Threat.Top(); top != nil {
+		topThreat = top.Threat
+	}
+	ai.Threat.Raise(sourceID, topThreat*ai.ThreatSwitchRatio+threatSettings().TauntBonus, isMinion)
+	ai.TargetID = sourceID
+	if ai.State == AIStateIdle {
+		ai.State = AIStateChase
+	}
+}
+
+// addDamageThreat records threat on an enemy for damage dealt by a caster, which
+// is either a player or one of their minions. Caller must hold w.mu.
+func (w *World) addDamageThreat(enemy *Enemy, casterID, ownerID string, damage float64) {
+	if enemy.AI == nil || enemy.Dead {
+		return
+	}
+	enemy.AI.AddThreat(casterID, damage*threatSettings().DamageMultiplier, casterID != ownerID)
+}
+
+// addHealingThreat records threat for healing done to a player on every enemy
+// already fighting that player. Caller must hold w.mu.
+func (w *World) addHealingThreat(healerID, targetID string, amount float64) {
+	threat := amount * threatSettings().HealingMultiplier
+	for _, enemy := range w.enemies {
+		if enemy.AI == nil || enemy.Dead {
+			continue
+		}
+		if enemy.AI.Threat.Has(targetID) || enemy.AI.TargetID == targetID {
+			enemy.AI.AddThreat(healerID, threat, false)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThreatTable_AddAndTop(t *testing.T) {
+	table := NewThreatTable()
+	assert.Nil(t, table.Top())
+
+	table.Add("a", 10, false)
+	table.Add("b", 15, false)
+	table.Add("a", 10, false)
+
+	top := table.Top()
+	require.NotNil(t, top)
+	assert.Equal(t, "a", top.ID)
+	assert.Equal(t, 20.0, top.Threat)
+
+	entries := table.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "b", entries[1].ID)
+}
+
+func TestThreatTable_RaiseKeepsHigherThreat(t *testing.T) {
+	table := NewThreatTable()
+	table.Add("a", 10, false)
+
+	table.Raise("a", 5, false)
+	assert.Equal(t, 10.0, table.Get("a"))
+
+	table.Raise("a", 12, false)
+	assert.Equal(t, 12.0, table.Get("a"))
+}
+
+func TestThreatTable_Decay(t *testing.T) {
+	table := NewThreatTable()
+	table.Add("a", 100, false)
+	table.Add("b", 0.015, false)
+
+	table.Decay(1.0, 0.5)
+
+	assert.InDelta(t, 60.65, table.Get("a"), 0.01)
+	assert.False(t, table.Has("b"), "negligible entries are dropped")
+}
+
+func TestThreatTable_Serialize(t *testing.T) {
+	table := NewThreatTable()
+	table.Add("pet-1", 3.33, true)
+	table.Add("player-1", 7, false)
+
+	data := table.Serialize()
+	require.Len(t, data, 2)
+	assert.Equal(t, "player-1", data[0]["id"])
+	assert.Equal(t, 3.3, data[1]["threat"])
+	assert.Equal(t, true, data[1]["isMinion"])
+}
+
+func TestEnemyAI_ThreatOverridesProximity(t *testing.T) {
+	cfg := createTestEnemyConfig("melee", 20.0)
+	enemy := createTestEnemy("enemy-1", "zombie", Vector3{}, cfg)
+
+	tank := NewPlayer("tank", "Tank")
+	tank.Position = Vector3{X: 2}
+	dps := NewPlayer("dps", "DPS")
+	dps.Position = Vector3{X: 15}
+
+	ctx := &EnemyAIContext{
+		Players:      map[string]*Player{tank.ID: tank, dps.ID: dps},
+		Enemies:      map[string]*Enemy{enemy.ID: enemy},
+		DeltaSeconds: 0.016,
+	}
+
+	enemy.AI.findTarget(enemy, ctx)
+	assert.Equal(t, "tank", enemy.AI.TargetID, "nearest player wins with no damage dealt")
+
+	enemy.AI.AddThreat("dps", 50, false)
+	enemy.AI.findTarget(enemy, ctx)
+	assert.Equal(t, "dps", enemy.AI.TargetID)
+}
+
+func TestEnemyAI_ThreatSwitchHysteresis(t *testing.T) {
+	cfg := createTestEnemyConfig("melee", 20.0)
+	cfg.AI.ThreatSwitch = 1.5
+	enemy := createTestEnemy("enemy-1", "zombie", Vector3{}, cfg)
+	assert.Equal(t, 1.5, enemy.AI.ThreatSwitchRatio)
+
+	p1 := NewPlayer("p1", "One")
+	p1.Position = Vector3{X: 30}
+	p2 := NewPlayer("p2", "Two")
+	p2.Position = Vector3{X: 30}
+
+	ctx := &EnemyAIContext{
+		Players:      map[string]*Player{p1.ID: p1, p2.ID: p2},
+		Enemies:      map[string]*Enemy{enemy.ID: enemy},
+		DeltaSeconds: 0.016,
+	}
+
+	enemy.AI.AddThreat("p1", 100, false)
+	enemy.AI.findTarget(enemy, ctx)
+	require.Equal(t, "p1", enemy.AI.TargetID)
+
+	enemy.AI.AddThreat("p2", 140, false)
+	enemy.AI.findTarget(enemy, ctx)
+	assert.Equal(t, "p1", enemy.AI.TargetID, "not enough threat to pull aggro")
+
+	enemy.AI.AddThreat("p2", 20, false)
+	enemy.AI.findTarget(enemy, ctx)
+	assert.Equal(t, "p2", enemy.AI.TargetID)
+}
+
+func TestEnemyAI_ThreatDropsDeadPlayers(t *testing.T) {
+	cfg := createTestEnemyConfig("melee", 10.0)
+	enemy := createTestEnemy("enemy-1", "zombie", Vector3{}, cfg)
+
+	player := NewPlayer("p1", "One")
+	player.Position = Vector3{X: 50}
+	ctx := &EnemyAIContext{
+		Players:      map[string]*Player{player.ID: player},
+		Enemies:      map[string]*Enemy{enemy.ID: enemy},
+		DeltaSeconds: 0.016,
+	}
+
+	enemy.AI.AddThreat("p1", 100, false)
+	enemy.AI.findTarget(enemy, ctx)
+	require.Equal(t, "p1", enemy.AI.TargetID)
+
+	player.Health = 0
+	enemy.AI.findTarget(enemy, ctx)
+	assert.Equal(t, "", enemy.AI.TargetID)
+	assert.False(t, enemy.AI.Threat.Has("p1"))
+}
+
+func TestEnemyAI_MinionDrawsAggro(t *testing.T) {
+	cfg := createTestEnemyConfig("melee", 20.0)
+	enemy := createTestEnemy("enemy-1", "zombie", Vector3{}, cfg)
+
+	player := NewPlayer("p1", "One")
+	player.Position = Vector3{X: 5}
+	pet := &Minion{ID: "pet-1", OwnerID: player.ID, Type: MinionTypePet, Position: Vector3{Z: 8}}
+
+	ctx := &EnemyAIContext{
+		Players:      map[string]*Player{player.ID: player},
+		Enemies:      map[string]*Enemy{enemy.ID: enemy},
+		Minions:      map[string]*Minion{pet.ID: pet},
+		DeltaSeconds: 0.016,
+	}
+
+	enemy.AI.AddThreat("pet-1", 100, true)
+	enemy.AI.Update(enemy, ctx)
+
+	assert.Equal(t, "pet-1", enemy.AI.TargetID)
+	assert.Equal(t, pet.Position, enemy.AI.TargetPosition)
+	assert.Greater(t, enemy.Position.Z, 0.0, "enemy should chase the pet")
+}
+
+func TestHitEnemy_TauntSwitchesTarget(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	dps := NewPlayer("dps", "DPS")
+	tank := NewPlayer("tank", "Tank")
+	world.players[dps.ID] = dps
+	world.players[tank.ID] = tank
+
+	cfg := createTestEnemyConfig("melee", 20.0)
+	cfg.Health = 1000
+	cfg.MaxHealth = 1000
+	enemy := createTestEnemy("enemy-1", "zombie", Vector3{X: 3}, cfg)
+	world.enemies[enemy.ID] = enemy
+
+	fireball := &Ability{Type: "line", Shape: AbilityShapeLine, Damage: 200, Range: 10, Radius: 1}
+	world.CastPlayerAbility(dps, fireball, Vector3{X: 1}, CastOptions{})
+	assert.Equal(t, 200.0, enemy.AI.Threat.Get("dps"))
+
+	taunt := &Ability{Type: "taunt", Shape: AbilityShapeGroundAoE, Damage: 1, AreaRadius: 8, Taunt: true}
+	world.CastPlayerAbility(tank, taunt, Vector3{X: 1}, CastOptions{})
+
+	assert.Equal(t, "tank", enemy.AI.TargetID)
+	assert.Greater(t, enemy.AI.Threat.Get("tank"), enemy.AI.Threat.Get("dps")*enemy.AI.ThreatSwitchRatio)
+}
+
+func TestHealingDrawsThreat(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	healer := NewPlayer("healer", "Healer")
+	tank := NewPlayer("tank", "Tank")
+	tank.Position = Vector3{X: 3}
+	tank.Health = 50
+	world.players[healer.ID] = healer
+	world.players[tank.ID] = tank
+
+	engaged := createTestEnemy("engaged", "zombie", Vector3{X: 5}, createTestEnemyConfig("melee", 20.0))
+	engaged.AI.AddThreat("tank", 30, false)
+	bystander := createTestEnemy("bystander", "zombie", Vector3{X: 5}, createTestEnemyConfig("melee", 20.0))
+	world.enemies[engaged.ID] = engaged
+	world.enemies[bystander.ID] = bystander
+
+	mend := &Ability{Type: "mend", Shape: AbilityShapeSelfBuff, Heal: 40, AreaRadius: 8}
+	world.CastPlayerAbility(healer, mend, Vector3{}, CastOptions{})
+
+	assert.Equal(t, 90.0, tank.Health)
+	assert.Equal(t, healer.MaxHealth, healer.Health)
+	// Only the 40 points actually healed on the tank count
+	assert.InDelta(t, 40*threatSettings().HealingMultiplier, engaged.AI.Threat.Get("healer"), 0.001)
+	assert.False(t, bystander.AI.Threat.Has("healer"))
+}
+
+func TestWorldUpdate_EnemyAttacksMinion(t *testing.T) {
+	world := newTestWorldWithoutEnemies()
+	world.Board = newTestBoard(true)
+	owner := NewPlayer("p1", "One")
+	owner.Position = Vector3{X: 15}
+	world.players[owner.ID] = owner
+
+	ward := &Ability{Type: "ward", Shape: AbilityShapeSelfBuff}
+	turret := NewTurret("turret-1", owner.ID, Vector3{}, ward, ward.Type, &Modifier{MinionDuration: 1e6})
+	world.minions[turret.ID] = turret
+
+	enemy := createTestEnemy("enemy-1", "zombie", Vector3{X: 1}, createTestEnemyConfig("melee", 5.0))
+	world.addEnemy(enemy)
+
+	world.Update(50 * time.Millisecond)
+	require.Equal(t, turret.ID, enemy.AI.TargetID, "the owner is out of aggro range")
+	assert.Less(t, enemy.AI.Threat.Get(turret.ID), threatSettings().ProximityThreat, "minion proximity threat is scaled down")
+
+	for i := 0; i < 200 && world.minions[turret.ID] != nil; i++ {
+		world.Update(50 * time.Millisecond)
+	}
+
+	assert.NotContains(t, world.minions, turret.ID, "the turret falls once its health runs out")
+	assert.True(t, turret.IsDead())
+	require.NotEmpty(t, world.deathEvents)
+	assert.Equal(t, "minion", world.deathEvents[len(world.deathEvents)-1].EntityType)
+	assert.Equal(t, owner.MaxHealth, owner.Health)
+}
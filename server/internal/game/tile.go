@@ -57,16 +57,19 @@ type TileLighting struct {
 
 // Tile represents a single hex tile in the world
 type Tile struct {
-	Coord      HexCoord         `json:"coord"`
-	Biome      string           `json:"biome"`
-	TileType   TileType         `json:"tileType"`
-	Difficulty int              `json:"difficulty"`
-	Generated  bool             `json:"generated"`
-	Explored   bool             `json:"explored"`   // whether any player has visited
-	EdgePaths  [6]bool          `json:"edgePaths"`  // which hex edges have paths to neighbors
-	Features   []TerrainFeature `json:"features"`
-	Spawns     []EnemySpawnPoint `json:"spawns"`
-	Lighting   TileLighting     `json:"lighting"`
+	Coord        HexCoord          `json:"coord"`
+	Biome        string            `json:"biome"`
+	TileType     TileType          `json:"tileType"`
+	Difficulty   int               `json:"difficulty"`
+	Generated    bool              `json:"generated"`
+	Explored     bool              `json:"explored"`  // whether any player has visited
+	EdgePaths    [6]bool           `json:"edgePaths"` // which hex edges have paths to neighbors
+	Features     []TerrainFeature  `json:"features"`
+	Spawns       []EnemySpawnPoint `json:"spawns"`
+	Lighting     TileLighting      `json:"lighting"`
+	PvPZone      bool              `json:"pvpZone"`                // players can fight each other here in PvP zone games
+	BossType     string            `json:"bossType,omitempty"`     // boss enemy type spawned in a dungeon boss room
+	BossDefeated bool              `json:"bossDefeated,omitempty"` // the boss is dead; the room stays cleared
 
 	// Dungeon entrance (only for TileTypeDungeonEntrance)
 	DungeonEntryPos   *Vector3  `json:"dungeonEntryPos,omitempty"`
@@ -195,11 +198,11 @@ func generateOverworldTile(tile *Tile, center Vector3, rng *rand.Rand, neighborE
 		tile.EdgePaths[pathDirs[i]] = true
 	}
 
-	// Match neighbor edges if they're already generated
+	// Match neighbor edges if they're already generated. The neighbor in
+	// direction dir reports its edge facing us, so our side of it is dir.
 	for dir, hasPath := range neighborEdges {
-		oppositeDir := (dir + 3) % 6
 		if hasPath {
-			tile.EdgePaths[oppositeDir] = true
+			tile.EdgePaths[dir] = true
 		}
 	}
 
@@ -346,6 +349,7 @@ func (t *Tile) Serialize() map[string]interface{} {
 		"edgePaths": t.EdgePaths,
 		"features":  features,
 		"spawns":    spawns,
+		"pvpZone":   t.PvPZone,
 		"lighting": map[string]interface{}{
 			"ambientColor":     t.Lighting.AmbientColor,
 			"ambientIntensity": t.Lighting.AmbientIntensity,
@@ -355,6 +359,11 @@ func (t *Tile) Serialize() map[string]interface{} {
 		},
 	}
 
+	if t.BossType != "" {
+		result["bossType"] = t.BossType
+		result["bossDefeated"] = t.BossDefeated
+	}
+
 	if t.DungeonEntryPos != nil {
 		result["dungeonEntryPos"] = map[string]interface{}{
 			"x": t.DungeonEntryPos.X, "y": t.DungeonEntryPos.Y, "z": t.DungeonEntryPos.Z,
@@ -19,6 +19,9 @@ type World struct {
 	// Hex board (replaces old Level)
 	Board *Board
 
+	// Combat ruleset (PvP, friendly fire)
+	Rules GameRules
+
 	// Track which tile data each player has received
 	playerTilesSent map[string]map[HexCoord]bool // playerID -> set of sent tile coords
 
@@ -28,42 +31,74 @@ type World struct {
 	projectiles map[string]*Projectile
 	minions     map[string]*Minion
 	groundItems map[string]*GroundItem
+	lootChests  map[string]*LootChest
+	packs       map[string]*EnemyPack
+
+	// Living enemies of inactive tiles, by home tile (see leash.go)
+	parkedEnemies map[HexCoord][]*Enemy
+
+	// Spatial index for enemy queries, rebuilt lazily when enemies change
+	enemyGrid      *SpatialGrid
+	enemyGridDirty bool
 
 	// Events (for broadcasting)
 	damageEvents      []DamageEvent
 	deathEvents       []DeathEvent
 	abilityCastEvents []AbilityCastEvent
 
+	// Death recaps waiting to be sent to the players who died
+	deathRecaps []DeathRecap
+
+	// Boss encounters: pending ground AoEs and events for the boss room
+	telegraphs []*Telegraph
+	bossEvents []BossPhaseEvent
+
+	// Per-player damage meter, and an optional shared log of combat events
+	combatStats *CombatTracker
+	CombatLog   *CombatLog
+
 	// Character AI
 	LLM              *LLMManager
 	pendingAIActions []PendingAIAction
 
+	// Simulation time; every gameplay timer runs on it (see clock.go)
+	clock *SimClock
+
 	// Item generation
 	nextItemID int
+
+	// Summoned enemy IDs, unique even when several are raised in one tick
+	nextSummonID int
+
+	// Boss, add and chest IDs, unique across every encounter in the world
+	nextEncounterID int
 }
 
 // NewWorld creates a new game world with a hex board
-func NewWorld(id string, llmProvider LLMProvider) *World {
+func NewWorld(id string, llm *LLMManager) *World {
 	w := &World{
 		ID:                id,
 		created:           time.Now(),
+		clock:             NewSimClock(time.Now()),
+		Rules:             DefaultGameRules(),
 		playerTilesSent:   make(map[string]map[HexCoord]bool),
 		players:           make(map[string]*Player),
 		enemies:           make(map[string]*Enemy),
 		projectiles:       make(map[string]*Projectile),
 		minions:           make(map[string]*Minion),
 		groundItems:       make(map[string]*GroundItem),
+		lootChests:        make(map[string]*LootChest),
+		packs:             make(map[string]*EnemyPack),
+		parkedEnemies:     make(map[HexCoord][]*Enemy),
 		damageEvents:      make([]DamageEvent, 0),
 		deathEvents:       make([]DeathEvent, 0),
 		abilityCastEvents: make([]AbilityCastEvent, 0),
+		combatStats:       NewCombatTracker(),
 		nextItemID:        1,
 	}
 
-	// Initialize LLM manager with the provided provider
-	w.LLM = NewLLMManager(LLMManagerConfig{
-		Provider: llmProvider,
-	})
-	w.LLM.Start()
+	// Character AI shares the server's inference workers
+	w.LLM = llm
 
 	// Generate hex board (3 rings = 37 tiles)
 	seed := time.Now().UnixNano()
@@ -82,41 +117,78 @@ func (w *World) Update(delta time.Duration) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	deltaSeconds := delta.Seconds()
-
 	// Clear events from previous tick
 	w.damageEvents = w.damageEvents[:0]
 	w.deathEvents = w.deathEvents[:0]
 	w.abilityCastEvents = w.abilityCastEvents[:0]
 
-	// Update player tile tracking and generate/activate nearby tiles
+	// Everything below runs on simulation time, which stands still while paused
+	delta = w.simClock().Advance(delta)
+	if delta <= 0 {
+		return
+	}
+	deltaSeconds := delta.Seconds()
+	now := w.Now()
+
+	// Update player tile tracking and generate/activate nearby tiles, then
+	// park whatever is left behind in tiles nobody is near
+	activeTiles := make(map[HexCoord]bool)
 	for _, player := range w.players {
-		w.updatePlayerTiles(player)
+		for _, coord := range w.updatePlayerTiles(player) {
+			activeTiles[coord] = true
+		}
 	}
+	w.deactivateTiles(activeTiles)
 
 	// Update players
 	for _, player := range w.players {
+		prev := player.Position
+		forced := player.Movement != nil
 		player.Update(deltaSeconds)
+		if !forced {
+			// Movement abilities validate their own paths when cast
+			player.Position = w.resolveEntityMovement(prev, player.Position)
+		}
+
+		// Resolve leaps that touched down this tick
+		if landed := player.ConsumeLanding(); landed != nil {
+			if hitTargets := w.resolveLanding(player, landed); hitTargets != nil {
+				w.abilityCastEvents = append(w.abilityCastEvents, AbilityCastEvent{
+					CasterID: player.ID, CasterType: "player", OwnerID: player.ID,
+					AbilityType: string(landed.Ability.Type), Position: player.Position,
+					HitTargets: hitTargets,
+				})
+			}
+		}
 	}
 
 	// Update enemies with AI
 	aiContext := &EnemyAIContext{
 		Players:      w.players,
 		Enemies:      w.enemies,
+		Minions:      w.minions,
 		DeltaSeconds: deltaSeconds,
 		World:        w,
 	}
 
 	// Collect spawn requests to avoid modifying map during iteration
 	var spawnRequests []SpawnEnemyRequest
+	w.updateSummons()
 
 	for _, enemy := range w.enemies {
-		// Only update enemies in active tiles
-		if !w.isEntityInActiveTile(enemy.Position) {
+		// Only update enemies in active tiles, or on their way back to one
+		if !w.isEnemyActive(enemy) {
 			continue
 		}
 
+		prev := enemy.Position
 		attackResult := enemy.UpdateAI(aiContext)
+		enemy.Position = w.resolveEntityMovement(prev, enemy.Position)
+		if enemy.Position != prev {
+			w.markEnemiesMoved()
+		}
+		w.updateEliteAffixes(enemy, aiContext)
+		w.updateBoss(enemy)
 		if attackResult == nil {
 			continue
 		}
@@ -126,24 +198,24 @@ func (w *World) Update(delta time.Duration) {
 			for _, player := range w.players {
 				distance := Distance2D(enemy.Position, player.Position)
 				if distance <= attackResult.ExplosionRadius {
-					player.Health -= attackResult.Damage
-					if player.Health < 0 {
-						player.Health = 0
-					}
-					w.damageEvents = append(w.damageEvents, DamageEvent{
-						TargetID: player.ID,
-						Damage:   attackResult.Damage,
+					w.damagePlayer(player, DamageInfo{
+						Amount:   attackResult.Damage,
 						Type:     attackResult.DamageType,
-					})
+						SourceID: enemy.ID,
+						TargetID: player.ID,
+					}, "enemy", enemyDisplayName(enemy))
 				}
 			}
 			enemy.Dead = true
 			enemy.Health = 0
-			w.deathEvents = append(w.deathEvents, DeathEvent{
+			w.emitDeath(DeathEvent{
 				EntityID:   enemy.ID,
 				EntityType: "enemy",
 				KillerID:   enemy.ID,
+				KillerType: "enemy",
 			})
+			w.triggerDeathAffixes(enemy)
+			w.packMemberDied(enemy)
 		} else if attackResult.IsProjectile {
 			projectileID := fmt.Sprintf("proj-enemy-%s-%d", enemy.ID, time.Now().UnixNano())
 			projectile := NewEnemyProjectile(
@@ -154,7 +226,7 @@ func (w *World) Update(delta time.Duration) {
 				attackResult.Damage,
 				attackResult.DamageType,
 			)
-			w.projectiles[projectileID] = projectile
+			w.addProjectile(projectile)
 			w.abilityCastEvents = append(w.abilityCastEvents, AbilityCastEvent{
 				CasterID:    enemy.ID,
 				CasterType:  "enemy",
@@ -165,137 +237,129 @@ func (w *World) Update(delta time.Duration) {
 			})
 		} else if attackResult.ApplyBuff != nil {
 			buff := attackResult.ApplyBuff
-			for _, ally := range w.enemies {
-				if ally.ID == enemy.ID || ally.Dead {
-					continue
-				}
-				distance := Distance2D(enemy.Position, ally.Position)
-				if distance <= buff.Radius {
+			for _, ally := range w.enemiesInRadius(enemy.Position, layerFromY(enemy.Position.Y), buff.Radius) {
+				if ally.ID != enemy.ID {
 					ally.ApplyBuff(buff.DamageMult, buff.SpeedMult, buff.Duration)
 				}
 			}
 		} else if len(attackResult.SpawnEnemies) > 0 {
 			spawnRequests = append(spawnRequests, attackResult.SpawnEnemies...)
 		} else if attackResult.TargetID != "" {
-			player, exists := w.players[attackResult.TargetID]
-			if exists {
-				damage := attackResult.Damage * enemy.DamageBuff
-				player.Health -= damage
-				if player.Health < 0 {
-					player.Health = 0
-				}
-				w.damageEvents = append(w.damageEvents, DamageEvent{
-					TargetID: player.ID,
-					Damage:   damage,
-					Type:     attackResult.DamageType,
-				})
+			damage := DamageInfo{
+				Amount:   attackResult.Damage * enemy.DamageBuff,
+				Type:     attackResult.DamageType,
+				SourceID: enemy.ID,
+				TargetID: attackResult.TargetID,
+			}
+			if player, exists := w.players[attackResult.TargetID]; exists {
+				healthBefore := player.Health
+				w.damagePlayer(player, damage, "enemy", enemyDisplayName(enemy))
+				w.applyLifeSteal(enemy, healthBefore-player.Health)
+			} else if minion, exists := w.minions[attackResult.TargetID]; exists {
+				healthBefore := minion.Health
+				w.damageMinion(minion, damage, enemyDisplayName(enemy))
+				w.applyLifeSteal(enemy, healthBefore-minion.Health)
 			}
 		}
 	}
 
+	w.updatePacks()
+	w.resolveTelegraphs(now)
+
 	// Process spawn requests
 	for _, spawn := range spawnRequests {
-		enemyID := fmt.Sprintf("enemy-summon-%d", time.Now().UnixNano())
-		newEnemy := NewEnemy(enemyID, spawn.Type, spawn.Position)
-		w.enemies[enemyID] = newEnemy
+		w.spawnSummon(spawn)
 	}
 
 	// Update projectiles and check collisions
 	for id, projectile := range w.projectiles {
 		if projectile.IsHoming {
-			var nearest *Enemy
-			minDistance := 100.0
-			for _, enemy := range w.enemies {
-				if enemy.IsDead() {
-					continue
-				}
-				distance := Distance2D(projectile.Position, enemy.Position)
-				if distance < minDistance {
-					minDistance = distance
-					nearest = enemy
-				}
-			}
-			if nearest != nil {
+			if nearest := w.nearestEnemy(projectile.Position, projectile.Layer, 100.0, nil); nearest != nil {
 				projectile.UpdateHoming(nearest.Position, deltaSeconds)
 			}
 		}
 
+		prev := projectile.Position
 		projectile.Update(deltaSeconds)
 
+		// Projectiles are destroyed on impact with tall terrain or closed edges
+		if w.projectileHitTerrain(projectile, prev) {
+			delete(w.projectiles, id)
+			continue
+		}
+
 		if projectile.IsEnemyProjectile {
+			hit := false
 			for _, player := range w.players {
 				if player.Health <= 0 {
 					continue
 				}
 				distance := Distance2D(projectile.Position, player.Position)
 				if distance <= projectile.Radius+0.5 {
-					player.Health -= projectile.Damage
-					if player.Health < 0 {
-						player.Health = 0
-					}
-					w.damageEvents = append(w.damageEvents, DamageEvent{
-						TargetID: player.ID,
-						Damage:   projectile.Damage,
+					healthBefore := player.Health
+					w.damagePlayer(player, DamageInfo{
+						Amount:   projectile.Damage,
 						Type:     projectile.DamageType,
-					})
+						SourceID: projectile.OwnerID,
+						TargetID: player.ID,
+					}, "enemy", w.enemySource(projectile.OwnerID))
+					w.applyLifeSteal(w.enemies[projectile.OwnerID], healthBefore-player.Health)
 					delete(w.projectiles, id)
+					hit = true
 					break
 				}
 			}
-			continue
-		}
-
-		if enemy := CheckProjectileCollision(projectile, w.enemies, projectile.Radius); enemy != nil {
-			if projectile.HasHitEnemy(enemy.ID) {
+			if hit {
 				continue
 			}
 
-			damageInfo := DamageInfo{
-				Amount:   projectile.Damage,
-				Type:     projectile.DamageType,
-				SourceID: projectile.OwnerID,
-				TargetID: enemy.ID,
-			}
-
-			died := ApplyDamage(enemy, damageInfo)
-
-			if projectile.StatusEffectInfo != nil {
-				statusEffect := NewStatusEffect(
-					projectile.StatusEffectInfo.Type,
-					projectile.StatusEffectInfo.Duration,
-					projectile.StatusEffectInfo.Magnitude,
-					projectile.OwnerID,
-				)
-				enemy.ApplyStatusEffect(statusEffect)
+			for _, minion := range w.minions {
+				if Distance2D(projectile.Position, minion.Position) <= projectile.Radius+0.5 {
+					healthBefore := minion.Health
+					w.damageMinion(minion, DamageInfo{
+						Amount:   projectile.Damage,
+						Type:     projectile.DamageType,
+						SourceID: projectile.OwnerID,
+						TargetID: minion.ID,
+					}, w.enemySource(projectile.OwnerID))
+					w.applyLifeSteal(w.enemies[projectile.OwnerID], healthBefore-minion.Health)
+					delete(w.projectiles, id)
+					break
+				}
 			}
+			continue
+		}
 
-			w.damageEvents = append(w.damageEvents, DamageEvent{
-				TargetID: enemy.ID,
-				Damage:   damageInfo.Amount,
-				Type:     damageInfo.Type,
-			})
+		if owner, ok := w.players[projectile.OwnerID]; ok {
+			if victim := w.projectilePlayerHit(projectile, owner); victim != nil {
+				w.hitPlayer(owner, victim, projectile.Damage, projectile.DamageType, projectile.AbilityType)
 
-			if died {
-				w.deathEvents = append(w.deathEvents, DeathEvent{
-					EntityID:   enemy.ID,
-					EntityType: "enemy",
-					KillerID:   projectile.OwnerID,
-				})
-				w.dropLoot(enemy)
+				// Pierce bookkeeping is shared between enemy and player targets
+				if projectile.IsPiercing {
+					projectile.MarkEnemyHit(victim.ID)
+					if !projectile.CanPierce() {
+						delete(w.projectiles, id)
+					}
+				} else {
+					delete(w.projectiles, id)
+				}
+				continue
 			}
+		}
 
+		for _, enemy := range w.projectileEnemyHits(projectile) {
+			w.projectileHitEnemy(projectile, enemy)
 			if projectile.IsPiercing {
 				projectile.MarkEnemyHit(enemy.ID)
-				if !projectile.CanPierce() {
-					delete(w.projectiles, id)
+				if projectile.CanPierce() {
+					continue
 				}
-			} else {
-				delete(w.projectiles, id)
 			}
-			continue
+			delete(w.projectiles, id)
+			break
 		}
 
-		if projectile.ShouldDestroy() {
+		if projectile.ShouldDestroy(now) {
 			delete(w.projectiles, id)
 		}
 	}
@@ -310,106 +374,33 @@ func (w *World) Update(delta time.Duration) {
 
 		minion.Update(deltaSeconds, owner.Position)
 
-		if minion.CanCast() {
-			target := minion.FindNearestEnemy(w.enemies, minion.Ability.Range)
+		if minion.CanCast(now) && minion.Ability.IsOffensive() && !owner.IsDead() {
+			target := w.nearestEnemy(minion.Position, layerFromY(minion.Position.Y), minion.Ability.Range, nil)
 			if target != nil {
 				direction := minion.GetDirectionTo(target.Position)
 
-				switch minion.Ability.Category {
-				case AbilityCategoryProjectile:
-					projectileID := fmt.Sprintf("proj-minion-%s-%d", id, time.Now().UnixNano())
-					spawnPosition := minion.Position
-					spawnPosition.Y = 0.5
-
-					minionProjectile := NewProjectile(
-						projectileID, minion.OwnerID, spawnPosition,
-						Vector3{
-							X: direction.X * minion.Ability.Speed,
-							Y: 0,
-							Z: direction.Z * minion.Ability.Speed,
-						},
-						minion.Ability.Damage, minion.Ability.DamageType,
-						string(minion.AbilityType),
-					)
-					minionProjectile.StatusEffectInfo = minion.Ability.StatusEffect
-					w.projectiles[projectileID] = minionProjectile
-
-				case AbilityCategoryInstant:
-					hitTargets := make([]string, 0)
-					for _, enemy := range w.enemies {
-						if CheckLineCollision(minion.Position, direction, minion.Ability.Range, minion.Ability.Radius, enemy) {
-							damageInfo := DamageInfo{
-								Amount: minion.Ability.Damage, Type: minion.Ability.DamageType,
-								SourceID: minion.OwnerID, TargetID: enemy.ID,
-							}
-							died := ApplyDamage(enemy, damageInfo)
-							hitTargets = append(hitTargets, enemy.ID)
-							if minion.Ability.StatusEffect != nil {
-								enemy.ApplyStatusEffect(NewStatusEffect(
-									minion.Ability.StatusEffect.Type,
-									minion.Ability.StatusEffect.Duration,
-									minion.Ability.StatusEffect.Magnitude,
-									minion.OwnerID,
-								))
-							}
-							w.damageEvents = append(w.damageEvents, DamageEvent{TargetID: enemy.ID, Damage: damageInfo.Amount, Type: damageInfo.Type})
-							if died {
-								w.deathEvents = append(w.deathEvents, DeathEvent{EntityID: enemy.ID, EntityType: "enemy", KillerID: minion.OwnerID})
-								w.dropLoot(enemy)
-							}
-						}
-					}
-					w.abilityCastEvents = append(w.abilityCastEvents, AbilityCastEvent{
-						CasterID: id, CasterType: string(minion.Type), OwnerID: minion.OwnerID,
-						AbilityType: string(minion.AbilityType), Position: minion.Position,
-						Direction: direction, HitTargets: hitTargets,
-					})
-
-				case AbilityCategoryMelee:
-					hitTargets := make([]string, 0)
-					for _, enemy := range w.enemies {
-						if CheckConeCollision(minion.Position, direction, minion.Ability.Range, minion.Ability.Angle, enemy) {
-							damageInfo := DamageInfo{
-								Amount: minion.Ability.Damage, Type: minion.Ability.DamageType,
-								SourceID: minion.OwnerID, TargetID: enemy.ID,
-							}
-							died := ApplyDamage(enemy, damageInfo)
-							hitTargets = append(hitTargets, enemy.ID)
-							if minion.Ability.StatusEffect != nil {
-								enemy.ApplyStatusEffect(NewStatusEffect(
-									minion.Ability.StatusEffect.Type,
-									minion.Ability.StatusEffect.Duration,
-									minion.Ability.StatusEffect.Magnitude,
-									minion.OwnerID,
-								))
-							}
-							w.damageEvents = append(w.damageEvents, DamageEvent{TargetID: enemy.ID, Damage: damageInfo.Amount, Type: damageInfo.Type})
-							if died {
-								w.deathEvents = append(w.deathEvents, DeathEvent{EntityID: enemy.ID, EntityType: "enemy", KillerID: minion.OwnerID})
-								w.dropLoot(enemy)
-							}
-						}
-					}
+				result := w.castAbility(id, owner, minion.Position, minion.Ability, direction, CastOptions{ProjectileHeight: 0.5})
+				if minion.Ability.Shape != AbilityShapeProjectile {
 					w.abilityCastEvents = append(w.abilityCastEvents, AbilityCastEvent{
 						CasterID: id, CasterType: string(minion.Type), OwnerID: minion.OwnerID,
 						AbilityType: string(minion.AbilityType), Position: minion.Position,
-						Direction: direction, HitTargets: hitTargets,
+						Direction: direction, HitTargets: result.HitTargets,
 					})
 				}
 
-				minion.MarkCasted()
+				minion.MarkCasted(now)
 			}
 		}
 
-		if minion.ShouldDestroy() {
+		if minion.ShouldDestroy(now) {
 			delete(w.minions, id)
 		}
 	}
 
 	// Remove dead enemies after delay
 	for id, enemy := range w.enemies {
-		if enemy.IsDead() && time.Since(enemy.LastUpdate) > 2*time.Second {
-			delete(w.enemies, id)
+		if enemy.IsDead() && now.Sub(enemy.LastUpdate) > 2*time.Second {
+			w.removeEnemy(id)
 		}
 	}
 
@@ -418,6 +409,9 @@ func (w *World) Update(delta time.Duration) {
 
 	// Check and spawn enemies in active tiles
 	w.checkTileRespawns()
+
+	// Leave the enemy index fresh for state broadcasts, which only hold a read lock
+	w.enemyIndex()
 }
 
 // processCharacterAI runs autonomous combat decisions for players with auto-combat enabled.
@@ -434,6 +428,14 @@ func (w *World) processCharacterAI(delta float64) {
 		}
 	}
 
+	// Incoming fire the characters may need to sidestep
+	var enemyProjectiles []*Projectile
+	for _, projectile := range w.projectiles {
+		if projectile.IsEnemyProjectile {
+			enemyProjectiles = append(enemyProjectiles, projectile)
+		}
+	}
+
 	for _, player := range w.players {
 		if player.CharAI == nil {
 			continue
@@ -446,10 +448,8 @@ func (w *World) processCharacterAI(delta float64) {
 			player.CharAI.RecordPlayerDecision(-0.5 * delta)
 		}
 
-		if !player.AutoCombat {
-			continue
-		}
-		if player.IsDead() {
+		if !player.AutoCombat || player.IsDead() {
+			player.AIVelocity = Vector3{}
 			continue
 		}
 
@@ -461,30 +461,110 @@ func (w *World) processCharacterAI(delta float64) {
 		}
 
 		nearbyEnemies := make(map[string]*Enemy)
-		for id, enemy := range w.enemies {
-			if enemy.IsDead() {
-				continue
-			}
-			eLayer := layerFromY(enemy.Position.Y)
-			eCoord := WorldToHex(enemy.Position, eLayer)
-			if activeTiles[eCoord] {
-				nearbyEnemies[id] = enemy
+		for _, enemy := range w.enemiesInTiles(player.Position, layer, activeTiles) {
+			if !enemy.IsDead() {
+				nearbyEnemies[enemy.ID] = enemy
 			}
 		}
 
 		action := player.CharAI.UpdateWithLLM(delta, player, nearbyEnemies, w.LLM)
-		if action == nil {
-			continue
+		if action != nil {
+			// Dodges without a movement ability, and retreats, are made on foot
+			if (action.Type == "dodge" && action.Ability == "") || action.Type == "retreat" {
+				player.CharAI.OrderMove(action.Direction)
+			}
+
+			// Store the action for the network layer to pick up and execute
+			w.pendingAIActions = append(w.pendingAIActions, PendingAIAction{
+				PlayerID: player.ID,
+				Action:   action,
+			})
 		}
 
-		// Store the action for the network layer to pick up and execute
-		w.pendingAIActions = append(w.pendingAIActions, PendingAIAction{
-			PlayerID: player.ID,
-			Action:   action,
+		player.AIVelocity = player.CharAI.Steer(delta, player, &AISteerContext{
+			Enemies:     nearbyEnemies,
+			Projectiles: enemyProjectiles,
+			Allies:      w.alliesOf(player),
 		})
 	}
 }
 
+// alliesOf returns the living players on the player's layer who can't harm
+// them. Caller must hold w.mu.
+func (w *World) alliesOf(player *Player) []*Player {
+	layer := layerFromY(player.Position.Y)
+	var allies []*Player
+	for _, other := range w.players {
+		if other.ID == player.ID || other.IsDead() || layerFromY(other.Position.Y) != layer {
+			continue
+		}
+		if w.canHarmPlayer(other, player, false) {
+			continue
+		}
+		allies = append(allies, other)
+	}
+	return allies
+}
+
+// projectilePlayerHit returns the first player a player-owned projectile hits
+// under the world's ruleset, or nil. Caller must hold w.mu.
+func (w *World) projectilePlayerHit(projectile *Projectile, owner *Player) *Player {
+	for _, victim := range w.players {
+		if projectile.HasHitEnemy(victim.ID) || !w.canHarmPlayer(owner, victim, projectile.FromMinion()) {
+			continue
+		}
+		if Distance2D(projectile.Position, victim.Position) <= projectile.Radius+0.5 {
+			return victim
+		}
+	}
+	return nil
+}
+
+// projectileEnemyHits returns the living enemies inside a projectile's radius
+// that it hasn't already pierced. Caller must hold w.mu.
+func (w *World) projectileEnemyHits(projectile *Projectile) []*Enemy {
+	var hits []*Enemy
+	for _, enemy := range w.enemiesInRadius(projectile.Position, projectile.Layer, projectile.Radius) {
+		if !projectile.HasHitEnemy(enemy.ID) {
+			hits = append(hits, enemy)
+		}
+	}
+	return hits
+}
+
+// projectileHitEnemy applies a player or minion projectile's damage, threat
+// and status effect to an enemy. Caller must hold w.mu.
+func (w *World) projectileHitEnemy(projectile *Projectile, enemy *Enemy) {
+	damageInfo := DamageInfo{
+		Amount:      projectile.Damage,
+		Type:        projectile.DamageType,
+		SourceID:    projectile.OwnerID,
+		TargetID:    enemy.ID,
+		AbilityType: projectile.AbilityType,
+	}
+
+	casterID := projectile.CasterID
+	if casterID == "" {
+		casterID = projectile.OwnerID
+	}
+	w.addDamageThreat(enemy, casterID, projectile.OwnerID, damageInfo.Amount)
+
+	healthBefore := enemy.Health
+	died := ApplyDamage(enemy, damageInfo)
+
+	if projectile.StatusEffectInfo != nil {
+		statusEffect := NewStatusEffect(
+			projectile.StatusEffectInfo.Type,
+			projectile.StatusEffectInfo.Duration,
+			projectile.StatusEffectInfo.Magnitude,
+			projectile.OwnerID,
+		)
+		enemy.ApplyStatusEffect(statusEffect)
+	}
+
+	w.enemyDamaged(enemy, damageInfo, healthBefore, died)
+}
+
 // PendingAIAction holds an AI decision that needs to be executed by the network layer.
 type PendingAIAction struct {
 	PlayerID string
@@ -510,9 +590,9 @@ func layerFromY(y float64) int {
 	return 0
 }
 
-// updatePlayerTiles ensures tiles around a player are generated and tracks what
-// tile data needs to be sent.
-func (w *World) updatePlayerTiles(player *Player) {
+// updatePlayerTiles ensures tiles around a player are generated and active,
+// and returns their coordinates.
+func (w *World) updatePlayerTiles(player *Player) []HexCoord {
 	layer := layerFromY(player.Position.Y)
 	currentHex := WorldToHex(player.Position, layer)
 	player.CurrentTile = currentHex
@@ -528,6 +608,7 @@ func (w *World) updatePlayerTiles(player *Player) {
 			}
 		}
 	}
+	return coords
 }
 
 // isEntityInActiveTile checks if a world position is in an active tile
@@ -538,15 +619,15 @@ func (w *World) isEntityInActiveTile(pos Vector3) bool {
 	return tile != nil && tile.Active
 }
 
-// checkTileRespawns checks tiles that need enemy respawning
+// checkTileRespawns restores parked enemies to reactivated tiles and respawns
+// tiles whose population has been wiped out
 func (w *World) checkTileRespawns() {
-	// Build a map of which tiles have living enemies
+	// Count living enemies by the tile they belong to, not where they've
+	// wandered, so a tile whose enemies chased a player out isn't refilled
 	tilesWithEnemies := make(map[HexCoord]bool)
 	for _, enemy := range w.enemies {
-		if !enemy.IsDead() {
-			layer := layerFromY(enemy.Position.Y)
-			coord := WorldToHex(enemy.Position, layer)
-			tilesWithEnemies[coord] = true
+		if !enemy.IsDead() && enemy.HomeTile != nil {
+			tilesWithEnemies[*enemy.HomeTile] = true
 		}
 	}
 
@@ -558,9 +639,16 @@ func (w *World) checkTileRespawns() {
 		if tile.TileType == TileTypeTown {
 			continue
 		}
+		if len(w.parkedEnemies[coord]) > 0 {
+			w.restoreParkedEnemies(tile)
+			continue
+		}
 		if tilesWithEnemies[coord] {
 			continue
 		}
+		if tile.BossDefeated {
+			continue // Cleared boss rooms stay cleared
+		}
 		// No enemies in this active tile - check if there should be
 		if len(tile.Spawns) > 0 {
 			// Check if any player is nearby (within 2 hexes) on same layer
@@ -584,7 +672,12 @@ func (w *World) spawnTileEnemies(tile *Tile) {
 	spawnTime := time.Now().UnixNano()
 	count := 0
 
+	if tile.BossType != "" && !tile.BossDefeated {
+		w.spawnBoss(tile)
+	}
+
 	for spawnIdx, spawn := range tile.Spawns {
+		group := make([]*Enemy, 0, spawn.Count)
 		for i := 0; i < spawn.Count; i++ {
 			enemyType := spawn.EnemyTypes[rand.Intn(len(spawn.EnemyTypes))]
 			offsetX := (rand.Float64() - 0.5) * 3.0
@@ -596,9 +689,22 @@ func (w *World) spawnTileEnemies(tile *Tile) {
 				Z: spawn.Position.Z + offsetZ,
 			}
 
-			enemyID := fmt.Sprintf("enemy-%d-tile-%d-%d-%d", spawnTime, tile.Coord.Q, spawnIdx, i)
+			enemyID := fmt.Sprintf("enemy-%d-tile-%d-%d-%d-%d-%d", spawnTime, tile.Coord.Q, tile.Coord.R, tile.Coord.Layer, spawnIdx, i)
 			enemy := NewEnemy(enemyID, enemyType, pos)
-			w.enemies[enemyID] = enemy
+			enemy.applyLevel(w.monsterLevel(tile.Difficulty))
+			home := tile.Coord
+			enemy.HomeTile = &home
+			group = append(group, enemy)
+		}
+
+		// Elites are rolled before the pack enters the world so their scaled
+		// stats are what clients first see
+		if len(group) > 0 {
+			w.formPack(fmt.Sprintf("pack-%s", group[0].ID), group)
+		}
+		rollElitePack(group, tile.Difficulty)
+		for _, enemy := range group {
+			w.addEnemy(enemy)
 			count++
 		}
 	}
@@ -716,8 +822,16 @@ func (w *World) AddPlayer(player *Player) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	w.attachPlayer(player)
 	w.players[player.ID] = player
 
+	// Players always join alive, even if they were saved dead
+	if player.IsDead() {
+		player.Health = player.MaxHealth
+		player.DiedAt = time.Time{}
+		player.RespawnAt = time.Time{}
+	}
+
 	// Set player spawn position to town center
 	if w.Board != nil {
 		player.Position = w.Board.GetSpawnPoint()
@@ -756,6 +870,10 @@ func (w *World) RemovePlayer(playerID string) {
 
 	delete(w.players, playerID)
 	delete(w.playerTilesSent, playerID)
+	w.combatTracker().Remove(playerID)
+	if w.LLM != nil {
+		w.LLM.Forget(playerID)
+	}
 }
 
 // GetPlayers returns all players (thread-safe copy)
@@ -775,6 +893,13 @@ func (w *World) AddProjectile(projectile *Projectile) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	w.addProjectile(projectile)
+}
+
+// addProjectile adds a projectile whose lifetime starts now on the world
+// clock. Caller must hold w.mu.
+func (w *World) addProjectile(projectile *Projectile) {
+	projectile.CreatedAt = w.Now()
 	w.projectiles[projectile.ID] = projectile
 }
 
@@ -783,6 +908,15 @@ func (w *World) AddMinion(minion *Minion) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	w.addMinion(minion)
+}
+
+// addMinion adds a minion whose lifetime and cast timer start now on the
+// world clock. Caller must hold w.mu.
+func (w *World) addMinion(minion *Minion) {
+	now := w.Now()
+	minion.CreatedAt = now
+	minion.LastCast = now
 	w.minions[minion.ID] = minion
 }
 
@@ -844,12 +978,8 @@ func (w *World) GetWorldStateForPlayer(playerID string) map[string]interface{} {
 	}
 
 	enemies := make([]map[string]interface{}, 0)
-	for _, e := range w.enemies {
-		eLayer := layerFromY(e.Position.Y)
-		eCoord := WorldToHex(e.Position, eLayer)
-		if activeTiles[eCoord] {
-			enemies = append(enemies, e.Serialize())
-		}
+	for _, e := range w.enemiesInTiles(player.Position, layer, activeTiles) {
+		enemies = append(enemies, e.Serialize())
 	}
 
 	projectiles := make([]map[string]interface{}, 0)
@@ -886,6 +1016,7 @@ func (w *World) GetWorldStateForPlayer(playerID string) map[string]interface{} {
 			"entityID":   event.EntityID,
 			"entityType": event.EntityType,
 			"killerID":   event.KillerID,
+			"killerType": event.KillerType,
 		})
 	}
 
@@ -911,12 +1042,20 @@ func (w *World) GetWorldStateForPlayer(playerID string) map[string]interface{} {
 		}
 	}
 
+	lootChests := make([]map[string]interface{}, 0)
+	for _, chest := range w.lootChests {
+		if activeTiles[WorldToHex(chest.Position, layerFromY(chest.Position.Y))] {
+			lootChests = append(lootChests, chest.Serialize())
+		}
+	}
+
 	return map[string]interface{}{
 		"players":           players,
 		"enemies":           enemies,
 		"projectiles":       projectiles,
 		"minions":           minions,
 		"groundItems":       groundItems,
+		"lootChests":        lootChests,
 		"damageEvents":      damageEvents,
 		"deathEvents":       deathEvents,
 		"abilityCastEvents": abilityCastEvents,
@@ -963,6 +1102,7 @@ func (w *World) GetWorldState() map[string]interface{} {
 			"entityID":   event.EntityID,
 			"entityType": event.EntityType,
 			"killerID":   event.KillerID,
+			"killerType": event.KillerType,
 		})
 	}
 
@@ -984,39 +1124,67 @@ func (w *World) GetWorldState() map[string]interface{} {
 		groundItems = append(groundItems, groundItem.Serialize())
 	}
 
+	lootChests := make([]map[string]interface{}, 0, len(w.lootChests))
+	for _, chest := range w.lootChests {
+		lootChests = append(lootChests, chest.Serialize())
+	}
+
 	return map[string]interface{}{
 		"players":           players,
 		"enemies":           enemies,
 		"projectiles":       projectiles,
 		"minions":           minions,
 		"groundItems":       groundItems,
+		"lootChests":        lootChests,
 		"damageEvents":      damageEvents,
 		"deathEvents":       deathEvents,
 		"abilityCastEvents": abilityCastEvents,
 	}
 }
 
-// dropLoot creates loot when an enemy dies
+// dropLoot creates loot when an enemy dies. Elites always drop, champions
+// drop extra items, and both roll better rarities.
 func (w *World) dropLoot(enemy *Enemy) {
-	if rand.Float64() > 0.7 {
+	rankCfg, elite := eliteRankConfig(enemy.Rank)
+	if !elite && rand.Float64() > lootDropChance(enemy) {
 		return
 	}
 
-	itemLevel := 1
-	itemTypes := []ItemType{
-		ItemTypeWeapon1H, ItemTypeWeapon2H,
-		ItemTypeHead, ItemTypeChest, ItemTypeHands, ItemTypeFeet,
-		ItemTypeAmulet, ItemTypeRing,
+	for i := 0; i <= rankCfg.ExtraDrops; i++ {
+		w.dropLootItem(enemy, rankCfg.RarityBonus)
 	}
-	itemType := itemTypes[rand.Intn(len(itemTypes))]
+}
+
+// lootItemTypes are the equipment types enemies and boss chests drop
+var lootItemTypes = []ItemType{
+	ItemTypeWeapon1H, ItemTypeWeapon2H,
+	ItemTypeHead, ItemTypeChest, ItemTypeHands, ItemTypeFeet,
+	ItemTypeAmulet, ItemTypeRing,
+}
 
+// dropLootItem rolls one item and places it on the ground near an enemy
+func (w *World) dropLootItem(enemy *Enemy, rarityBonus float64) {
 	itemID := fmt.Sprintf("item-%d", w.nextItemID)
 	w.nextItemID++
 
-	item := NewItem(itemID, itemType, itemLevel)
+	var item *Item
+	if rand.Float64() < config.Items.ConsumableDropChance {
+		if consumableType, ok := rollConsumableType(); ok {
+			item, _ = NewConsumable(itemID, consumableType, 1)
+		}
+	}
+
+	if item == nil {
+		itemLevel := enemy.lootItemLevel()
+		itemType := lootItemTypes[rand.Intn(len(lootItemTypes))]
+
+		item = NewItemWithRarityBonus(itemID, itemType, itemLevel, rarityBonus)
+		// Magic items drop unidentified and need a scroll to reveal their affixes
+		item.Unidentified = item.Rarity != ItemRarityNormal
+	}
 
 	dropPos := w.findOpenDropPosition(enemy.Position)
-	groundItemID := fmt.Sprintf("ground-%d", time.Now().UnixNano())
+	groundItemID := fmt.Sprintf("ground-%s", itemID)
 	groundItem := NewGroundItem(groundItemID, item, dropPos)
 
 	w.groundItems[groundItemID] = groundItem
@@ -1085,6 +1253,9 @@ func (w *World) PickupItem(playerID, groundItemID string) error {
 	if !exists {
 		return fmt.Errorf("player not found")
 	}
+	if player.IsDead() {
+		return fmt.Errorf("player is dead")
+	}
 
 	groundItem, exists := w.groundItems[groundItemID]
 	if !exists {
@@ -1113,7 +1284,7 @@ func (w *World) PickupItem(playerID, groundItemID string) error {
 	}
 
 	if !autoEquipped {
-		if player.Inventory.IsFull() {
+		if !player.Inventory.CanAdd(item) {
 			return fmt.Errorf("inventory is full")
 		}
 
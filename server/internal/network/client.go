@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"time"
 
 	"github.com/PersonThing/cs-crawler/server/internal/config"
@@ -142,8 +143,12 @@ func (c *Client) handleMessage(data []byte) {
 		c.handleSetSkillConfig(msg)
 	case "use_heal":
 		c.handleUseHeal(msg)
+	case "use_item":
+		c.handleUseItem(msg)
 	case "pickup_item":
 		c.handlePickupItem(msg)
+	case "open_chest":
+		c.handleOpenChest(msg)
 	case "equip_item":
 		c.handleEquipItem(msg)
 	case "unequip_item":
@@ -164,6 +169,8 @@ func (c *Client) handleMessage(data []byte) {
 		c.handleSetPriorityTarget(msg)
 	case "get_ai_stats":
 		c.handleGetAIStats(msg)
+	case "get_combat_stats":
+		c.handleGetCombatStats(msg)
 	case "respawn":
 		c.handleRespawn(msg)
 	// Lobby messages
@@ -259,14 +266,17 @@ func (c *Client) handleJoin(msg map[string]interface{}) {
 	} else if savedData != nil {
 		player.RestoreFromSave(
 			savedData.PositionX, savedData.PositionY, savedData.PositionZ,
-			savedData.Rotation, savedData.Health,
+			savedData.Rotation, savedData.Health, savedData.Mana,
 			savedData.EquippedItems, savedData.BagItems,
 		)
+		player.Experience = savedData.Experience
+		player.LoadCharacterAI(savedData.CharacterAI)
 		if config.Server.Debug.LogPlayerLoads {
 			log.Printf("[LOAD] Restored player %s from database (pos: %.1f, %.1f, %.1f)",
 				username, savedData.PositionX, savedData.PositionY, savedData.PositionZ)
 		}
 	} else {
+		player.LoadCharacterAI(nil)
 		if config.Server.Debug.LogPlayerLoads {
 			log.Printf("[LOAD] No saved data for %s, creating fresh player", username)
 		}
@@ -401,8 +411,17 @@ func (c *Client) handleUseAbility(msg map[string]interface{}) {
 		return
 	}
 
+	if player.IsDead() {
+		c.Send(map[string]interface{}{
+			"type":    "ability_failed",
+			"reason":  "dead",
+			"ability": string(abilityType),
+		})
+		return
+	}
+
 	// Try to use ability
-	ability, err := player.Abilities.UseAbility(abilityType)
+	ability, err := player.Abilities.UseAbility(abilityType, &player.Mana)
 	if err != nil {
 		log.Printf("[ABILITY] Cannot use ability: %v", err)
 		c.Send(map[string]interface{}{
@@ -418,7 +437,7 @@ func (c *Client) handleUseAbility(msg map[string]interface{}) {
 	}
 
 	// Handle pet and turret modifiers - these create minions
-	if c.modifiers["pet"] {
+	if c.modifiers["pet"] && ability.IsOffensive() {
 		// Create a pet minion that follows the player
 		minionID := fmt.Sprintf("pet-%s-%d", c.playerID, time.Now().UnixNano())
 		modifier := game.Modifier{
@@ -445,7 +464,7 @@ func (c *Client) handleUseAbility(msg map[string]interface{}) {
 		})
 	}
 
-	if c.modifiers["turret"] {
+	if c.modifiers["turret"] && ability.IsOffensive() {
 		// Create a turret minion at the cast position
 		minionID := fmt.Sprintf("turret-%s-%d", c.playerID, time.Now().UnixNano())
 
@@ -480,160 +499,38 @@ func (c *Client) handleUseAbility(msg map[string]interface{}) {
 		})
 	}
 
-	// Handle ability based on category
-	switch ability.Category {
-	case game.AbilityCategoryProjectile:
-		// Create projectile based on ability
-		// Spawn projectile at player height (0.9 units above ground, which is half player height of 1.8)
-		projectileID := generateProjectileID()
-		spawnPosition := player.Position
-		spawnPosition.Y = 0.9 // Half of player height (1.8 / 2)
-
-		projectile := game.NewProjectile(
-			projectileID,
-			player.ID,
-			spawnPosition,
-			game.Vector3{
-				X: direction.X * ability.Speed,
-				Y: direction.Y * ability.Speed,
-				Z: direction.Z * ability.Speed,
-			},
-			ability.Damage,
-			ability.DamageType,
-			string(abilityType),
-		)
-
-		// Store status effect info in projectile for application on hit
-		projectile.StatusEffectInfo = ability.StatusEffect
-
-		// Apply active modifiers
-		if c.modifiers["homing"] {
-			projectile.IsHoming = true
-			projectile.HomingTurnRate = 360.0 // degrees per second
-			if config.Server.Debug.LogAbilityCasts {
-				log.Printf("[ABILITY] Applied homing modifier to projectile")
-			}
-		}
-
-		if c.modifiers["piercing"] {
-			projectile.IsPiercing = true
-			projectile.MaxPierces = 3 // Can hit up to 3 enemies
-			if config.Server.Debug.LogAbilityCasts {
-				log.Printf("[ABILITY] Applied piercing modifier to projectile")
-			}
-		}
-
-		world.AddProjectile(projectile)
-
-		// Broadcast ability cast to all clients in world
-		c.server.BroadcastToWorld(c.worldID, map[string]interface{}{
-			"type":         "ability_cast",
-			"playerID":     player.ID,
-			"abilityType":  string(abilityType),
-			"position":     player.Position,
-			"direction":    direction,
-			"projectileID": projectileID,
-		})
-
-		if config.Server.Debug.LogAbilityCasts {
-			log.Printf("[ABILITY] Projectile %s created for player %s", projectileID, c.playerID)
-		}
-
-	case game.AbilityCategoryInstant:
-		// Instant ability (e.g., Lightning) - check line collision immediately
-		enemies := world.GetEnemies()
-		hitTargets := make([]string, 0)
-
-		for _, enemy := range enemies {
-			if game.CheckLineCollision(player.Position, direction, ability.Range, ability.Radius, enemy) {
-				damageInfo := game.DamageInfo{
-					Amount:   ability.Damage,
-					Type:     ability.DamageType,
-					SourceID: player.ID,
-					TargetID: enemy.ID,
-				}
-
-				died := game.ApplyDamage(enemy, damageInfo)
-				hitTargets = append(hitTargets, enemy.ID)
-
-				// Apply status effect if present
-				if ability.StatusEffect != nil {
-					statusEffect := game.NewStatusEffect(
-						ability.StatusEffect.Type,
-						ability.StatusEffect.Duration,
-						ability.StatusEffect.Magnitude,
-						player.ID,
-					)
-					enemy.ApplyStatusEffect(statusEffect)
-				}
-
-				if config.Server.Debug.LogAbilityCasts {
-					log.Printf("[ABILITY] Instant ability hit enemy %s (died: %v)", enemy.ID, died)
-				}
-			}
-		}
-
-		// Broadcast instant ability cast to all clients
-		c.server.BroadcastToWorld(c.worldID, map[string]interface{}{
-			"type":        "ability_cast",
-			"playerID":    player.ID,
-			"abilityType": string(abilityType),
-			"position":    player.Position,
-			"direction":   direction,
-			"hitTargets":  hitTargets,
-		})
-
-		if config.Server.Debug.LogAbilityCasts {
-			log.Printf("[ABILITY] Instant ability %s hit %d targets", abilityType, len(hitTargets))
+	// Resolve the ability against the world based on its shape
+	opts := game.CastOptions{
+		Homing:           c.modifiers["homing"],
+		Piercing:         c.modifiers["piercing"],
+		ProjectileHeight: 0.9, // Half of player height (1.8 / 2)
+	}
+	if targetMap, ok := msg["targetPosition"].(map[string]interface{}); ok {
+		opts.TargetPosition = &game.Vector3{
+			X: getFloat64(targetMap, "x"),
+			Y: getFloat64(targetMap, "y"),
+			Z: getFloat64(targetMap, "z"),
 		}
+	}
 
-	case game.AbilityCategoryMelee:
-		// Melee ability (e.g., BasicAttack) - check cone collision immediately
-		enemies := world.GetEnemies()
-		hitTargets := make([]string, 0)
-
-		for _, enemy := range enemies {
-			if game.CheckConeCollision(player.Position, direction, ability.Range, ability.Angle, enemy) {
-				damageInfo := game.DamageInfo{
-					Amount:   ability.Damage,
-					Type:     ability.DamageType,
-					SourceID: player.ID,
-					TargetID: enemy.ID,
-				}
-
-				died := game.ApplyDamage(enemy, damageInfo)
-				hitTargets = append(hitTargets, enemy.ID)
-
-				// Apply status effect if present
-				if ability.StatusEffect != nil {
-					statusEffect := game.NewStatusEffect(
-						ability.StatusEffect.Type,
-						ability.StatusEffect.Duration,
-						ability.StatusEffect.Magnitude,
-						player.ID,
-					)
-					enemy.ApplyStatusEffect(statusEffect)
-				}
-
-				if config.Server.Debug.LogAbilityCasts {
-					log.Printf("[ABILITY] Melee ability hit enemy %s (died: %v)", enemy.ID, died)
-				}
-			}
-		}
+	castPosition := player.Position
+	result := world.CastPlayerAbility(player, ability, direction, opts)
 
-		// Broadcast melee ability cast to all clients
-		c.server.BroadcastToWorld(c.worldID, map[string]interface{}{
-			"type":        "ability_cast",
-			"playerID":    player.ID,
-			"abilityType": string(abilityType),
-			"position":    player.Position,
-			"direction":   direction,
-			"hitTargets":  hitTargets,
-		})
+	// Broadcast ability cast to all clients in world
+	castMsg := map[string]interface{}{
+		"type":        "ability_cast",
+		"playerID":    player.ID,
+		"abilityType": string(abilityType),
+		"shape":       string(ability.Shape),
+		"position":    castPosition,
+		"direction":   direction,
+	}
+	result.Serialize(castMsg)
+	c.server.BroadcastToWorld(c.worldID, castMsg)
 
-		if config.Server.Debug.LogAbilityCasts {
-			log.Printf("[ABILITY] Melee ability %s hit %d targets", abilityType, len(hitTargets))
-		}
+	if config.Server.Debug.LogAbilityCasts {
+		log.Printf("[ABILITY] %s ability %s cast by %s hit %d targets",
+			ability.Shape, abilityType, c.playerID, len(result.HitTargets))
 	}
 }
 
@@ -697,6 +594,10 @@ func (c *Client) handleSetSkillConfig(msg map[string]interface{}) {
 		log.Printf("[SKILL_CONFIG] Invalid abilityType in message")
 		return
 	}
+	if _, exists := game.GetAbility(game.AbilityType(abilityType)); !exists {
+		log.Printf("[SKILL_CONFIG] Unknown ability type: %s", abilityType)
+		return
+	}
 
 	// Parse modifiers array
 	modifiersRaw, ok := msg["modifiers"].([]interface{})
@@ -779,6 +680,38 @@ func (c *Client) handlePickupItem(msg map[string]interface{}) {
 	c.Send(response)
 }
 
+// handleOpenChest opens a boss loot chest, spilling its items onto the ground
+func (c *Client) handleOpenChest(msg map[string]interface{}) {
+	if c.playerID == "" || c.worldID == "" {
+		return
+	}
+
+	chestID, ok := msg["chestID"].(string)
+	if !ok {
+		log.Printf("[CHEST] Invalid chestID in message")
+		return
+	}
+
+	world, ok := c.server.gameServer.GetWorld(c.worldID)
+	if !ok {
+		return
+	}
+
+	if err := world.OpenChest(c.playerID, chestID); err != nil {
+		c.Send(map[string]interface{}{
+			"type":    "error",
+			"code":    "OPEN_CHEST_FAILED",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.Send(map[string]interface{}{
+		"type":    "chest_opened",
+		"chestID": chestID,
+	})
+}
+
 // handleEquipItem processes a request to equip an item from inventory
 func (c *Client) handleEquipItem(msg map[string]interface{}) {
 	if c.playerID == "" || c.worldID == "" {
@@ -1084,6 +1017,20 @@ func (c *Client) executeAIAbility(action *game.AIAction) {
 			"z": action.Direction.Z,
 		},
 	}
+
+	// Ground-targeted abilities land on the chosen target
+	if action.Type == "ability" && action.TargetID != "" {
+		if world, ok := c.server.gameServer.GetWorld(c.worldID); ok {
+			if target, ok := world.GetEnemies()[action.TargetID]; ok {
+				msg["targetPosition"] = map[string]interface{}{
+					"x": target.Position.X,
+					"y": target.Position.Y,
+					"z": target.Position.Z,
+				}
+			}
+		}
+	}
+
 	c.handleUseAbility(msg)
 }
 
@@ -1163,7 +1110,25 @@ func (c *Client) handleGetAIStats(msg map[string]interface{}) {
 	c.Send(stats)
 }
 
-// handleRespawn respawns a dead player at their spawn position with full health
+// handleGetCombatStats returns the damage meter for everyone in the player's world
+func (c *Client) handleGetCombatStats(msg map[string]interface{}) {
+	if c.playerID == "" || c.worldID == "" {
+		return
+	}
+
+	world, ok := c.server.gameServer.GetWorld(c.worldID)
+	if !ok {
+		return
+	}
+
+	c.Send(map[string]interface{}{
+		"type":    "combat_stats",
+		"players": world.GetCombatStats(),
+	})
+}
+
+// handleRespawn respawns a dead player in town or at their dungeon's entrance
+// once their respawn timer has run out
 func (c *Client) handleRespawn(msg map[string]interface{}) {
 	if c.playerID == "" || c.worldID == "" {
 		log.Printf("[RESPAWN] Player not in a world, ignoring respawn request")
@@ -1182,23 +1147,34 @@ func (c *Client) handleRespawn(msg map[string]interface{}) {
 		return
 	}
 
-	// Reset player health to full
-	player.Health = player.MaxHealth
-
-	// Move player to spawn position (center of map at 0, 0.5, 0)
-	player.Position = game.Vector3{X: 0, Y: 0.5, Z: 0}
-	player.Velocity = game.Vector3{X: 0, Y: 0, Z: 0}
-
-	log.Printf("[RESPAWN] Player %s respawned with full health at spawn position", c.playerID)
+	// The world decides when and where the player comes back
+	pos, err := world.RespawnPlayer(c.playerID)
+	if err != nil {
+		c.Send(map[string]interface{}{
+			"type":      "respawn_failed",
+			"reason":    err.Error(),
+			"respawnIn": math.Max(player.RespawnAt.Sub(world.Now()).Seconds(), 0),
+		})
+		return
+	}
 
 	// Send confirmation
 	c.Send(map[string]interface{}{
 		"type":    "respawn_success",
 		"message": "You have respawned!",
+		"position": map[string]interface{}{
+			"x": pos.X, "y": pos.Y, "z": pos.Z,
+		},
 	})
 }
 
+// handleUseHeal restores the player to full health for free. It is a debug
+// cheat, disabled unless debug.allowFreeHeal is set; players use potions.
 func (c *Client) handleUseHeal(msg map[string]interface{}) {
+	if !config.Server.Debug.AllowFreeHeal {
+		log.Printf("[HEAL] Free heal is disabled, ignoring request from %s", c.playerID)
+		return
+	}
 	if c.playerID == "" || c.worldID == "" {
 		log.Printf("[HEAL] Player not in a world, ignoring heal request")
 		return
@@ -1216,6 +1192,11 @@ func (c *Client) handleUseHeal(msg map[string]interface{}) {
 		return
 	}
 
+	// The dead have to respawn instead
+	if player.IsDead() {
+		return
+	}
+
 	// Heal player to full health
 	oldHealth := player.Health
 	player.Health = player.MaxHealth
@@ -1225,10 +1206,65 @@ func (c *Client) handleUseHeal(msg map[string]interface{}) {
 	// Send confirmation
 	c.Send(map[string]interface{}{
 		"type":    "heal_success",
-		"message": "You have respawned!",
+		"message": "You have been healed",
 	})
 }
 
+// handleUseItem uses a potion or scroll from the player's bag
+func (c *Client) handleUseItem(msg map[string]interface{}) {
+	if c.playerID == "" || c.worldID == "" {
+		return
+	}
+
+	bagSlot, ok := msg["bagSlot"].(float64)
+	if !ok {
+		log.Printf("[ITEM] Invalid bagSlot in message")
+		return
+	}
+	targetSlot := -1.0
+	if v, ok := msg["targetSlot"].(float64); ok {
+		targetSlot = v
+	}
+
+	world, ok := c.server.gameServer.GetWorld(c.worldID)
+	if !ok {
+		return
+	}
+
+	result, err := world.UseItem(c.playerID, int(bagSlot), int(targetSlot))
+	if err != nil {
+		c.Send(map[string]interface{}{
+			"type":           "use_item_failed",
+			"bagSlot":        int(bagSlot),
+			"reason":         err.Error(),
+			"potionCooldown": result.PotionCooldown,
+		})
+		return
+	}
+
+	response := map[string]interface{}{
+		"type":           "item_used",
+		"bagSlot":        int(bagSlot),
+		"effect":         string(result.Effect),
+		"restored":       result.Restored,
+		"potionCooldown": result.PotionCooldown,
+		"remaining":      result.RemainingInSlot,
+	}
+	if result.Position != nil {
+		response["position"] = *result.Position
+	}
+	if result.IdentifiedItem != nil {
+		response["identifiedItem"] = result.IdentifiedItem.Serialize()
+	}
+
+	player := world.GetPlayer(c.playerID)
+	if player != nil && player.Inventory != nil {
+		response["inventory"] = player.Inventory.Serialize()
+		response["stats"] = player.Serialize()["stats"]
+	}
+	c.Send(response)
+}
+
 // handleEnterDungeon moves the player into the dungeon beneath their current tile
 func (c *Client) handleEnterDungeon(msg map[string]interface{}) {
 	if c.playerID == "" || c.worldID == "" {
@@ -1324,13 +1360,22 @@ func (c *Client) handleCreateGame(msg map[string]interface{}) {
 	name, _ := msg["name"].(string)
 	visibilityStr, _ := msg["visibility"].(string)
 	maxPlayers := int(getFloat64(msg, "maxPlayers"))
+	pvpStr, _ := msg["pvp"].(string)
+	friendlyFire, _ := msg["friendlyFire"].(bool)
+	difficultyTier := int(getFloat64(msg, "difficultyTier"))
 
 	visibility := game.GameVisibilityPublic
 	if visibilityStr == "private" {
 		visibility = game.GameVisibilityPrivate
 	}
 
-	gameListing, err := c.server.gameServer.Lobby.CreateGame(c.playerID, c.username, name, visibility, maxPlayers)
+	rules := game.GameRules{
+		PvP:            game.ParsePvPMode(pvpStr),
+		FriendlyFire:   friendlyFire,
+		DifficultyTier: game.ParseDifficultyTier(difficultyTier),
+	}
+
+	gameListing, err := c.server.gameServer.Lobby.CreateGame(c.playerID, c.username, name, visibility, maxPlayers, rules)
 	if err != nil {
 		c.Send(map[string]interface{}{
 			"type":    "error",
@@ -1340,7 +1385,7 @@ func (c *Client) handleCreateGame(msg map[string]interface{}) {
 		return
 	}
 
-	log.Printf("[LOBBY] Player %s created game: %s (%s)", c.username, gameListing.Name, gameListing.ID)
+	log.Printf("[LOBBY] Player %s created game: %s (%s) pvp=%s friendlyFire=%v tier=%d", c.username, gameListing.Name, gameListing.ID, rules.PvP, rules.FriendlyFire, rules.DifficultyTier)
 
 	c.Send(map[string]interface{}{
 		"type": "game_created",
@@ -1504,6 +1549,11 @@ func (c *Client) joinGameWorld(worldID string) {
 	world, ok := c.server.gameServer.GetWorld(worldID)
 	if !ok {
 		world = c.server.gameServer.CreateWorld(worldID)
+
+		// Apply the ruleset chosen when the game was created
+		if gameListing, ok := c.server.gameServer.Lobby.GetGame(worldID); ok {
+			world.SetRules(gameListing.Rules)
+		}
 	}
 
 	// Create player
@@ -1514,9 +1564,13 @@ func (c *Client) joinGameWorld(worldID string) {
 	if err == nil && savedData != nil {
 		player.RestoreFromSave(
 			savedData.PositionX, savedData.PositionY, savedData.PositionZ,
-			savedData.Rotation, savedData.Health,
+			savedData.Rotation, savedData.Health, savedData.Mana,
 			savedData.EquippedItems, savedData.BagItems,
 		)
+		player.Experience = savedData.Experience
+		player.LoadCharacterAI(savedData.CharacterAI)
+	} else if err == nil {
+		player.LoadCharacterAI(nil)
 	}
 
 	world.AddPlayer(player)
@@ -1722,11 +1776,6 @@ func joinArgs(args []string) string {
 	return result
 }
 
-// generateProjectileID creates a unique projectile ID
-func generateProjectileID() string {
-	return fmt.Sprintf("proj-%d", time.Now().UnixNano())
-}
-
 // Send queues a message to be sent to the client
 func (c *Client) Send(message map[string]interface{}) {
 	data, err := json.Marshal(message)
@@ -176,6 +176,8 @@ func (s *Server) broadcastLoop() {
 	for range ticker.C {
 		s.broadcastWorldStates()
 		s.broadcastAIActions()
+		s.broadcastDeathRecaps()
+		s.broadcastBossEvents()
 	}
 }
 
@@ -253,13 +255,17 @@ func (s *Server) broadcastAIActions() {
 			if pa.Action.TargetID != "" {
 				msg["targetId"] = pa.Action.TargetID
 			}
-			if pa.Action.Type == "ability" {
-				msg["ability"] = string(pa.Action.Ability)
+			if pa.Action.Type != "idle" {
 				msg["direction"] = map[string]interface{}{
 					"x": pa.Action.Direction.X,
 					"y": pa.Action.Direction.Y,
 					"z": pa.Action.Direction.Z,
 				}
+			}
+			// Dodges use a movement ability when one is ready; otherwise they,
+			// like retreats, are walked by the world (see CharacterAI.Steer)
+			if pa.Action.Type == "ability" || (pa.Action.Type == "dodge" && pa.Action.Ability != "") {
+				msg["ability"] = string(pa.Action.Ability)
 				// Actually execute the ability on behalf of the character
 				client.executeAIAbility(pa.Action)
 			}
@@ -268,6 +274,71 @@ func (s *Server) broadcastAIActions() {
 	}
 }
 
+// broadcastDeathRecaps sends each player who died a summary of what killed them
+func (s *Server) broadcastDeathRecaps() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	clientByPlayer := make(map[string]*Client)
+	worldIDs := make(map[string]bool)
+	for client := range s.clients {
+		if client.playerID != "" && client.worldID != "" {
+			clientByPlayer[client.playerID] = client
+			worldIDs[client.worldID] = true
+		}
+	}
+
+	for worldID := range worldIDs {
+		world, ok := s.gameServer.GetWorld(worldID)
+		if !ok {
+			continue
+		}
+
+		for _, recap := range world.DrainDeathRecaps() {
+			client, ok := clientByPlayer[recap.PlayerID]
+			if !ok {
+				continue
+			}
+			msg := recap.Serialize()
+			msg["type"] = "death_recap"
+			client.Send(msg)
+		}
+	}
+}
+
+// broadcastBossEvents sends boss phase changes and AoE warnings to the
+// players in each boss's room
+func (s *Server) broadcastBossEvents() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	clientByPlayer := make(map[string]*Client)
+	worldIDs := make(map[string]bool)
+	for client := range s.clients {
+		if client.playerID != "" && client.worldID != "" {
+			clientByPlayer[client.playerID] = client
+			worldIDs[client.worldID] = true
+		}
+	}
+
+	for worldID := range worldIDs {
+		world, ok := s.gameServer.GetWorld(worldID)
+		if !ok {
+			continue
+		}
+
+		for _, event := range world.DrainBossEvents() {
+			msg := event.Serialize()
+			msg["type"] = "boss_phase"
+			for _, playerID := range event.Recipients {
+				if client, ok := clientByPlayer[playerID]; ok {
+					client.Send(msg)
+				}
+			}
+		}
+	}
+}
+
 // checkWorldEmpty checks if a world has no players and schedules shutdown
 func (s *Server) checkWorldEmpty(worldID string) {
 	s.mu.RLock()
@@ -0,0 +1,5 @@
+-- Add mana to player save data
+
+ALTER TABLE players ADD COLUMN IF NOT EXISTS mana DOUBLE PRECISION DEFAULT 100;
+
+COMMENT ON COLUMN players.mana IS 'Current mana at time of save';
@@ -0,0 +1,5 @@
+-- Add experience to player save data
+
+ALTER TABLE players ADD COLUMN IF NOT EXISTS experience DOUBLE PRECISION DEFAULT 0;
+
+COMMENT ON COLUMN players.experience IS 'Experience earned at time of save';
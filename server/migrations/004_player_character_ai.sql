@@ -0,0 +1,5 @@
+-- Add character AI state to player save data
+
+ALTER TABLE players ADD COLUMN IF NOT EXISTS character_ai JSONB DEFAULT '{}';
+
+COMMENT ON COLUMN players.character_ai IS 'Character AI personality, trust and mood at time of save';